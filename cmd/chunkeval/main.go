@@ -0,0 +1,472 @@
+// This program runs the book through multiple chunking strategies and
+// reports the retrieval metrics for each one side by side, so "how you
+// chunk is critical" comes with numbers attached. The strategies are a
+// fixed token window, a fixed window with overlap, and the cleaner's
+// section based chunks which follow the structure of the document.
+//
+// A retrieved chunk counts as relevant when it overlaps the text of a
+// section the golden dataset marks as relevant, so strategies with
+// different chunk boundaries are scored on equal footing.
+//
+// # Running the program:
+//
+//	$ make chunkeval
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/embedding"
+	"github.com/ardanlabs/ai-training/foundation/tiktoken"
+	"github.com/ardanlabs/ai-training/foundation/vector"
+	"github.com/ardanlabs/kronk/sdk/kronk"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/ardanlabs/kronk/sdk/tools/defaults"
+	"github.com/ardanlabs/kronk/sdk/tools/libs"
+	"github.com/ardanlabs/kronk/sdk/tools/models"
+)
+
+const (
+	modelEmbedSource = "ggml-org/embeddinggemma-300m-qat-Q8_0"
+	bookFile         = "zarf/data/book.txt"
+	chunksFile       = "zarf/data/book.chunks"
+	chunkTokens      = 400
+	overlapTokens    = 80
+)
+
+// golden represents one entry in the golden dataset, shared with the
+// rageval command.
+type golden struct {
+	Question string `json:"question"`
+	Pages    []int  `json:"pages"`
+}
+
+// span marks where a chunk lives in the book text. Relevance is judged by
+// overlap between spans, so every strategy is scored against the same
+// ground truth no matter where it draws its boundaries.
+type span struct {
+	start int
+	end   int
+}
+
+func (s span) overlaps(other span) bool {
+	return s.start < other.end && other.start < s.end
+}
+
+// chunk is a piece of the book produced by one of the strategies.
+type chunk struct {
+	text string
+	span span
+}
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	goldenFile := flag.String("golden", "zarf/data/rageval.json", "golden dataset to evaluate against")
+	k := flag.Int("k", 5, "number of chunks to retrieve per question")
+	flag.Parse()
+
+	bookData, err := os.ReadFile(bookFile)
+	if err != nil {
+		return fmt.Errorf("unable to read book: %w", err)
+	}
+	book := string(bookData)
+
+	goldens, err := readGolden(*goldenFile)
+	if err != nil {
+		return fmt.Errorf("unable to read golden dataset: %w", err)
+	}
+
+	sections, err := sectionChunks(book)
+	if err != nil {
+		return fmt.Errorf("unable to read section chunks: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	tkn, err := tiktoken.NewTiktoken()
+	if err != nil {
+		return fmt.Errorf("new tiktoken: %w", err)
+	}
+
+	type strategy struct {
+		name   string
+		chunks []chunk
+	}
+
+	strategies := []strategy{
+		{"fixed", windowChunks(tkn, book, chunkTokens, 0)},
+		{"overlap", windowChunks(tkn, book, chunkTokens, overlapTokens)},
+		{"section", chunksOnly(sections)},
+	}
+
+	// -------------------------------------------------------------------------
+
+	infoEmbed, err := installSystem()
+	if err != nil {
+		return fmt.Errorf("unable to install system: %w", err)
+	}
+
+	krnEmbed, err := newKronk(infoEmbed)
+	if err != nil {
+		return fmt.Errorf("unable to create embedding model: %w", err)
+	}
+	defer func() {
+		fmt.Println("\nUnloading embedding model")
+		if err := krnEmbed.Unload(context.Background()); err != nil {
+			fmt.Printf("failed to unload embedding model: %v", err)
+		}
+	}()
+
+	emb := embedding.New(krnEmbed)
+
+	// -------------------------------------------------------------------------
+
+	questionVecs, err := embedTexts(emb, questions(goldens))
+	if err != nil {
+		return fmt.Errorf("unable to embed questions: %w", err)
+	}
+
+	relevant := relevantSpans(goldens, sections)
+
+	fmt.Printf("\n%-10s %7s %9s %7s %7s\n", "strategy", "chunks", "recall@"+fmt.Sprint(*k), "mrr", "ndcg")
+
+	for _, s := range strategies {
+		recall, mrr, ndcg, err := evaluate(emb, s.chunks, questionVecs, relevant, *k)
+		if err != nil {
+			return fmt.Errorf("unable to evaluate %s: %w", s.name, err)
+		}
+
+		fmt.Printf("%-10s %7d %9.3f %7.3f %7.3f\n", s.name, len(s.chunks), recall, mrr, ndcg)
+	}
+
+	return nil
+}
+
+// evaluate embeds the chunks of one strategy and scores retrieval for
+// every golden question, returning the averaged metrics.
+func evaluate(emb *embedding.Model, chunks []chunk, questionVecs [][]float32, relevant [][]span, k int) (float64, float64, float64, error) {
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.text
+	}
+
+	chunkVecs, err := embedTexts(emb, texts)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("embed chunks: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	var sumRecall float64
+	var sumMRR float64
+	var sumNDCG float64
+	var scored int
+
+	for qi, qVec := range questionVecs {
+		// A question whose relevant pages didn't survive the chunk lookup
+		// can't be scored fairly for any strategy.
+		if len(relevant[qi]) == 0 {
+			continue
+		}
+
+		top := topK(qVec, chunkVecs, k)
+
+		var found int
+		var mrr float64
+		var dcg float64
+
+		hit := make([]bool, len(relevant[qi]))
+
+		for rank, ci := range top {
+			var match bool
+
+			for si, rs := range relevant[qi] {
+				if chunks[ci].span.overlaps(rs) {
+					match = true
+					if !hit[si] {
+						hit[si] = true
+						found++
+					}
+				}
+			}
+
+			if !match {
+				continue
+			}
+
+			if mrr == 0 {
+				mrr = 1 / float64(rank+1)
+			}
+
+			dcg += 1 / math.Log2(float64(rank+2))
+		}
+
+		ideal := min(k, len(relevant[qi]))
+
+		var idcg float64
+		for rank := range ideal {
+			idcg += 1 / math.Log2(float64(rank+2))
+		}
+
+		sumRecall += float64(found) / float64(ideal)
+		sumMRR += mrr
+		sumNDCG += dcg / idcg
+		scored++
+	}
+
+	if scored == 0 {
+		return 0, 0, 0, fmt.Errorf("no questions could be scored")
+	}
+
+	n := float64(scored)
+
+	return sumRecall / n, sumMRR / n, sumNDCG / n, nil
+}
+
+// topK returns the indexes of the k chunks most similar to the query.
+func topK(query []float32, vecs [][]float32, k int) []int {
+	idx := make([]int, len(vecs))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	sort.Slice(idx, func(a, b int) bool {
+		return vector.CosineSimilarity32(query, vecs[idx[a]]) > vector.CosineSimilarity32(query, vecs[idx[b]])
+	})
+
+	return idx[:min(k, len(idx))]
+}
+
+// embedTexts vectorizes the texts in batches so the model processes a
+// group per call.
+func embedTexts(emb *embedding.Model, texts []string) ([][]float32, error) {
+	const batchSize = 32
+
+	var vecs [][]float32
+
+	for start := 0; start < len(texts); start += batchSize {
+		batch := texts[start:min(start+batchSize, len(texts))]
+
+		group, err := func() ([][]float32, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			return emb.EmbedBatch(ctx, batch)
+		}()
+		if err != nil {
+			return nil, fmt.Errorf("embed: %w", err)
+		}
+
+		vecs = append(vecs, group...)
+	}
+
+	return vecs, nil
+}
+
+// =============================================================================
+
+// windowChunks splits the book into fixed token windows, locating each
+// piece in the original text so relevance can be judged by overlap.
+func windowChunks(tkn *tiktoken.Tiktoken, book string, chunkTokens int, overlapTokens int) []chunk {
+	pieces := tkn.Split(book, chunkTokens, overlapTokens)
+
+	var chunks []chunk
+	searchFrom := 0
+
+	for _, piece := range pieces {
+		idx := strings.Index(book[searchFrom:], piece)
+		if idx == -1 {
+			continue
+		}
+
+		start := searchFrom + idx
+
+		chunks = append(chunks, chunk{
+			text: piece,
+			span: span{start: start, end: start + len(piece)},
+		})
+
+		// Overlapping windows share text, so the next search starts inside
+		// this chunk rather than after it.
+		searchFrom = start + 1
+	}
+
+	return chunks
+}
+
+// sectionChunk pairs a section based chunk with the page it came from.
+type sectionChunk struct {
+	chunk
+	page int
+}
+
+// sectionChunks reads the cleaner's structure aware chunks and locates
+// each one in the book text. Chunks whose text can't be found (the cleaner
+// normalizes some whitespace) are dropped from the comparison.
+func sectionChunks(book string) ([]sectionChunk, error) {
+	input, err := os.Open(chunksFile)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer input.Close()
+
+	var chunks []sectionChunk
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+
+	for scanner.Scan() {
+		var doc struct {
+			Text string `json:"text"`
+			Page int    `json:"page"`
+		}
+
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			return nil, fmt.Errorf("unmarshal: %w", err)
+		}
+
+		idx := strings.Index(book, doc.Text)
+		if idx == -1 {
+			continue
+		}
+
+		chunks = append(chunks, sectionChunk{
+			chunk: chunk{
+				text: doc.Text,
+				span: span{start: idx, end: idx + len(doc.Text)},
+			},
+			page: doc.Page,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner: %w", err)
+	}
+
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("no section chunks located in the book text")
+	}
+
+	return chunks, nil
+}
+
+func chunksOnly(sections []sectionChunk) []chunk {
+	chunks := make([]chunk, len(sections))
+	for i, s := range sections {
+		chunks[i] = s.chunk
+	}
+
+	return chunks
+}
+
+// relevantSpans maps every golden question to the spans of the sections on
+// its relevant pages.
+func relevantSpans(goldens []golden, sections []sectionChunk) [][]span {
+	spans := make([][]span, len(goldens))
+
+	for i, g := range goldens {
+		pages := make(map[int]bool)
+		for _, page := range g.Pages {
+			pages[page] = true
+		}
+
+		for _, s := range sections {
+			if pages[s.page] {
+				spans[i] = append(spans[i], s.span)
+			}
+		}
+	}
+
+	return spans
+}
+
+func questions(goldens []golden) []string {
+	texts := make([]string, len(goldens))
+	for i, g := range goldens {
+		texts[i] = g.Question
+	}
+
+	return texts
+}
+
+func readGolden(goldenFile string) ([]golden, error) {
+	data, err := os.ReadFile(goldenFile)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	var goldens []golden
+	if err := json.Unmarshal(data, &goldens); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	if len(goldens) == 0 {
+		return nil, fmt.Errorf("golden dataset is empty")
+	}
+
+	return goldens, nil
+}
+
+// =============================================================================
+
+func installSystem() (models.Path, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Minute)
+	defer cancel()
+
+	libs, err := libs.New(
+		libs.WithVersion(defaults.LibVersion("")),
+	)
+	if err != nil {
+		return models.Path{}, err
+	}
+
+	if _, err := libs.Download(ctx, kronk.FmtLogger); err != nil {
+		return models.Path{}, fmt.Errorf("unable to install llama.cpp: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	mdls, err := models.New()
+	if err != nil {
+		return models.Path{}, fmt.Errorf("unable to create models api: %w", err)
+	}
+
+	infoEmbed, err := mdls.Download(context.Background(), kronk.FmtLogger, modelEmbedSource)
+	if err != nil {
+		return models.Path{}, fmt.Errorf("unable to install model: %w", err)
+	}
+
+	return infoEmbed, nil
+}
+
+func newKronk(mp models.Path) (*kronk.Kronk, error) {
+	if err := kronk.Init(); err != nil {
+		return nil, fmt.Errorf("unable to init kronk: %w", err)
+	}
+
+	krn, err := kronk.New(
+		model.WithModelFiles(mp.ModelFiles),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to create inference model: %w", err)
+	}
+
+	return krn, nil
+}
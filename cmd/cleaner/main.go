@@ -1,54 +1,134 @@
 // This program takes the Ultimate Go Notebook in PDF form and creates chunks
-// from the different sections in the book. If these chunks are over 500 words,
-// then it breaks those up into 250 word chunks. Each chunk exists on it's own
-// line and vectorized.
-// NOTE:
-// More needs to be done. Code examples are flattened out as an example.
+// from the different sections in the book. Chunk sizes are measured in model
+// tokens since that is the unit the embedding model's limit is expressed in.
+// If a section is over the maximum number of tokens, then it is broken up
+// into maximum sized pieces. Each chunk exists on it's own line and vectorized.
+//
+// Code examples are detected with line based heuristics and each example is
+// kept intact as its own chunk with a language hint. They are never split in
+// the middle of a function.
 package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"code.sajari.com/docconv/v2"
+	"github.com/google/uuid"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/docling"
+	"github.com/ardanlabs/ai-training/foundation/logger"
+	"github.com/ardanlabs/ai-training/foundation/tiktoken"
+	"github.com/ardanlabs/ai-training/foundation/ui"
+	"github.com/ardanlabs/ai-training/foundation/vector"
 )
 
+var (
+	sourceFile = "/Users/bill/Documents/book/FE-UGN-41.pdf"
+	urlDocling = "http://localhost:5001"
+	urlEmbed   = "http://localhost:11435/v1/embeddings"
+	modelEmbed = "embeddinggemma-300m-qat-Q8_0"
+
+	// maxChunkTokens is the largest chunk we will save. The embedding models
+	// we use can truncate input over 512 tokens.
+	maxChunkTokens = 500
+)
+
+func init() {
+	if v := os.Getenv("SOURCE_FILE"); v != "" {
+		sourceFile = v
+	}
+
+	if v := os.Getenv("DOC_SERVER"); v != "" {
+		urlDocling = v
+	}
+
+	if v := os.Getenv("LLM_SERVER"); v != "" {
+		urlEmbed = v
+	}
+
+	if v := os.Getenv("LLM_MODEL"); v != "" {
+		modelEmbed = v
+	}
+
+	if v := os.Getenv("MAX_CHUNK_TOKENS"); v != "" {
+		if max, err := strconv.Atoi(v); err == nil {
+			maxChunkTokens = max
+		}
+	}
+}
+
+// log is the run's structured logger. Every record carries a run id so
+// pipeline runs can be post-processed apart. Debug level shows every
+// chunk as it is cut; LLM_LOG_LEVEL and LLM_LOG_FORMAT adjust the
+// output the same way they do for the client.
+var log = newLogger()
+
+func newLogger() *slog.Logger {
+	format := logger.FormatText
+	if os.Getenv("LLM_LOG_FORMAT") == "json" {
+		format = logger.FormatJSON
+	}
+
+	level := slog.LevelInfo
+	if v := os.Getenv("LLM_LOG_LEVEL"); v != "" {
+		level.UnmarshalText([]byte(v))
+	}
+
+	return logger.New(os.Stdout, format, level, "cleaner").With("run_id", uuid.NewString())
+}
+
 func main() {
 	if err := run(); err != nil {
-		log.Fatal(err)
+		log.Error("run", "err", err)
+		os.Exit(1)
 	}
 }
 
 func run() error {
-	if err := convertPDFtoTxt(); err != nil {
-		return fmt.Errorf("convertPDFtoTxt: %w", err)
+	if err := convertToTxt(); err != nil {
+		return fmt.Errorf("convertToTxt: %w", err)
 	}
 
 	if err := findChunks(); err != nil {
-		return fmt.Errorf("convertPDFtoTxt: %w", err)
+		return fmt.Errorf("findChunks: %w", err)
 	}
 
 	return nil
 }
 
-func convertPDFtoTxt() error {
+func convertToTxt() error {
 	if _, err := os.Stat("zarf/data/book.txt"); !os.IsNotExist(err) {
 		return nil
 	}
 
-	input, err := os.Open("/Users/bill/Documents/book/FE-UGN-41.pdf")
-	if err != nil {
-		return fmt.Errorf("open file: %w", err)
+	// PDF's are converted locally. Everything else (docx, pptx, epub) is
+	// routed through the Docling service.
+	var doc string
+	var err error
+
+	switch filepath.Ext(sourceFile) {
+	case ".pdf":
+		doc, err = convertPDF()
+
+	default:
+		doc, err = convertWithDocling()
 	}
-	defer input.Close()
 
-	doc, _, err := docconv.ConvertPDF(input)
 	if err != nil {
-		return fmt.Errorf("read file: %w", err)
+		return fmt.Errorf("convert file: %w", err)
 	}
 
 	output, err := os.Create("zarf/data/book.txt")
@@ -64,10 +144,40 @@ func convertPDFtoTxt() error {
 	return nil
 }
 
+func convertPDF() (string, error) {
+	input, err := os.Open(sourceFile)
+	if err != nil {
+		return "", fmt.Errorf("open file: %w", err)
+	}
+	defer input.Close()
+
+	doc, _, err := docconv.ConvertPDF(input)
+	if err != nil {
+		return "", fmt.Errorf("read file: %w", err)
+	}
+
+	return doc, nil
+}
+
+func convertWithDocling() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	doc := docling.New(urlDocling)
+
+	data, err := doc.ConvertFile(ctx, sourceFile, docling.NewOptions())
+	if err != nil {
+		return "", fmt.Errorf("docling: %w", err)
+	}
+
+	return data, nil
+}
+
 func findChunks() error {
 
 	// This code attempts to find the block of text for each section from
-	// the outline in the book. The sections are down below.
+	// the outline in the book. The sections are detected from the book's
+	// own table of contents so other documents work without editing source.
 
 	inputB, err := os.ReadFile("zarf/data/book.txt")
 	if err != nil {
@@ -76,269 +186,462 @@ func findChunks() error {
 
 	input := string(inputB)
 
-	var chunks []string
+	sections := findSections(input)
+	if len(sections) == 0 {
+		return fmt.Errorf("no table of contents detected")
+	}
 
-	for i := range sections {
-		strSection := sections[i]
-		if strSection == "END" {
-			break
-		}
+	pages := findSectionPages(input)
+
+	var chunks []sectionChunk
+	var chapter string
+	var searchFrom int
 
-		endSection := sections[i+1]
+	for i, strSection := range sections {
+		if strings.HasPrefix(strSection, "Chapter ") {
+			chapter = strSection
+		}
 
-		srtIdx := strings.Index(input, strSection+"\n")
+		// Section headings repeat in the TOC, so only search the text that
+		// follows the previous section we found.
+		srtIdx := strings.Index(input[searchFrom:], strSection+"\n")
+		if srtIdx == -1 {
+			continue
+		}
+		srtIdx += searchFrom
 
-		switch {
-		case endSection != "END":
-			endIdx := strings.Index(input, endSection+"\n")
-			chunks = append(chunks, input[srtIdx:endIdx])
+		// The section runs until the next section heading we can locate.
+		endIdx := len(input)
 
-		default:
-			chunks = append(chunks, input[srtIdx:])
+		for j := i + 1; j < len(sections); j++ {
+			if idx := strings.Index(input[srtIdx+len(strSection):], sections[j]+"\n"); idx != -1 {
+				endIdx = srtIdx + len(strSection) + idx
+				break
+			}
 		}
+
+		chunks = append(chunks, sectionChunk{
+			chapter: chapter,
+			section: strSection,
+			page:    pages[strSection],
+			text:    input[srtIdx:endIdx],
+		})
+
+		searchFrom = srtIdx + len(strSection)
 	}
 
 	// -------------------------------------------------------------------------
 
 	// This code takes those chunks we found and cleans them up. It won't
-	// save a chunk larger than 500 words. If we have a chunk that is larger,
-	// then it's broken up into 250 word sections.
+	// save a chunk larger than the maximum number of tokens. If we have a
+	// chunk that is larger, then it's broken up into maximum sized pieces.
 
-	output, err := os.Create("zarf/data/book.chunks")
+	tkn, err := tiktoken.NewTiktoken()
 	if err != nil {
-		return fmt.Errorf("open file: %w", err)
+		return fmt.Errorf("new tiktoken: %w", err)
 	}
-	defer output.Close()
+
+	var docs []chunkDoc
 
 	for _, chunk := range chunks {
 
-		// Clean up the chunk and replace \n with spaces.
+		// Separate the code examples from the prose so a code example is
+		// never split in the middle of a function.
+		for _, seg := range splitSegments(chunk.text) {
+			if seg.code {
+				docs = append(docs, codeChunk(chunk, seg.text))
+				continue
+			}
 
-		// Figure out how many words we have.
-		words := strings.Fields(chunk)
+			docs = append(docs, proseChunks(tkn, chunk, seg.text)...)
+		}
+	}
+
+	for i := range docs {
+		docs[i].Tokens = tkn.TokenCount(docs[i].Text)
+	}
 
-		const min = 200
-		const max = 500
+	// -------------------------------------------------------------------------
 
-		// We have less than or exactly max words.
-		if len(words) >= min && len(words) <= max {
-			fmt.Println(chunk)
-			output.WriteString("<CHUNK>\n")
-			output.WriteString(chunk)
-			output.WriteString("\n")
-			output.WriteString("</CHUNK>\n")
-			continue
+	// The PDF repeats boilerplate like headers and footers across pages.
+	// Drop chunks that are near duplicates of a chunk we already kept so
+	// they don't pollute the index. This step needs the embedding model
+	// and is skipped when DEDUP=off.
+
+	if os.Getenv("DEDUP") != "off" {
+		docs, err = dedupChunks(docs)
+		if err != nil {
+			return fmt.Errorf("dedup chunks: %w", err)
 		}
+	}
 
-		var idx int
+	// -------------------------------------------------------------------------
 
-		for {
-			// We have the last section of words.
-			if len(words[idx:]) <= max && len(words[idx:]) >= min {
-				fmt.Println(strings.Join(words[idx:], " "))
-				output.WriteString("<CHUNK>\n")
-				output.WriteString(strings.Join(words[idx:], " "))
-				output.WriteString("\n")
-				output.WriteString("</CHUNK>\n")
-				break
-			}
+	if err := writeJSONL("zarf/data/book.chunks", docs); err != nil {
+		return fmt.Errorf("write jsonl: %w", err)
+	}
 
-			// Throw this out since it's too small.
-			if len(words[idx:]) < min {
+	// The CSV export is optional and only produced when asked for.
+	if csvFile := os.Getenv("CSV_FILE"); csvFile != "" {
+		if err := writeCSV(csvFile, docs); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// chunkDoc represents a single chunk in the structured chunks file. Each
+// chunk is written as one JSON document per line.
+type chunkDoc struct {
+	Text     string `json:"text"`
+	Chapter  string `json:"chapter"`
+	Section  string `json:"section"`
+	Page     int    `json:"page"`
+	Language string `json:"language,omitempty"`
+	Tokens   int    `json:"tokens"`
+}
+
+// dedupChunks embeds every chunk and drops the ones whose embedding is more
+// than 0.98 similar to a chunk that was already kept.
+func dedupChunks(docs []chunkDoc) ([]chunkDoc, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+
+	llm := client.NewLLM(urlEmbed, modelEmbed)
+
+	const threshold = 0.98
+
+	var kept []chunkDoc
+	var keptVectors [][]float64
+
+	p := ui.NewProgress(os.Stdout, "Deduping Data", len(docs))
+
+	for counter, doc := range docs {
+		p.Update(counter)
+
+		vec, err := llm.EmbedText(ctx, doc.Text)
+		if err != nil {
+			return nil, fmt.Errorf("embedding: %w", err)
+		}
+
+		var dupe bool
+
+		for _, kv := range keptVectors {
+			if vector.CosineSimilarity(kv, vec) > threshold {
+				dupe = true
 				break
 			}
+		}
 
-			// This is a max chunk of words.
-			fmt.Println(strings.Join(words[idx:idx+max], " "))
-			output.WriteString("<CHUNK>\n")
-			output.WriteString(strings.Join(words[idx:idx+max], " "))
-			output.WriteString("\n")
-			output.WriteString("</CHUNK>\n")
+		if dupe {
+			continue
+		}
 
-			idx = idx + max
+		kept = append(kept, doc)
+		keptVectors = append(keptVectors, vec)
+	}
+
+	p.Done()
+
+	log.Info("dedup complete", "kept", len(kept), "dropped", len(docs)-len(kept))
+
+	return kept, nil
+}
+
+func writeJSONL(fileName string, docs []chunkDoc) error {
+	output, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer output.Close()
+
+	for _, doc := range docs {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("marshal: %w", err)
+		}
+
+		if _, err := output.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func writeCSV(fileName string, docs []chunkDoc) error {
+	output, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer output.Close()
+
+	w := csv.NewWriter(output)
+	defer w.Flush()
+
+	if err := w.Write([]string{"text", "chapter", "section", "page", "language", "tokens"}); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+
+	for _, doc := range docs {
+		record := []string{
+			doc.Text,
+			doc.Chapter,
+			doc.Section,
+			strconv.Itoa(doc.Page),
+			doc.Language,
+			strconv.Itoa(doc.Tokens),
+		}
+
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("write record: %w", err)
 		}
 	}
 
 	return nil
 }
 
-var sections = []string{
-	"Welcome",
-	"Intended Audience",
-	"Acknowledgements",
-	"Table of Contents",
-	"Chapter 1: Introduction",
-	"1.1 Reading Code",
-	"1.2 Legacy Software",
-	"1.3 Mental Models",
-	"1.4 Productivity vs Performance",
-	"1.5 Correctness vs Performance",
-	"1.6 Understanding Rules",
-	"1.7 Differences Between Senior vs Junior Developers",
-	"1.8 Design Philosophy",
-	"1.8.1 Integrity",
-	"1.8.2 Readability",
-	"1.8.3 Simplicity",
-	"1.8.4 Performance",
-	"1.8.5 Micro-Optimizations",
-	"1.8.6 Data-Orientation",
-	"1.8.7 Interface And Composition",
-	"1.8.8 Writing Concurrent Software",
-	"1.8.9 Signaling and Channels",
-	"Chapter 2: Language Mechanics",
-	"2.1 Built-in Types",
-	"2.2 Word Size",
-	"2.3 Zero Value Concept",
-	"2.4 Declare and Initialize",
-	"2.5 Conversion vs Casting",
-	"2.6 Struct and Construction Mechanics",
-	"2.7 Padding and Alignment",
-	"2.8 Assigning Values",
-	"2.9 Pointers",
-	"2.10 Pass By Value",
-	"2.11 Escape Analysis",
-	"2.12 Stack Growth",
-	"2.13 Garbage Collection",
-	"2.14 Constants",
-	"2.15 IOTA",
-	"Chapter 3: Data Structures",
-	"3.1 CPU Caches",
-	"3.2 Translation Lookaside Buffer (TLB)",
-	"3.3 Declaring and Initializing Values",
-	"3.4 String Assignments",
-	"3.5 Iterating Over Collections",
-	"3.6 Value Semantic Iteration",
-	"3.7 Pointer Semantic Iteration",
-	"3.8 Data Semantic Guideline For Built-In Types",
-	"3.9 Different Type Arrays",
-	"3.10 Contiguous Memory Construction",
-	"3.11 Constructing Slices",
-	"3.12 Slice Length vs Capacity",
-	"3.13 Data Semantic Guideline For Slices",
-	"3.14 Contiguous Memory Layout",
-	"3.15 Appending With Slices",
-	"3.16 Slicing Slices",
-	"3.17 Mutations To The Backing Array",
-	"3.18 Copying Slices Manually",
-	"3.19 Slices Use Pointer Semantic Mutation",
-	"3.20 Linear Traversal Efficiency",
-	"3.21 UTF-8",
-	"3.22 Declaring And Constructing Maps",
-	"3.23 Lookups and Deleting Map Keys",
-	"3.24 Key Map Restrictions",
-	"Chapter 4: Decoupling",
-	"4.1 Methods",
-	"4.2 Method Calls",
-	"4.3 Data Semantic Guideline For Internal Types",
-	"4.4 Data Semantic Guideline For Struct Types",
-	"4.5 Methods Are Just Functions",
-	"4.6 Know The Behavior of the Code",
-	"4.7 Interfaces",
-	"4.8 Interfaces Are Valueless",
-	"4.9 Implementing Interfaces",
-	"4.10 Polymorphism",
-	"4.11 Method Set Rules",
-	"4.12 Slice of Interface",
-	"4.13 Embedding",
-	"4.14 Exporting",
-	"Chapter 5: Software Design",
-	"5.1 Grouping Different Types of Data",
-	"5.2 Don’t Design With Interfaces",
-	"5.3 Composition",
-	"5.4 Decoupling With Interfaces",
-	"5.5 Interface Composition",
-	"5.6 Precision Review",
-	"5.7 Implicit Interface Conversions",
-	"5.8 Type assertions",
-	"5.9 Interface Pollution",
-	"5.10 Interface Ownership",
-	"5.11 Error Handling",
-	"5.12 Always Use The Error Interface",
-	"5.13 Handling Errors",
-	"Chapter 6: Concurrency",
-	"6.1 Scheduler Semantics",
-	"6.2 Concurrency Basics",
-	"6.3 Preemptive Scheduler",
-	"6.4 Data Races",
-	"6.5 Data Race Example",
-	"6.6 Race Detection",
-	"6.7 Atomics",
-	"6.8 Mutexes",
-	"6.9 Read/Write Mutexes",
-	"6.10 Channel Semantics",
-	"6.11 Channel Patterns",
-	"6.11.1 Wait For Result",
-	"6.11.2 Fan Out/In",
-	"6.11.3 Wait For Task",
-	"6.11.4 Pooling",
-	"6.11.5 Drop",
-	"6.11.6 Cancellation",
-	"6.11.7 Fan Out/In Semaphore",
-	"6.11.8 Bounded Work Pooling",
-	"6.11.9 Retry Timeout",
-	"6.11.10 Channel Cancellation",
-	"Chapter 7: Testing",
-	"7.1 Basic Unit Test",
-	"7.2 Table Unit Test",
-	"7.3 Web Call Mocking",
-	"7.4 Internal Web Endpoints",
-	"7.5 Basic Sub-Tests",
-	"Chapter 8: Benchmarking",
-	"8.1 Basic Benchmark",
-	"8.2 Basic Sub-Benchmarks",
-	"8.3 Validate Benchmarks",
-	"Chapter 9: Generics",
-	"9.1 Basic Syntax",
-	"9.2 Underlying Types",
-	"9.3 Struct Types",
-	"9.4 Behavior As Constraint",
-	"9.5 Type As Constraint",
-	"9.6 Multi-Type Parameters",
-	"9.7 Field Access",
-	"9.8 Slice Constraints",
-	"9.9 Channels",
-	"9.10 Hash Tables",
-	"10.1 Introduction",
-	"10.1.1 The Basics of Profiling",
-	"10.1.2 Types of Profiling",
-	"10.1.3 Hints to interpret what I see in the profile",
-	"10.1.4 Rules of Performance",
-	"10.1.5 Go and OS Tooling",
-	"10.2 Example Code",
-	"10.3 Benchmarking",
-	"10.4 Memory Profiling",
-	"10.5 Inlining",
-	"10.6 Escape Analysis",
-	"Chapter 11: Profiling Live Code",
-	"11.1 Example Code",
-	"11.2 Generating a GC Trace",
-	"11.3 Generating Load And Evaluation",
-	"11.4 Adding Profile Endpoints",
-	"11.5 Viewing Memory Profile",
-	"11.6 Removing Allocations",
-	"Chapter 12: Tracing",
-	"12.1 Example Code",
-	"12.2 Generating Traces",
-	"12.3 Viewing Traces",
-	"12.4 Fan-Out",
-	"12.5 Cache Friendly",
-	"12.6 Fan-Out Results",
-	"12.7 Pooling",
-	"12.8 Pooling Results",
-	"12.9 GC Percentage",
-	"12.10 Tasks And Regions",
-	"Chapter 13: Stack Traces / Core Dumps",
-	"13.1 ABI Changes In 1.17",
-	"13.2 Basic Example",
-	"13.3 Word Packing",
-	"13.4 Go 1.17 ABI Changes",
-	"13.5 Generating Core Dumps",
-	"Chapter 14: Blog Posts",
-	"14.1 Stacks And Pointer Mechanics",
-	"14.2 Escape Analysis Mechanics",
-	"14.3 Scheduling In Go: OS Scheduler",
-	"14.4 Scheduling In Go: Go Scheduler",
-	"14.5 Scheduling In Go: Concurrency",
-	"14.6 Garbage Collection Semantics",
-	"END",
+// sectionChunk represents the block of text for a single section from the
+// book's outline along with where that section lives in the book.
+type sectionChunk struct {
+	chapter string
+	section string
+	page    int
+	text    string
+}
+
+// tocLineRE matches the table of contents lines which carry the approximate
+// page number for every section. e.g. "2.10 Pass By Value......33"
+var tocLineRE = regexp.MustCompile(`(?m)^(.+?)\.{3,}(\d+)\s*$`)
+
+// findSections parses the table of contents from the book text and returns
+// the section titles in the order they appear in the book.
+func findSections(input string) []string {
+	seen := make(map[string]bool)
+
+	var sections []string
+
+	for _, match := range tocLineRE.FindAllStringSubmatch(input, -1) {
+		title := strings.TrimSpace(match[1])
+		title = strings.TrimRight(title, ".")
+
+		if title == "" || seen[title] {
+			continue
+		}
+
+		seen[title] = true
+		sections = append(sections, title)
+	}
+
+	return sections
+}
+
+// findSectionPages parses the table of contents from the book text and
+// returns the approximate starting page number for each section title.
+func findSectionPages(input string) map[string]int {
+	pages := make(map[string]int)
+
+	for _, match := range tocLineRE.FindAllStringSubmatch(input, -1) {
+		title := strings.TrimSpace(match[1])
+		title = strings.TrimRight(title, ".")
+
+		page, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+
+		if _, exists := pages[title]; !exists {
+			pages[title] = page
+		}
+	}
+
+	return pages
+}
+
+func proseChunks(tkn *tiktoken.Tiktoken, sc sectionChunk, chunk string) []chunkDoc {
+	var docs []chunkDoc
+
+	newDoc := func(text string) chunkDoc {
+		log.Debug("prose chunk", "section", sc.section, "page", sc.page, "text", text)
+
+		return chunkDoc{
+			Text:    text,
+			Chapter: sc.chapter,
+			Section: sc.section,
+			Page:    sc.page,
+		}
+	}
+
+	// Chunks under the minimum are thrown out since they are boilerplate
+	// that carries no meaning on its own.
+	min := maxChunkTokens * 2 / 5
+	max := maxChunkTokens
+
+	total := tkn.TokenCount(chunk)
+
+	// We have less than or exactly max tokens.
+	if total >= min && total <= max {
+		return append(docs, newDoc(chunk))
+	}
+
+	// Break the section on sentence boundaries so a chunk never stops in
+	// the middle of a thought.
+	var piece []string
+	var pieceTokens int
+
+	for _, sentence := range splitSentences(chunk) {
+		sentenceTokens := tkn.TokenCount(sentence)
+
+		// A single run-on sentence can blow past the budget on its own.
+		// Split it on exact token boundaries instead and show where the
+		// cut lands.
+		if sentenceTokens > max {
+			ids := tkn.Encode(sentence)
+			log.Warn("sentence over token budget", "tokens", len(ids), "cut", tkn.Decode(ids[max-5:max]))
+
+			if pieceTokens >= min {
+				docs = append(docs, newDoc(strings.Join(piece, " ")))
+			}
+			piece = piece[:0]
+			pieceTokens = 0
+
+			for _, part := range tkn.Split(sentence, max, 0) {
+				if tkn.TokenCount(part) >= min {
+					docs = append(docs, newDoc(part))
+				}
+			}
+
+			continue
+		}
+
+		// This is a max chunk of tokens.
+		if pieceTokens > 0 && pieceTokens+sentenceTokens > max {
+			docs = append(docs, newDoc(strings.Join(piece, " ")))
+			piece = piece[:0]
+			pieceTokens = 0
+		}
+
+		piece = append(piece, sentence)
+		pieceTokens += sentenceTokens
+	}
+
+	// We have the last sentences. Throw them out if they are too small.
+	if pieceTokens >= min {
+		docs = append(docs, newDoc(strings.Join(piece, " ")))
+	}
+
+	return docs
+}
+
+// sentenceRE matches a run of text up to and including its terminating
+// punctuation.
+var sentenceRE = regexp.MustCompile(`[^.!?]+[.!?]*`)
+
+// splitSentences breaks prose into sentences. The text is flattened first
+// since the PDF extraction hard wraps lines mid sentence.
+func splitSentences(text string) []string {
+	flat := strings.Join(strings.Fields(text), " ")
+
+	var sentences []string
+
+	for _, sentence := range sentenceRE.FindAllString(flat, -1) {
+		if sentence = strings.TrimSpace(sentence); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+
+	return sentences
+}
+
+func codeChunk(sc sectionChunk, code string) chunkDoc {
+	log.Debug("code chunk", "section", sc.section, "page", sc.page, "text", code)
+
+	return chunkDoc{
+		Text:     "```go\n" + code + "\n```",
+		Chapter:  sc.chapter,
+		Section:  sc.section,
+		Page:     sc.page,
+		Language: "go",
+	}
+}
+
+// =============================================================================
+
+// segment represents a run of lines from a section that is either prose or
+// a code example.
+type segment struct {
+	text string
+	code bool
+}
+
+// listingRE matches the listing captions the book places in front of every
+// code example.
+var listingRE = regexp.MustCompile(`^Listing \d+\.\d+`)
+
+// codeLineRE matches lines that look like Go code. The PDF extraction strips
+// all indentation, so we key off of tokens instead of leading whitespace.
+var codeLineRE = regexp.MustCompile(`^(package |import |func |type |var |const |return|if |for |switch |select |case |defer |go |break|continue|//|\}|\{|println|fmt\.)|(:=|==|!=|&&|\|\|)|[{})];?\s*$`)
+
+// splitSegments breaks a section into prose and code segments. A code
+// example starts at a listing caption and continues while the lines still
+// look like code or there are unbalanced braces.
+func splitSegments(chunk string) []segment {
+	lines := strings.Split(chunk, "\n")
+
+	var segments []segment
+	var prose []string
+	var code []string
+	var depth int
+	var inCode bool
+
+	flushProse := func() {
+		if text := strings.TrimSpace(strings.Join(prose, "\n")); text != "" {
+			segments = append(segments, segment{text: text})
+		}
+		prose = prose[:0]
+	}
+
+	flushCode := func() {
+		if text := strings.TrimSpace(strings.Join(code, "\n")); text != "" {
+			segments = append(segments, segment{text: text, code: true})
+		}
+		code = code[:0]
+		depth = 0
+		inCode = false
+	}
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if !inCode {
+			if listingRE.MatchString(trimmed) {
+				flushProse()
+				inCode = true
+				continue
+			}
+
+			prose = append(prose, line)
+			continue
+		}
+
+		depth += strings.Count(trimmed, "{") - strings.Count(trimmed, "}")
+
+		// The example is over when the braces are balanced and the line
+		// no longer looks like code.
+		if depth <= 0 && trimmed != "" && !codeLineRE.MatchString(trimmed) {
+			flushCode()
+			prose = append(prose, line)
+			continue
+		}
+
+		code = append(code, line)
+	}
+
+	if inCode {
+		flushCode()
+	}
+	flushProse()
+
+	return segments
 }
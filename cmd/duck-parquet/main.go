@@ -0,0 +1,76 @@
+// This program exports the items table from a duckdb database to a parquet
+// file and imports it back. Use it to share a computed embedding set with
+// the class so nobody has to re-run the vectorization.
+//
+// # Running the program:
+//
+//	$ make duck-parquet-export
+//	$ make duck-parquet-import
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/duckdbstore"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	dbPath := flag.String("db", "zarf/data/duck-ex13-step3.db", "duckdb database to use")
+	dimentions := flag.Int("dimensions", 768, "dimensions of the embedding column")
+	export := flag.String("export", "", "parquet file to export the items table to")
+	imprt := flag.String("import", "", "parquet file to import into the items table")
+	flag.Parse()
+
+	if (*export == "") == (*imprt == "") {
+		return fmt.Errorf("specify either -export or -import")
+	}
+
+	db, err := duckdbstore.Open(*dbPath, *dimentions)
+	if err != nil {
+		return fmt.Errorf("error connecting to database: %w", err)
+	}
+	defer db.Close()
+
+	// -------------------------------------------------------------------------
+
+	t := time.Now()
+
+	switch {
+	case *export != "":
+		if err := duckdbstore.ExportParquet(db, *export); err != nil {
+			return fmt.Errorf("error exporting: %w", err)
+		}
+
+		info, err := os.Stat(*export)
+		if err != nil {
+			return fmt.Errorf("error checking file: %w", err)
+		}
+
+		fmt.Printf("Exported items to %s (%d bytes) in %v\n", *export, info.Size(), time.Since(t))
+
+	case *imprt != "":
+		if err := duckdbstore.ImportParquet(db, *imprt); err != nil {
+			return fmt.Errorf("error importing: %w", err)
+		}
+
+		var count int
+		if err := db.QueryRow("SELECT count(*) FROM items;").Scan(&count); err != nil {
+			return fmt.Errorf("error counting rows: %w", err)
+		}
+
+		fmt.Printf("Imported %s, items table now holds %d rows (%v)\n", *imprt, count, time.Since(t))
+	}
+
+	return nil
+}
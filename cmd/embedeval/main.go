@@ -0,0 +1,332 @@
+// This program embeds the same corpus with several embedding models, one
+// DuckDB database per model, and reports the retrieval metrics from the
+// golden dataset plus index size and query latency for each. Use it to
+// pick an embedding model with numbers instead of leaderboard folklore.
+//
+// # Running the program:
+//
+//	$ make embedeval
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/duckdbstore"
+	"github.com/ardanlabs/ai-training/foundation/embedding"
+	"github.com/ardanlabs/kronk/sdk/kronk"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/ardanlabs/kronk/sdk/tools/defaults"
+	"github.com/ardanlabs/kronk/sdk/tools/libs"
+	"github.com/ardanlabs/kronk/sdk/tools/models"
+)
+
+const (
+	chunksFile = "zarf/data/book.chunks"
+
+	defaultModels = "ggml-org/embeddinggemma-300m-qat-Q8_0,gpustack/bge-m3-GGUF,nomic-ai/nomic-embed-text-v1.5-GGUF"
+)
+
+// golden represents one entry in the golden dataset, shared with the
+// rageval command.
+type golden struct {
+	Question string `json:"question"`
+	Pages    []int  `json:"pages"`
+}
+
+// result collects everything measured for one embedding model.
+type result struct {
+	source    string
+	dims      int
+	loadTime  time.Duration
+	indexSize int64
+	queryTime time.Duration
+	recall    float64
+	mrr       float64
+	ndcg      float64
+}
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	modelList := flag.String("models", defaultModels, "comma separated embedding model sources")
+	goldenFile := flag.String("golden", "zarf/data/rageval.json", "golden dataset to evaluate against")
+	k := flag.Int("k", 5, "number of documents to retrieve per question")
+	flag.Parse()
+
+	goldens, err := readGolden(*goldenFile)
+	if err != nil {
+		return fmt.Errorf("unable to read golden dataset: %w", err)
+	}
+
+	if err := installLibs(); err != nil {
+		return fmt.Errorf("unable to install llama.cpp: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	var results []result
+
+	for i, source := range strings.Split(*modelList, ",") {
+		source = strings.TrimSpace(source)
+
+		fmt.Printf("\n=== %s ===\n\n", source)
+
+		res, err := evaluateModel(source, fmt.Sprintf("zarf/data/duck-embedeval-%d.db", i), goldens, *k)
+		if err != nil {
+			return fmt.Errorf("unable to evaluate %s: %w", source, err)
+		}
+
+		results = append(results, res)
+	}
+
+	// -------------------------------------------------------------------------
+
+	fmt.Printf("\n%-45s %5s %9s %9s %9s %7s %7s %7s\n",
+		"model", "dims", "load", "index", "query", "recall", "mrr", "ndcg")
+
+	for _, res := range results {
+		fmt.Printf("%-45s %5d %9s %8dK %9s %7.3f %7.3f %7.3f\n",
+			res.source,
+			res.dims,
+			res.loadTime.Round(time.Second),
+			res.indexSize/1024,
+			res.queryTime.Round(time.Microsecond),
+			res.recall,
+			res.mrr,
+			res.ndcg)
+	}
+
+	return nil
+}
+
+// evaluateModel loads one embedding model, vectorizes the corpus into its
+// own database, and runs the golden questions against it.
+func evaluateModel(source string, dbPath string, goldens []golden, k int) (result, error) {
+	mdls, err := models.New()
+	if err != nil {
+		return result{}, fmt.Errorf("unable to create models api: %w", err)
+	}
+
+	info, err := mdls.Download(context.Background(), kronk.FmtLogger, source)
+	if err != nil {
+		return result{}, fmt.Errorf("unable to install model: %w", err)
+	}
+
+	krn, err := newKronk(info)
+	if err != nil {
+		return result{}, fmt.Errorf("unable to create embedding model: %w", err)
+	}
+	defer func() {
+		if err := krn.Unload(context.Background()); err != nil {
+			fmt.Printf("failed to unload embedding model: %v", err)
+		}
+	}()
+
+	emb := embedding.New(krn)
+
+	// -------------------------------------------------------------------------
+
+	// The models disagree on dimensions, so probe for it before creating
+	// the table.
+	dims, err := probeDimensions(emb)
+	if err != nil {
+		return result{}, fmt.Errorf("unable to probe dimensions: %w", err)
+	}
+
+	t := time.Now()
+
+	db, err := duckdbstore.LoadData(dbPath, emb, dims, chunksFile)
+	if err != nil {
+		return result{}, fmt.Errorf("error connecting to database: %w", err)
+	}
+	defer db.Close()
+
+	loadTime := time.Since(t)
+
+	// -------------------------------------------------------------------------
+
+	recall, mrr, ndcg, queryTime, err := evaluate(emb, db, goldens, k)
+	if err != nil {
+		return result{}, err
+	}
+
+	var indexSize int64
+	if info, err := os.Stat(dbPath); err == nil {
+		indexSize = info.Size()
+	}
+
+	return result{
+		source:    source,
+		dims:      dims,
+		loadTime:  loadTime,
+		indexSize: indexSize,
+		queryTime: queryTime,
+		recall:    recall,
+		mrr:       mrr,
+		ndcg:      ndcg,
+	}, nil
+}
+
+// evaluate runs every golden question and returns the averaged metrics
+// plus the average search latency, embedding time excluded.
+func evaluate(emb *embedding.Model, db *sql.DB, goldens []golden, k int) (float64, float64, float64, time.Duration, error) {
+	var sumRecall float64
+	var sumMRR float64
+	var sumNDCG float64
+	var queryTime time.Duration
+
+	for _, g := range goldens {
+		vec, err := func() ([]float32, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			return emb.Embed(ctx, g.Question)
+		}()
+		if err != nil {
+			return 0, 0, 0, 0, fmt.Errorf("embed: %w", err)
+		}
+
+		t := time.Now()
+
+		docs, err := duckdbstore.Search(db, vec, duckdbstore.Filter{}, k)
+		if err != nil {
+			return 0, 0, 0, 0, fmt.Errorf("error searching: %w", err)
+		}
+
+		queryTime += time.Since(t)
+
+		// ---------------------------------------------------------------------
+
+		relevant := make(map[int]bool)
+		for _, page := range g.Pages {
+			relevant[page] = true
+		}
+
+		recall, mrr, ndcg := score(docs, relevant, k)
+
+		sumRecall += recall
+		sumMRR += mrr
+		sumNDCG += ndcg
+	}
+
+	n := float64(len(goldens))
+
+	return sumRecall / n, sumMRR / n, sumNDCG / n, queryTime / time.Duration(len(goldens)), nil
+}
+
+// score computes recall@k, MRR, and nDCG@k for a single question using
+// binary relevance on the page number.
+func score(docs []duckdbstore.Document, relevant map[int]bool, k int) (recall float64, mrr float64, ndcg float64) {
+	var found int
+	var dcg float64
+
+	seen := make(map[int]bool)
+
+	for rank, doc := range docs {
+		if !relevant[doc.Page] {
+			continue
+		}
+
+		if mrr == 0 {
+			mrr = 1 / float64(rank+1)
+		}
+
+		dcg += 1 / math.Log2(float64(rank+2))
+
+		if !seen[doc.Page] {
+			seen[doc.Page] = true
+			found++
+		}
+	}
+
+	var idcg float64
+	for rank := range min(k, len(relevant)) {
+		idcg += 1 / math.Log2(float64(rank+2))
+	}
+
+	recall = float64(found) / float64(min(k, len(relevant)))
+	ndcg = dcg / idcg
+
+	return recall, mrr, ndcg
+}
+
+// probeDimensions embeds a short text to learn the model's vector size.
+func probeDimensions(emb *embedding.Model) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	vec, err := emb.Embed(ctx, "dimension probe")
+	if err != nil {
+		return 0, fmt.Errorf("embed: %w", err)
+	}
+
+	return len(vec), nil
+}
+
+func readGolden(goldenFile string) ([]golden, error) {
+	data, err := os.ReadFile(goldenFile)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	var goldens []golden
+	if err := json.Unmarshal(data, &goldens); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	if len(goldens) == 0 {
+		return nil, fmt.Errorf("golden dataset is empty")
+	}
+
+	return goldens, nil
+}
+
+// =============================================================================
+
+func installLibs() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Minute)
+	defer cancel()
+
+	libs, err := libs.New(
+		libs.WithVersion(defaults.LibVersion("")),
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := libs.Download(ctx, kronk.FmtLogger); err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+
+	return nil
+}
+
+func newKronk(mp models.Path) (*kronk.Kronk, error) {
+	if err := kronk.Init(); err != nil {
+		return nil, fmt.Errorf("unable to init kronk: %w", err)
+	}
+
+	krn, err := kronk.New(
+		model.WithModelFiles(mp.ModelFiles),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to create inference model: %w", err)
+	}
+
+	return krn, nil
+}
@@ -0,0 +1,94 @@
+// Package example01 holds the example's logic behind an exported Run
+// function, following the layout example06 established, so the eval and
+// smoke tooling can drive it programmatically.
+package example01
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ardanlabs/ai-training/foundation/vector"
+)
+
+// Config carries everything Run needs. The example is pure vector math,
+// so only the output destination is configurable.
+type Config struct {
+	Output io.Writer
+}
+
+// data represents a hand crafted embedding.
+type data struct {
+	Name      string
+	Authority float64 // These fields are called features.
+	Animal    float64
+	Human     float64
+	Rich      float64
+	Gender    float64
+}
+
+// Vector can convert the specified data into a vector.
+func (d data) Vector() []float64 {
+	return []float64{
+		d.Authority,
+		d.Animal,
+		d.Human,
+		d.Rich,
+		d.Gender,
+	}
+}
+
+// String pretty prints an embedding to a vector representation.
+func (d data) String() string {
+	return fmt.Sprintf("%f", d.Vector())
+}
+
+// Run compares the hand crafted embeddings with cosine similarity and
+// performs the King - Man + Woman vector math.
+func Run(cfg Config) error {
+
+	// Apply the feature dataPoints to the hand crafted embeddings.
+	dataPoints := []vector.Data{
+		data{Name: "Horse   ", Authority: 0.0, Animal: 1.0, Human: 0.0, Rich: 0.0, Gender: +1.0},
+		data{Name: "Man     ", Authority: 0.0, Animal: 0.0, Human: 1.0, Rich: 0.0, Gender: -1.0},
+		data{Name: "Woman   ", Authority: 0.0, Animal: 0.0, Human: 1.0, Rich: 0.0, Gender: +1.0},
+		data{Name: "King    ", Authority: 1.0, Animal: 0.0, Human: 1.0, Rich: 1.0, Gender: -1.0},
+		data{Name: "Queen   ", Authority: 1.0, Animal: 0.0, Human: 1.0, Rich: 1.0, Gender: +1.0},
+	}
+
+	// -------------------------------------------------------------------------
+
+	// Display the data points.
+	fmt.Fprint(cfg.Output, "\n")
+	for _, v := range dataPoints {
+		fmt.Fprintf(cfg.Output, "Vector: Name(%s) len(%d) %v\n", v.(data).Name, len(v.(data).Vector()), v.(data).Vector())
+	}
+	fmt.Fprint(cfg.Output, "\n")
+
+	// Compare each data point to every other by performing a cosine
+	// similarity comparison. This requires converting each data point
+	// into a vector.
+	for _, target := range dataPoints {
+		results := vector.Similarity(target, dataPoints...)
+
+		for _, result := range results {
+			fmt.Fprintf(cfg.Output, "%s -> %s: %.2f%% similar\n",
+				result.Target.(data).Name,
+				result.DataPoint.(data).Name,
+				result.Percentage)
+		}
+		fmt.Fprint(cfg.Output, "\n")
+	}
+
+	// -------------------------------------------------------------------------
+
+	// You can perform vector math by adding and subtracting vectors.
+	kingSubMan := vector.Sub(dataPoints[3].Vector(), dataPoints[1].Vector())
+	kingSubManPlusWoman := vector.Add(kingSubMan, dataPoints[2].Vector())
+	queen := dataPoints[4].Vector()
+
+	// Now compare a (King - Man + Woman) to a Queen.
+	result := vector.CosineSimilarity(kingSubManPlusWoman, queen)
+	fmt.Fprintf(cfg.Output, "King - Man + Woman ~= Queen similarity: %.2f%%\n", result*100)
+
+	return nil
+}
@@ -0,0 +1,20 @@
+// This smoke test runs the hand crafted vector math end to end. No
+// servers are needed, so it always runs under `go test ./cmd/...`.
+package example01
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSmoke(t *testing.T) {
+	var out strings.Builder
+
+	if err := Run(Config{Output: &out}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "King - Man + Woman ~= Queen") {
+		t.Fatalf("missing the vector math result in output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,104 @@
+// Package example02 carries the example's logic in an exported Run
+// function with its knobs in Config, the layout example06 established,
+// so tests and tooling can drive it against any model server.
+package example02
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/vector"
+)
+
+// Config carries everything Run needs: the embedding endpoint and model
+// plus the output destination.
+type Config struct {
+	EmbedURL   string
+	EmbedModel string
+	Output     io.Writer
+}
+
+// data represents a data point embedded by the model.
+type data struct {
+	Name      string
+	Text      string
+	Embedding []float64 // The vector where the data is embedded in space.
+}
+
+// Vector can convert the specified data into a vector.
+func (d data) Vector() []float64 {
+	return d.Embedding
+}
+
+// Run embeds the hand crafted data points with the model and repeats the
+// similarity comparisons and vector math from example01.
+func Run(ctx context.Context, cfg Config) error {
+
+	// Construct the llm client for access the model server.
+	llm := client.NewLLM(cfg.EmbedURL, cfg.EmbedModel)
+
+	// -------------------------------------------------------------------------
+
+	// Apply the feature vectors to the hand crafted data points.
+	// This time you need to use words since we are using a word based model.
+	dataPoints := []vector.Data{
+		data{Name: "Horse   ", Text: "Animal, Female"},
+		data{Name: "Man     ", Text: "Human,  Male,   Pants, Poor, Worker"},
+		data{Name: "Woman   ", Text: "Human,  Female, Dress, Poor, Worker"},
+		data{Name: "King    ", Text: "Human,  Male,   Pants, Rich, Ruler"},
+		data{Name: "Queen   ", Text: "Human,  Female, Dress, Rich, Ruler"},
+	}
+
+	fmt.Fprint(cfg.Output, "\n")
+
+	// Iterate over each data point and use the LLM to generate the vector
+	// embedding related to the model.
+	for i, dp := range dataPoints {
+		dataPoint := dp.(data)
+
+		vector, err := llm.EmbedText(ctx, dataPoint.Text)
+		if err != nil {
+			return fmt.Errorf("embedding: %w", err)
+		}
+
+		dataPoint.Embedding = vector
+		dataPoints[i] = dataPoint
+
+		fmt.Fprintf(cfg.Output, "Vector: Name(%s) len(%d) %v...%v\n", dataPoint.Name, len(vector), vector[0:2], vector[len(vector)-2:])
+	}
+
+	fmt.Fprint(cfg.Output, "\n")
+
+	// -------------------------------------------------------------------------
+
+	// Compare each data point to every other by performing a cosine
+	// similarity comparison using the vector embedding from the LLM.
+	for _, target := range dataPoints {
+		results := vector.Similarity(target, dataPoints...)
+
+		for _, result := range results {
+			fmt.Fprintf(cfg.Output, "%s -> %s: %.2f%% similar\n",
+				result.Target.(data).Name,
+				result.DataPoint.(data).Name,
+				result.Percentage)
+		}
+		fmt.Fprint(cfg.Output, "\n")
+	}
+
+	// -------------------------------------------------------------------------
+
+	// Perform the same vector math as in example1 using the LLM vector embedding.
+
+	// You can perform vector math by adding and subtracting vectors.
+	kingSubMan := vector.Sub(dataPoints[3].Vector(), dataPoints[1].Vector())
+	kingSubManPlusWoman := vector.Add(kingSubMan, dataPoints[2].Vector())
+	queen := dataPoints[4].Vector()
+
+	// Now compare a (King - Man + Woman) to a Queen.
+	result := vector.CosineSimilarity(kingSubManPlusWoman, queen)
+	fmt.Fprintf(cfg.Output, "King - Man + Woman ~= Queen similarity: %.2f%%\n", result*100)
+
+	return nil
+}
@@ -0,0 +1,32 @@
+// This smoke test runs the example flow against the fake embedding
+// server, so `go test ./cmd/...` exercises it without a model.
+package example02
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/ai-training/foundation/fakeembed"
+)
+
+func TestSmoke(t *testing.T) {
+	srv := httptest.NewServer(fakeembed.Handler(64))
+	defer srv.Close()
+
+	var out strings.Builder
+
+	cfg := Config{
+		EmbedURL:   srv.URL,
+		EmbedModel: "fakeembed",
+		Output:     &out,
+	}
+
+	if err := Run(t.Context(), cfg); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "similar") {
+		t.Fatalf("missing the similarity results in output:\n%s", out.String())
+	}
+}
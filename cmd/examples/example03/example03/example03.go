@@ -0,0 +1,358 @@
+// Package example03 puts the example's insert and search flow behind an
+// exported Run function configured through Config, matching the layout
+// example06 established. Setting LocalPath runs the flow against a local
+// file store instead of MongoDB.
+package example03
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/mongodb"
+	"github.com/ardanlabs/ai-training/foundation/vector"
+	"github.com/ardanlabs/ai-training/foundation/vectorstore"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const dimensions = 768
+
+// Config carries everything Run needs. The Mongo settings are ignored
+// when LocalPath selects the local file store.
+type Config struct {
+	EmbedURL   string
+	EmbedModel string
+	MongoURI   string
+	MongoUser  string
+	MongoPass  string
+	DBName     string
+	ColName    string
+	LocalPath  string
+	Output     io.Writer
+}
+
+// =============================================================================
+
+type document struct {
+	ID        int       `bson:"id"`
+	Name      string    `bson:"name"`
+	Text      string    `bson:"text"`
+	Embedding []float64 `bson:"embedding"`
+}
+
+// Vector can convert the specified data into a vector.
+func (d document) Vector() []float64 {
+	return d.Embedding
+}
+
+type searchResult struct {
+	ID        int       `bson:"id"`
+	Name      string    `bson:"name"`
+	Text      string    `bson:"text"`
+	Embedding []float64 `bson:"embedding"`
+	Score     float64   `bson:"score"`
+}
+
+// =============================================================================
+
+// Run stores the hand crafted documents with their embeddings and
+// performs a set of nearest neighbor searches over them.
+func Run(ctx context.Context, cfg Config) error {
+	if cfg.LocalPath != "" {
+		return runLocal(ctx, cfg)
+	}
+
+	return runMongo(ctx, cfg)
+}
+
+// runLocal performs the same insert and search flow against a local file
+// store, for students who can't run the compose stack.
+func runLocal(ctx context.Context, cfg Config) error {
+	llm := client.NewLLM(cfg.EmbedURL, cfg.EmbedModel)
+
+	fmt.Fprintln(cfg.Output, "\nUsing the local file store")
+
+	store, err := vectorstore.NewLocal(cfg.LocalPath)
+	if err != nil {
+		return fmt.Errorf("vectorstore.NewLocal: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprintln(cfg.Output, "Inserting Documents")
+
+	documents := []document{
+		{ID: 1, Name: "Horse   ", Text: "Animal Female"},
+		{ID: 2, Name: "Man     ", Text: "Human  Male   Pants Poor Worker"},
+		{ID: 3, Name: "Woman   ", Text: "Human  Female Dress Poor Worker"},
+		{ID: 4, Name: "King    ", Text: "Human  Male   Pants Rich Ruler"},
+		{ID: 5, Name: "Queen   ", Text: "Human  Female Dress Rich Ruler"},
+	}
+
+	fmt.Fprint(cfg.Output, "\n")
+
+	for _, doc := range documents {
+		embedding, err := llm.EmbedText(ctx, doc.Text)
+		if err != nil {
+			return fmt.Errorf("embedding: %w", err)
+		}
+
+		d := vectorstore.Document{
+			ID:        strconv.Itoa(doc.ID),
+			Text:      doc.Text,
+			Embedding: embedding,
+			Metadata:  map[string]any{"name": doc.Name},
+		}
+
+		if err := store.Upsert(ctx, d); err != nil {
+			return fmt.Errorf("upsert: %w", err)
+		}
+
+		fmt.Fprintf(cfg.Output, "Vector: Name(%s) len(%d) %v...%v\n", doc.Name, len(embedding), embedding[0:2], embedding[len(embedding)-2:])
+	}
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprint(cfg.Output, "\n---- VECTOR SEARCH ----\n\n")
+
+	search := func(searchDocument string) {
+		fmt.Fprintf(cfg.Output, "Searching for: %q\n", searchDocument)
+
+		embedding, err := llm.EmbedText(ctx, searchDocument)
+		if err != nil {
+			fmt.Fprintf(cfg.Output, "error while searching: %v", fmt.Errorf("embedding: %w", err))
+			return
+		}
+
+		matches, err := store.Search(ctx, embedding, 10)
+		if err != nil {
+			fmt.Fprintf(cfg.Output, "error while searching: %v", fmt.Errorf("search: %w", err))
+			return
+		}
+
+		for _, match := range matches {
+			fmt.Fprintf(cfg.Output, "%s -> %s: %.2f%% similar\n",
+				match.Metadata["name"],
+				match.Text,
+				match.Score)
+		}
+
+		fmt.Fprintf(cfg.Output, "\n\n")
+	}
+
+	search("worker")
+	search("worker woman")
+	search("human worker woman")
+
+	fmt.Fprintf(cfg.Output, "\n\n")
+
+	return nil
+}
+
+func runMongo(ctx context.Context, cfg Config) error {
+
+	// Construct the llm client for access the model server.
+	llm := client.NewLLM(cfg.EmbedURL, cfg.EmbedModel)
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprintln(cfg.Output, "\nConnecting to MongoDB")
+
+	client, err := mongodb.Connect(ctx, cfg.MongoURI, cfg.MongoUser, cfg.MongoPass)
+	if err != nil {
+		return fmt.Errorf("mongodb.Connect: %w", err)
+	}
+	defer client.Disconnect(ctx)
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprintln(cfg.Output, "Initializing Database")
+
+	col, err := initDB(ctx, cfg, client)
+	if err != nil {
+		return fmt.Errorf("initDB: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	if err := insertDocuments(ctx, cfg, llm, col); err != nil {
+		return err
+	}
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprint(cfg.Output, "\n---- VECTOR SEARCH ----\n\n")
+
+	search := func(searchDocument string) {
+		fmt.Fprintf(cfg.Output, "Searching for: %q\n", searchDocument)
+
+		results, err := vectorSearch(ctx, col, llm, searchDocument, 10)
+		if err != nil {
+			fmt.Fprintf(cfg.Output, "error while searching: %v", fmt.Errorf("storeDocuments: %w", err))
+		}
+
+		for _, result := range results {
+			fmt.Fprintf(cfg.Output, "%s -> %s: %.2f%% similar\n",
+				result.Name,
+				result.Text,
+				result.Score)
+		}
+
+		fmt.Fprintf(cfg.Output, "\n\n")
+	}
+
+	search("worker")
+	search("worker woman")
+	search("human worker woman")
+
+	fmt.Fprintf(cfg.Output, "\n\n")
+
+	return nil
+}
+
+func initDB(ctx context.Context, cfg Config, client *mongo.Client) (*mongo.Collection, error) {
+	db := client.Database(cfg.DBName)
+
+	col, err := mongodb.CreateCollection(ctx, db, cfg.ColName)
+	if err != nil {
+		return nil, fmt.Errorf("createCollection: %w", err)
+	}
+
+	const indexName = "vector_index"
+
+	settings := mongodb.VectorIndexSettings{
+		NumDimensions: dimensions,
+		Path:          "embedding",
+		Similarity:    "cosine",
+	}
+
+	if err := mongodb.CreateVectorIndex(ctx, col, indexName, settings); err != nil {
+		return nil, fmt.Errorf("createVectorIndex: %w", err)
+	}
+
+	// Make sure the collection wasn't built with a different embedding model.
+	meta := mongodb.CollectionMeta{
+		Model:      cfg.EmbedModel,
+		Dimensions: dimensions,
+		Similarity: settings.Similarity,
+	}
+
+	if err := mongodb.CheckCollectionMeta(ctx, col, meta); err != nil {
+		return nil, fmt.Errorf("checkCollectionMeta: %w", err)
+	}
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	col.Indexes().CreateOne(ctx, indexModel)
+
+	// Delete any documents that might be there.
+	col.DeleteMany(ctx, bson.D{})
+
+	return col, nil
+}
+
+func insertDocuments(ctx context.Context, cfg Config, llm *client.LLM, col *mongo.Collection) error {
+	fmt.Fprintln(cfg.Output, "Inserting Documents")
+
+	// Apply the feature vectors to the hand crafted data points.
+	// This time you need to use words since we are using a word based model.
+	documents := []vector.Data{
+		document{ID: 1, Name: "Horse   ", Text: "Animal Female"},
+		document{ID: 2, Name: "Man     ", Text: "Human  Male   Pants Poor Worker"},
+		document{ID: 3, Name: "Woman   ", Text: "Human  Female Dress Poor Worker"},
+		document{ID: 4, Name: "King    ", Text: "Human  Male   Pants Rich Ruler"},
+		document{ID: 5, Name: "Queen   ", Text: "Human  Female Dress Rich Ruler"},
+	}
+
+	fmt.Fprint(cfg.Output, "\n")
+
+	var data []any
+
+	// Iterate over each data point and use the LLM to generate the vector
+	// embedding related to the model.
+	for i, dp := range documents {
+		dataPoint := dp.(document)
+
+		embedding, err := llm.EmbedText(ctx, dataPoint.Text)
+		if err != nil {
+			return fmt.Errorf("embedding: %w", err)
+		}
+
+		dataPoint.Embedding = embedding
+		documents[i] = dataPoint
+
+		data = append(data, dataPoint)
+
+		fmt.Fprintf(cfg.Output, "Vector: Name(%s) len(%d) %v...%v\n", dataPoint.Name, len(embedding), embedding[0:2], embedding[len(embedding)-2:])
+	}
+
+	res, err := col.InsertMany(ctx, data)
+	if err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+
+	fmt.Fprintln(cfg.Output, "\nInserted IDs:")
+
+	for _, insertedID := range res.InsertedIDs {
+		fmt.Fprintf(cfg.Output, "%v\n", insertedID)
+	}
+
+	// Wait until Mongo reports the vector index ready for queries.
+	if err := mongodb.WaitForIndexReady(ctx, col, "vector_index", 10*time.Second); err != nil {
+		return fmt.Errorf("waitForIndexReady: %w", err)
+	}
+
+	return nil
+}
+
+func vectorSearch(ctx context.Context, col *mongo.Collection, llm *client.LLM, searchDocument string, limit int) ([]searchResult, error) {
+	embedding, err := llm.EmbedText(ctx, searchDocument)
+	if err != nil {
+		return nil, fmt.Errorf("embedding: %w", err)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{
+			Key: "$vectorSearch",
+			Value: bson.M{
+				"index":       "vector_index",
+				"exact":       true,
+				"path":        "embedding",
+				"queryVector": embedding,
+				"limit":       limit,
+			}},
+		},
+		{{
+			Key: "$project",
+			Value: bson.M{
+				"id":        1,
+				"name":      1,
+				"text":      1,
+				"embedding": 1,
+				"score": bson.M{
+					"$meta": "vectorSearchScore",
+				},
+			}},
+		},
+	}
+
+	cur, err := col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var results []searchResult
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("all: %w", err)
+	}
+
+	return results, nil
+}
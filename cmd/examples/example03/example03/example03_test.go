@@ -0,0 +1,35 @@
+// This smoke test runs the local file store flow against the fake
+// embedding server, so `go test ./cmd/...` exercises it without a model
+// or MongoDB.
+package example03
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/ai-training/foundation/fakeembed"
+)
+
+func TestSmoke(t *testing.T) {
+	srv := httptest.NewServer(fakeembed.Handler(64))
+	defer srv.Close()
+
+	var out strings.Builder
+
+	cfg := Config{
+		EmbedURL:   srv.URL,
+		EmbedModel: "fakeembed",
+		LocalPath:  filepath.Join(t.TempDir(), "example03.json"),
+		Output:     &out,
+	}
+
+	if err := Run(t.Context(), cfg); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "VECTOR SEARCH") {
+		t.Fatalf("missing the search results in output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,64 @@
+// Package example04 keeps the example's logic in an exported Run
+// function configured through Config, the layout example06 established,
+// so it can be embedded and tested without the interactive wrapper.
+package example04
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+)
+
+// Config carries everything Run needs: the chat endpoint and model plus
+// the output destination.
+type Config struct {
+	ChatURL   string
+	ChatModel string
+	Output    io.Writer
+}
+
+// Run sends the question with the provided content to the model and
+// streams the response to cfg.Output.
+func Run(ctx context.Context, cfg Config, content string, question string) error {
+	const prompt = `
+		Use the following pieces of information to answer the user's question.
+		If you don't know the answer, say that you don't know.
+
+		Context: %s
+
+		Question: %s
+
+		Answer the question and provide additional helpful information, but be concise.
+
+		Responses should be properly formatted to be easily read.
+	`
+
+	fmt.Fprintf(cfg.Output, "\nContent:\n%s\n", content)
+	fmt.Fprintf(cfg.Output, "\nQuestion:\n\n%s\n", question)
+
+	finalPrompt := fmt.Sprintf(prompt, content, question)
+
+	// -------------------------------------------------------------------------
+
+	llm := client.NewLLM(cfg.ChatURL, cfg.ChatModel)
+
+	ch, err := llm.ChatCompletionsSSE(ctx, finalPrompt)
+	if err != nil {
+		return fmt.Errorf("chat completions: %w", err)
+	}
+
+	fmt.Fprint(cfg.Output, "\nModel Response:\n\n")
+
+	for resp := range ch {
+		// The final chunk carries only the usage block.
+		if len(resp.Choices) == 0 {
+			continue
+		}
+
+		fmt.Fprint(cfg.Output, resp.Choices[0].Delta.Content)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+// This smoke test streams the example's prompt through the fake chat
+// server, so `go test ./cmd/...` exercises it without a model.
+package example04
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/ai-training/foundation/fakechat"
+)
+
+func TestSmoke(t *testing.T) {
+	const reply = "the apple is red"
+
+	srv := httptest.NewServer(fakechat.Handler(reply))
+	defer srv.Close()
+
+	var out strings.Builder
+
+	cfg := Config{
+		ChatURL:   srv.URL,
+		ChatModel: "fakechat",
+		Output:    &out,
+	}
+
+	if err := Run(t.Context(), cfg, "the apple is red", "what color is the apple?"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if !strings.Contains(out.String(), reply) {
+		t.Fatalf("missing the streamed reply in output:\n%s", out.String())
+	}
+}
@@ -13,12 +13,11 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"os"
 	"time"
 
-	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/cmd/examples/example04/example04"
 )
 
 var (
@@ -36,54 +35,21 @@ func init() {
 	}
 }
 
-// =============================================================================
-
 func main() {
-	if err := run(); err != nil {
-		log.Fatal(err)
-	}
-}
-
-func run() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	const prompt = `
-		Use the following pieces of information to answer the user's question.
-		If you don't know the answer, say that you don't know.
-		
-		Context: %s
-		
-		Question: %s
-
-		Answer the question and provide additional helpful information, but be concise.
-
-		Responses should be properly formatted to be easily read.
-	`
-
-	question := `Is there value in the book and why?`
-
-	fmt.Printf("\nContent:\n%s\n", fakeContent)
-	fmt.Printf("\nQuestion:\n\n%s\n", question)
-
-	finalPrompt := fmt.Sprintf(prompt, fakeContent, question)
-
-	// -------------------------------------------------------------------------
-
-	llm := client.NewLLM(url, model)
-
-	ch, err := llm.ChatCompletionsSSE(ctx, finalPrompt)
-	if err != nil {
-		return fmt.Errorf("chat completions: %w", err)
+	cfg := example04.Config{
+		ChatURL:   url,
+		ChatModel: model,
+		Output:    os.Stdout,
 	}
 
-	fmt.Print("\nModel Response:\n\n")
+	question := `Is there value in the book and why?`
 
-	for resp := range ch {
-		fmt.Print(resp.Choices[0].Delta.Content)
+	if err := example04.Run(ctx, cfg, fakeContent, question); err != nil {
+		log.Fatal(err)
 	}
-
-	return nil
 }
 
 const fakeContent = `
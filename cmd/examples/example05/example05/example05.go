@@ -0,0 +1,476 @@
+// Package example05 drives the book embedding pipeline through an
+// exported Run function configured by Config, the layout example06
+// established, so the ingest flow can be run programmatically with any
+// chunks file and model server.
+package example05
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/mongodb"
+	"github.com/ardanlabs/ai-training/foundation/tiktoken"
+	"github.com/ardanlabs/ai-training/foundation/ui"
+	"github.com/ardanlabs/ai-training/foundation/vectorstore"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"golang.org/x/sync/errgroup"
+)
+
+const dimensions = 768
+
+// Config carries everything Run needs. Setting LocalPath loads the
+// embeddings into a local file store there instead of MongoDB. The
+// Workers setting bounds the concurrent embedding calls per batch.
+type Config struct {
+	EmbedURL       string
+	EmbedModel     string
+	MongoURI       string
+	MongoUser      string
+	MongoPass      string
+	DBName         string
+	ColName        string
+	ChunksPath     string
+	EmbeddingsPath string
+	LocalPath      string
+	Workers        int
+	Output         io.Writer
+}
+
+// =============================================================================
+
+// chunk represents a single chunk from the structured chunks file produced
+// by the cleaner. Each line in the file is one JSON document.
+type chunk struct {
+	Text     string `json:"text"`
+	Chapter  string `json:"chapter"`
+	Section  string `json:"section"`
+	Page     int    `json:"page"`
+	Language string `json:"language"`
+	Tokens   int    `json:"tokens"`
+}
+
+func readChunks(fileName string) ([]chunk, error) {
+	input, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer input.Close()
+
+	var chunks []chunk
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+
+	for scanner.Scan() {
+		var chk chunk
+		if err := json.Unmarshal(scanner.Bytes(), &chk); err != nil {
+			return nil, fmt.Errorf("unmarshal: %w", err)
+		}
+
+		chunks = append(chunks, chk)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner: %w", err)
+	}
+
+	return chunks, nil
+}
+
+type document struct {
+	ID        int       `bson:"id"`
+	Text      string    `bson:"text"`
+	Chapter   string    `bson:"chapter"`
+	Section   string    `bson:"section"`
+	Page      int       `bson:"page"`
+	Embedding []float64 `bson:"embedding"`
+}
+
+// =============================================================================
+
+// Run embeds the chunks file and loads the embeddings into either
+// MongoDB or the local file store at cfg.LocalPath.
+func Run(ctx context.Context, cfg Config) error {
+	fmt.Fprintln(cfg.Output, "\nCreating Embeddings")
+
+	if err := createBookEmbeddings(ctx, cfg); err != nil {
+		return fmt.Errorf("createBookEmbeddings: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	if cfg.LocalPath != "" {
+		if err := insertBookEmbeddingsLocal(ctx, cfg); err != nil {
+			return fmt.Errorf("insertBookEmbeddingsLocal: %w", err)
+		}
+
+		fmt.Fprintln(cfg.Output, "\nYou can now use example06 with -local to ask questions about this content.")
+
+		return nil
+	}
+
+	fmt.Fprintln(cfg.Output, "Initializing Database")
+
+	client, err := mongodb.Connect(ctx, cfg.MongoURI, cfg.MongoUser, cfg.MongoPass)
+	if err != nil {
+		return fmt.Errorf("mongodb.Connect: %w", err)
+	}
+
+	col, err := initDB(ctx, cfg, client)
+	if err != nil {
+		return fmt.Errorf("initDB: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	if err := insertBookEmbeddings(ctx, cfg, col); err != nil {
+		return fmt.Errorf("insertBookEmbeddings: %w", err)
+	}
+
+	fmt.Fprintln(cfg.Output, "\nYou can now use example06 to ask questions about this content.")
+
+	return nil
+}
+
+func createBookEmbeddings(ctx context.Context, cfg Config) error {
+	llm := client.NewLLM(cfg.EmbedURL, cfg.EmbedModel)
+
+	chunks, err := readChunks(cfg.ChunksPath)
+	if err != nil {
+		return fmt.Errorf("read chunks: %w", err)
+	}
+
+	// YOU WILL WANT TO KNOW HOW MANY TOKENS ARE CURRENTLY IN THE CHUNK
+	// SO YOU DON'T EXCEED THE NUMBER OF TOKENS THE MODEL WILL USE TO
+	// CREATE THE VECTOR EMBEDDING. THE MODEL WILL TRUNCATE YOUR CHUNK IF IT
+	// EXCEEDS THE NUMBER OF TOKENS IT CAN USE TO CREATE THE VECTOR
+	// EMBEDDING. THERE ARE MODELS THAT ONLY VECTORIZE AS LITTLE AS 512
+	// TOKENS. THE CHUNKS FILE CARRIES A TOKEN COUNT FOR EACH CHUNK TO
+	// HELP YOU WITH THIS.
+	tke, err := tiktoken.NewTiktoken()
+	if err != nil {
+		return fmt.Errorf("new tiktoken: %w", err)
+	}
+
+	chunks = splitOversized(tke, chunks)
+
+	// If a previous run was interrupted, pick up where it left off. The
+	// documents already in the file are verified and anything after the last
+	// complete document is discarded.
+	offset, err := validateEmbeddings(cfg.EmbeddingsPath)
+	if err != nil {
+		return fmt.Errorf("validate embeddings: %w", err)
+	}
+
+	if offset >= len(chunks) {
+		return nil
+	}
+
+	output, err := os.OpenFile(cfg.EmbeddingsPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer output.Close()
+
+	p := ui.NewProgress(cfg.Output, "Vectorizing Data", len(chunks))
+
+	// Process the chunks in small batches. The workers inside a batch run
+	// concurrently and the batch is written in order once it's complete. That
+	// keeps the documents in the file ordered and the file resumable.
+	for batchStart := offset; batchStart < len(chunks); batchStart += cfg.Workers {
+		batchEnd := min(batchStart+cfg.Workers, len(chunks))
+
+		p.Update(batchStart)
+
+		vectors := make([][]float64, batchEnd-batchStart)
+
+		g, gCtx := errgroup.WithContext(ctx)
+
+		for i := batchStart; i < batchEnd; i++ {
+			g.Go(func() error {
+				vector, err := embedWithRetry(gCtx, llm, chunks[i].Text)
+				if err != nil {
+					return fmt.Errorf("embedding: %w", err)
+				}
+
+				vectors[i-batchStart] = vector
+
+				return nil
+			})
+		}
+
+		if err := g.Wait(); err != nil {
+			return err
+		}
+
+		for i := batchStart; i < batchEnd; i++ {
+			doc := document{
+				ID:        i,
+				Text:      chunks[i].Text,
+				Chapter:   chunks[i].Chapter,
+				Section:   chunks[i].Section,
+				Page:      chunks[i].Page,
+				Embedding: vectors[i-batchStart],
+			}
+
+			data, err := json.Marshal(doc)
+			if err != nil {
+				return fmt.Errorf("marshal: %w", err)
+			}
+
+			// Write the json document to the embeddings file.
+			if _, err := output.Write(data); err != nil {
+				return fmt.Errorf("write: %w", err)
+			}
+
+			// Write a crlf for easier read access.
+			if _, err := output.Write([]byte{'\n'}); err != nil {
+				return fmt.Errorf("write crlf: %w", err)
+			}
+		}
+	}
+
+	p.Done()
+
+	return nil
+}
+
+// splitOversized expands any chunk over the embedding token budget into
+// token bounded pieces. The cleaner keeps code listings whole no matter
+// their size and the model truncates anything over its limit, so splitting
+// here is what keeps the end of those listings searchable.
+func splitOversized(tke *tiktoken.Tiktoken, chunks []chunk) []chunk {
+	const maxEmbedTokens = 2048
+	const overlapTokens = 50
+
+	var expanded []chunk
+
+	for _, chk := range chunks {
+		if chk.Tokens <= maxEmbedTokens {
+			expanded = append(expanded, chk)
+			continue
+		}
+
+		for _, text := range tke.Split(chk.Text, maxEmbedTokens, overlapTokens) {
+			piece := chk
+			piece.Text = text
+			piece.Tokens = tke.TokenCount(text)
+			expanded = append(expanded, piece)
+		}
+	}
+
+	return expanded
+}
+
+// embedWithRetry asks the model for an embedding and retries the call a few
+// times before giving up. Model servers under load will drop requests.
+func embedWithRetry(ctx context.Context, llm *client.LLM, text string) ([]float64, error) {
+	const attempts = 3
+
+	var lastErr error
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		vector, err := llm.EmbedText(ctx, text)
+		if err == nil {
+			return vector, nil
+		}
+
+		lastErr = err
+
+		select {
+		case <-time.After(time.Duration(attempt) * time.Second):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, fmt.Errorf("after %d attempts: %w", attempts, lastErr)
+}
+
+// validateEmbeddings verifies the documents already written to the embeddings
+// file and returns the next chunk id to process. If the last run died in the
+// middle of a write, everything after the last complete document is dropped
+// so we can append from a clean spot.
+func validateEmbeddings(fileName string) (int, error) {
+	input, err := os.OpenFile(fileName, os.O_RDWR, 0644)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("open file: %w", err)
+	}
+	defer input.Close()
+
+	var next int
+	var goodBytes int64
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var d document
+		if err := json.Unmarshal(line, &d); err != nil {
+			break
+		}
+
+		if d.ID != next {
+			break
+		}
+
+		next++
+		goodBytes += int64(len(line)) + 1
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("scanner: %w", err)
+	}
+
+	if err := input.Truncate(goodBytes); err != nil {
+		return 0, fmt.Errorf("truncate: %w", err)
+	}
+
+	return next, nil
+}
+
+func insertBookEmbeddings(ctx context.Context, cfg Config, col *mongo.Collection) error {
+	input, err := os.Open(cfg.EmbeddingsPath)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer input.Close()
+
+	var counter int
+	var docs []any
+
+	p := ui.NewProgress(cfg.Output, "Inserting Data", 0)
+
+	// Read one document at a time (each line) from the file.
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		counter++
+		p.Update(counter)
+
+		var d document
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			return fmt.Errorf("unmarshal: %w", err)
+		}
+
+		docs = append(docs, d)
+	}
+
+	// Upsert the documents in batches keyed on their id, so a re-run
+	// replaces what's there instead of checking each document first.
+	settings := mongodb.BulkUpsertSettings{
+		Keys:      []string{"id"},
+		BatchSize: 250,
+	}
+
+	if err := mongodb.BulkUpsert(ctx, col, docs, settings); err != nil {
+		return fmt.Errorf("mongodb.BulkUpsert: %w", err)
+	}
+
+	p.Done()
+
+	return nil
+}
+
+// insertBookEmbeddingsLocal loads the embeddings into a local file store
+// instead of MongoDB, for students who can't run the compose stack.
+func insertBookEmbeddingsLocal(ctx context.Context, cfg Config) error {
+	store, err := vectorstore.NewLocal(cfg.LocalPath)
+	if err != nil {
+		return fmt.Errorf("vectorstore.NewLocal: %w", err)
+	}
+
+	input, err := os.Open(cfg.EmbeddingsPath)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer input.Close()
+
+	var counter int
+	var docs []vectorstore.Document
+
+	p := ui.NewProgress(cfg.Output, "Inserting Data", 0)
+
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		counter++
+		p.Update(counter)
+
+		var d document
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			return fmt.Errorf("unmarshal: %w", err)
+		}
+
+		docs = append(docs, vectorstore.Document{
+			ID:        strconv.Itoa(d.ID),
+			Text:      d.Text,
+			Embedding: d.Embedding,
+			Metadata: map[string]any{
+				"chapter": d.Chapter,
+				"section": d.Section,
+				"page":    d.Page,
+			},
+		})
+	}
+
+	if err := store.Upsert(ctx, docs...); err != nil {
+		return fmt.Errorf("upsert: %w", err)
+	}
+
+	p.Done()
+
+	return nil
+}
+
+func initDB(ctx context.Context, cfg Config, client *mongo.Client) (*mongo.Collection, error) {
+	db := client.Database(cfg.DBName)
+
+	col, err := mongodb.CreateCollection(ctx, db, cfg.ColName)
+	if err != nil {
+		return nil, fmt.Errorf("createCollection: %w", err)
+	}
+
+	const indexName = "vector_index"
+
+	settings := mongodb.VectorIndexSettings{
+		NumDimensions: dimensions,
+		Path:          "embedding",
+		Similarity:    "cosine",
+	}
+
+	if err := mongodb.CreateVectorIndex(ctx, col, indexName, settings); err != nil {
+		return nil, fmt.Errorf("createVectorIndex: %w", err)
+	}
+
+	// Make sure the collection wasn't built with a different embedding model.
+	meta := mongodb.CollectionMeta{
+		Model:      cfg.EmbedModel,
+		Dimensions: dimensions,
+		Similarity: settings.Similarity,
+	}
+
+	if err := mongodb.CheckCollectionMeta(ctx, col, meta); err != nil {
+		return nil, fmt.Errorf("checkCollectionMeta: %w", err)
+	}
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	col.Indexes().CreateOne(ctx, indexModel)
+
+	return col, nil
+}
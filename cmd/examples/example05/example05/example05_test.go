@@ -0,0 +1,75 @@
+// This smoke test runs the ingest pipeline over a tiny chunks file with
+// the fake embedding server and the local file store, so
+// `go test ./cmd/...` exercises it without a model or MongoDB.
+package example05
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/ai-training/foundation/fakeembed"
+)
+
+func TestSmoke(t *testing.T) {
+	srv := httptest.NewServer(fakeembed.Handler(64))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	chunks := []string{
+		`{"text": "goroutines are lightweight threads", "chapter": "Concurrency", "section": "Goroutines", "page": 1, "language": "", "tokens": 5}`,
+		`{"text": "channels synchronize goroutines", "chapter": "Concurrency", "section": "Channels", "page": 2, "language": "", "tokens": 4}`,
+	}
+
+	chunksPath := filepath.Join(dir, "book.chunks")
+	if err := os.WriteFile(chunksPath, []byte(strings.Join(chunks, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("write chunks: %v", err)
+	}
+
+	var out strings.Builder
+
+	cfg := Config{
+		EmbedURL:       srv.URL,
+		EmbedModel:     "fakeembed",
+		ChunksPath:     chunksPath,
+		EmbeddingsPath: filepath.Join(dir, "book.embeddings"),
+		LocalPath:      filepath.Join(dir, "book.vectors.json"),
+		Workers:        2,
+		Output:         &out,
+	}
+
+	if err := Run(t.Context(), cfg); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	// Every chunk should have made it into the embeddings file.
+	data, err := os.ReadFile(cfg.EmbeddingsPath)
+	if err != nil {
+		t.Fatalf("read embeddings: %v", err)
+	}
+
+	if lines := strings.Count(string(data), "\n"); lines != len(chunks) {
+		t.Fatalf("expected %d embedding documents, got %d", len(chunks), lines)
+	}
+
+	if _, err := os.Stat(cfg.LocalPath); err != nil {
+		t.Fatalf("local store was not written: %v", err)
+	}
+
+	// A second run should resume cleanly and not duplicate documents.
+	if err := Run(t.Context(), cfg); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+
+	data, err = os.ReadFile(cfg.EmbeddingsPath)
+	if err != nil {
+		t.Fatalf("read embeddings: %v", err)
+	}
+
+	if lines := strings.Count(string(data), "\n"); lines != len(chunks) {
+		t.Fatalf("expected %d embedding documents after re-run, got %d", len(chunks), lines)
+	}
+}
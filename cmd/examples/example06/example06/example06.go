@@ -0,0 +1,395 @@
+// Package example06 exposes the example's logic behind an exported Run
+// function so the eval and smoke tooling can drive it programmatically.
+// The thin main package handles stdin and wiring; everything configurable
+// lives in Config instead of package-level globals. New examples should
+// follow this layout.
+package example06
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/mongodb"
+	"github.com/ardanlabs/ai-training/foundation/rerank"
+	"github.com/ardanlabs/ai-training/foundation/tiktoken"
+	"github.com/ardanlabs/ai-training/foundation/vector"
+	"github.com/ardanlabs/ai-training/foundation/vectorstore"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Config carries everything Run needs. Populate it from the config
+// package for the interactive example or directly from a test. Setting
+// LocalPath searches a local file store instead of MongoDB. Setting
+// Hybrid fuses a keyword text search into the vector search. Setting
+// Rerank reorders the search results with a second relevance pass,
+// using RerankURL/RerankModel when set or else a chat model judge.
+type Config struct {
+	ChatURL     string
+	EmbedURL    string
+	ChatModel   string
+	EmbedModel  string
+	MongoURI    string
+	MongoUser   string
+	MongoPass   string
+	DBName      string
+	ColName     string
+	LocalPath   string
+	Hybrid      bool
+	Rerank      bool
+	RerankURL   string
+	RerankModel string
+	Output      io.Writer
+}
+
+// Run answers the question with a vector search over the book collection
+// followed by a model response streamed to cfg.Output.
+func Run(ctx context.Context, cfg Config, question string) error {
+	results, err := vectorSearch(ctx, cfg, question)
+	if err != nil {
+		return fmt.Errorf("vectorSearch: %w", err)
+	}
+
+	if cfg.Rerank {
+		results, err = rerankResults(ctx, cfg, question, results)
+		if err != nil {
+			return fmt.Errorf("rerankResults: %w", err)
+		}
+	}
+
+	if err := questionResponse(ctx, cfg, question, results); err != nil {
+		return fmt.Errorf("questionResponse: %w", err)
+	}
+
+	return nil
+}
+
+func vectorSearch(ctx context.Context, cfg Config, question string) ([]searchResult, error) {
+	llm := client.NewLLM(cfg.EmbedURL, cfg.EmbedModel)
+
+	vector, err := llm.EmbedText(ctx, question)
+	if err != nil {
+		return nil, fmt.Errorf("do: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	// Pull more candidates than we will use so the MMR pass below has
+	// something to choose from.
+	const limitResults = 2
+	const candidateResults = 10
+
+	var results []searchResult
+
+	switch {
+	case cfg.LocalPath != "":
+		results, err = localSearch(ctx, cfg.LocalPath, vector, candidateResults)
+		if err != nil {
+			return nil, fmt.Errorf("localSearch: %w", err)
+		}
+
+	case cfg.Hybrid:
+		client, err := mongodb.Connect(ctx, cfg.MongoURI, cfg.MongoUser, cfg.MongoPass)
+		if err != nil {
+			return nil, fmt.Errorf("mongodb.Connect: %w", err)
+		}
+
+		col := client.Database(cfg.DBName).Collection(cfg.ColName)
+
+		results, err = hybridDBSearch(ctx, col, vector, question, candidateResults)
+		if err != nil {
+			return nil, fmt.Errorf("hybridDBSearch: %w", err)
+		}
+
+	default:
+		client, err := mongodb.Connect(ctx, cfg.MongoURI, cfg.MongoUser, cfg.MongoPass)
+		if err != nil {
+			return nil, fmt.Errorf("mongodb.Connect: %w", err)
+		}
+
+		col := client.Database(cfg.DBName).Collection(cfg.ColName)
+
+		results, err = vectorDBSearch(ctx, col, vector, candidateResults)
+		if err != nil {
+			return nil, fmt.Errorf("vectorDBSearch: %w", err)
+		}
+	}
+
+	return mmrSelect(vector, results, limitResults), nil
+}
+
+// mmrSelect keeps the most relevant results while dropping near duplicate
+// chunks, so the context isn't the same paragraph said twice. The hybrid
+// fused scores are replaced by relevance here, which is what the gate in
+// questionResponse expects anyway.
+func mmrSelect(queryVec []float64, results []searchResult, k int) []searchResult {
+	candidates := make([][]float64, len(results))
+	for i, res := range results {
+		candidates[i] = res.Embedding
+	}
+
+	const lambda = 0.7
+
+	picked := vector.MMR(queryVec, candidates, k, lambda)
+
+	selected := make([]searchResult, len(picked))
+	for i, n := range picked {
+		selected[i] = results[n.Index]
+		selected[i].Score = n.Score
+	}
+
+	return selected
+}
+
+// rerankResults reorders the search results with a second relevance pass.
+// A cross-encoder behind RerankURL reads the question together with each
+// chunk; without one a chat model judges each pairing. The rerank score
+// replaces the similarity score so the relevance gate reflects it.
+func rerankResults(ctx context.Context, cfg Config, question string, results []searchResult) ([]searchResult, error) {
+	var rr rerank.Reranker
+
+	switch {
+	case cfg.RerankURL != "":
+		rr = rerank.NewEndpoint(cfg.RerankURL, cfg.RerankModel)
+	default:
+		rr = rerank.NewLLMJudge(client.NewLLM(cfg.ChatURL, cfg.ChatModel))
+	}
+
+	documents := make([]string, len(results))
+	for i, res := range results {
+		documents[i] = res.Text
+	}
+
+	ranked, err := rr.Rerank(ctx, question, documents)
+	if err != nil {
+		return nil, fmt.Errorf("rerank: %w", err)
+	}
+
+	reordered := make([]searchResult, len(ranked))
+
+	for i, rank := range ranked {
+		reordered[i] = results[rank.Index]
+		reordered[i].Score = rank.Score
+	}
+
+	return reordered, nil
+}
+
+func questionResponse(ctx context.Context, cfg Config, question string, results []searchResult) error {
+	const prompt = `Use only the CONTEXT to answer the user's question.
+
+	If the CONTEXT doesn't provide enough context, say that you don't know.
+
+	Answer the question and provide additional helpful information.
+
+	Responses should be properly formatted to be easily read.
+
+	CONTEXT:
+	%s
+
+	QUESTION:
+	%s
+`
+
+	var chunks strings.Builder
+
+	for _, res := range results {
+		if res.Score >= .70 {
+			chunks.WriteString(res.Text)
+			chunks.WriteString(".\n")
+		}
+	}
+
+	content := chunks.String()
+	if content == "" {
+		fmt.Fprintln(cfg.Output, "Don't have enough information to provide an answer")
+		return nil
+	}
+
+	// YOU WILL WANT TO KNOW HOW MANY TOKENS ARE CURRENTLY IN THE CONTEXT
+	// SO YOU DON'T EXCEED THE CONTEXT WINDOW (MAXIMUM TOKENS ALLOWED BY
+	// THE MODEL). OUR CURRENT MODEL SUPPORTS 8192 TOKENS. CUT THE CONTEXT
+	// TO A BUDGET BELOW THAT SO THE PROMPT AND QUESTION STILL FIT.
+	const maxContextTokens = 7000
+
+	tke, err := tiktoken.NewTiktoken()
+	if err != nil {
+		return fmt.Errorf("new tiktoken: %w", err)
+	}
+
+	content = tke.Truncate(content, maxContextTokens)
+
+	finalPrompt := fmt.Sprintf(prompt, content, question)
+
+	// -------------------------------------------------------------------------
+
+	llm := client.NewLLM(cfg.ChatURL, cfg.ChatModel)
+
+	ch, err := llm.ChatCompletionsSSE(ctx, finalPrompt)
+	if err != nil {
+		return fmt.Errorf("do: %w", err)
+	}
+
+	fmt.Fprint(cfg.Output, "Model Response:\n\n")
+
+	for resp := range ch {
+		// The final chunk carries only the usage block.
+		if len(resp.Choices) == 0 {
+			continue
+		}
+
+		fmt.Fprint(cfg.Output, resp.Choices[0].Delta.Content)
+	}
+
+	// -------------------------------------------------------------------------
+
+	// Show the reader where in the book the context came from.
+
+	fmt.Fprint(cfg.Output, "\n\nCitations:\n")
+
+	for _, res := range results {
+		if res.Score >= .70 {
+			fmt.Fprintf(cfg.Output, "- %s, %s (page %d)\n", res.Chapter, res.Section, res.Page)
+		}
+	}
+
+	return nil
+}
+
+// localSearch performs the vector search against the local file store
+// that example05 -local populated, so no containers are needed.
+func localSearch(ctx context.Context, path string, vector []float64, limit int) ([]searchResult, error) {
+	store, err := vectorstore.NewLocal(path)
+	if err != nil {
+		return nil, fmt.Errorf("vectorstore.NewLocal: %w", err)
+	}
+
+	matches, err := store.Search(ctx, vector, limit)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	results := make([]searchResult, len(matches))
+	for i, match := range matches {
+		chapter, _ := match.Metadata["chapter"].(string)
+		section, _ := match.Metadata["section"].(string)
+
+		// JSON numbers come back as float64.
+		page, _ := match.Metadata["page"].(float64)
+
+		results[i] = searchResult{
+			Text:      match.Text,
+			Chapter:   chapter,
+			Section:   section,
+			Page:      int(page),
+			Embedding: match.Embedding,
+			Score:     match.Score,
+		}
+	}
+
+	return results, nil
+}
+
+// =============================================================================
+
+type searchResult struct {
+	ID        int       `bson:"id"`
+	Text      string    `bson:"text"`
+	Chapter   string    `bson:"chapter"`
+	Section   string    `bson:"section"`
+	Page      int       `bson:"page"`
+	Embedding []float64 `bson:"embedding"`
+	Score     float64   `bson:"score"`
+}
+
+func vectorDBSearch(ctx context.Context, col *mongo.Collection, vector []float64, limit int) ([]searchResult, error) {
+	pipeline := mongo.Pipeline{
+		{{
+			Key: "$vectorSearch",
+			Value: bson.M{
+				"index":       "vector_index",
+				"exact":       true,
+				"path":        "embedding",
+				"queryVector": vector,
+				"limit":       limit,
+			}},
+		},
+		{{
+			Key: "$project",
+			Value: bson.M{
+				"id":        1,
+				"text":      1,
+				"chapter":   1,
+				"section":   1,
+				"page":      1,
+				"embedding": 1,
+				"score": bson.M{
+					"$meta": "vectorSearchScore",
+				},
+			}},
+		},
+	}
+
+	cur, err := col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var results []searchResult
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("all: %w", err)
+	}
+
+	return results, nil
+}
+
+// hybridDBSearch fuses a keyword text search into the vector search with
+// reciprocal rank fusion so exact terms the embedding misses still surface.
+// Try a question with a rare identifier to compare recall against the pure
+// vector search.
+func hybridDBSearch(ctx context.Context, col *mongo.Collection, vector []float64, question string, limit int) ([]searchResult, error) {
+	if err := mongodb.CreateTextIndex(ctx, col, "text"); err != nil {
+		return nil, fmt.Errorf("mongodb.CreateTextIndex: %w", err)
+	}
+
+	settings := mongodb.HybridSearchSettings{
+		IndexName:  "vector_index",
+		VectorPath: "embedding",
+		TextPath:   "text",
+		Limit:      limit,
+	}
+
+	docs, err := mongodb.HybridSearch(ctx, col, vector, question, settings)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb.HybridSearch: %w", err)
+	}
+
+	results := make([]searchResult, len(docs))
+
+	for i, doc := range docs {
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("marshal: %w", err)
+		}
+
+		if err := bson.Unmarshal(raw, &results[i]); err != nil {
+			return nil, fmt.Errorf("unmarshal: %w", err)
+		}
+	}
+
+	// Fused scores are rank based and live well below the similarity scale,
+	// so normalize against the top result to keep the relevance gate and
+	// citations working.
+	if len(results) > 0 && results[0].Score > 0 {
+		top := results[0].Score
+		for i := range results {
+			results[i].Score /= top
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,77 @@
+// This smoke test answers a question over a seeded local file store
+// using the fake embedding and chat servers, so `go test ./cmd/...`
+// exercises the full RAG flow without models or MongoDB.
+package example06
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/ai-training/foundation/fakechat"
+	"github.com/ardanlabs/ai-training/foundation/fakeembed"
+	"github.com/ardanlabs/ai-training/foundation/vectorstore"
+)
+
+func TestSmoke(t *testing.T) {
+	embedSrv := httptest.NewServer(fakeembed.Handler(64))
+	defer embedSrv.Close()
+
+	const reply = "Channels synchronize goroutines."
+
+	chatSrv := httptest.NewServer(fakechat.Handler(reply))
+	defer chatSrv.Close()
+
+	// Seed the local store the way example05 -local would have. The
+	// question below matches the first chunk's text exactly, so the fake
+	// embeddings score it past the relevance gate.
+	localPath := filepath.Join(t.TempDir(), "book.vectors.json")
+
+	store, err := vectorstore.NewLocal(localPath)
+	if err != nil {
+		t.Fatalf("new local store: %v", err)
+	}
+
+	docs := []vectorstore.Document{
+		{
+			ID:        "0",
+			Text:      "channels synchronize goroutines",
+			Embedding: fakeembed.Vector("channels synchronize goroutines", 64),
+			Metadata:  map[string]any{"chapter": "Concurrency", "section": "Channels", "page": float64(1)},
+		},
+		{
+			ID:        "1",
+			Text:      "maps are not safe for concurrent use",
+			Embedding: fakeembed.Vector("maps are not safe for concurrent use", 64),
+			Metadata:  map[string]any{"chapter": "Data Types", "section": "Maps", "page": float64(2)},
+		},
+	}
+
+	if err := store.Upsert(t.Context(), docs...); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+
+	var out strings.Builder
+
+	cfg := Config{
+		ChatURL:    chatSrv.URL,
+		EmbedURL:   embedSrv.URL,
+		ChatModel:  "fakechat",
+		EmbedModel: "fakeembed",
+		LocalPath:  localPath,
+		Output:     &out,
+	}
+
+	if err := Run(t.Context(), cfg, "channels synchronize goroutines"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if !strings.Contains(out.String(), reply) {
+		t.Fatalf("missing the model response in output:\n%s", out.String())
+	}
+
+	if !strings.Contains(out.String(), "Concurrency, Channels (page 1)") {
+		t.Fatalf("missing the citation in output:\n%s", out.String())
+	}
+}
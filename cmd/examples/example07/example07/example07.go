@@ -0,0 +1,102 @@
+// Package example07 exposes the SQL generation flow behind an exported
+// Run function configured through Config, the layout example06
+// established. The prompts and schema ride along as embedded files.
+package example07
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/sqldb"
+	"github.com/ardanlabs/ai-training/foundation/tiktoken"
+)
+
+// Config carries everything Run needs: the chat endpoint and model plus
+// the output destination. The database settings live in dbConnection
+// and match the compose stack.
+type Config struct {
+	ChatURL   string
+	ChatModel string
+	Output    io.Writer
+}
+
+// Run asks the model to generate a SQL query for the question, executes
+// it against the garage sale schema, and has the model answer from the
+// results.
+func Run(ctx context.Context, cfg Config, question string) error {
+	db, err := initSQLDB(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("initSQLDB: %w", err)
+	}
+	defer db.Close()
+
+	// -------------------------------------------------------------------------
+
+	llm := client.NewLLM(cfg.ChatURL, cfg.ChatModel)
+
+	query, err := llm.ChatCompletions(ctx, fmt.Sprintf(query, question))
+	if err != nil {
+		return fmt.Errorf("chat completions: %w", err)
+	}
+
+	fmt.Fprintln(cfg.Output, "QUERY:")
+	fmt.Fprint(cfg.Output, "-----------------------------------------------\n\n")
+	fmt.Fprintln(cfg.Output, query)
+	fmt.Fprint(cfg.Output, "\n")
+
+	// -------------------------------------------------------------------------
+
+	data := []map[string]any{}
+	if err := sqldb.QueryMap(ctx, db, query, &data); err != nil {
+		return fmt.Errorf("execQuery: %w", err)
+	}
+
+	fmt.Fprintln(cfg.Output, "DATA:")
+	fmt.Fprint(cfg.Output, "-----------------------------------------------\n\n")
+
+	for i, m := range data {
+		fmt.Fprintf(cfg.Output, "RESULT: %d\n", i+1)
+		for k, v := range m {
+			fmt.Fprintf(cfg.Output, "KEY: %s, VAL: %v\n", k, v)
+		}
+		fmt.Fprint(cfg.Output, "\n")
+	}
+
+	// -------------------------------------------------------------------------
+
+	var builder strings.Builder
+	for i, m := range data {
+		builder.WriteString(fmt.Sprintf("RESULT: %d\n", i+1))
+		for k, v := range m {
+			builder.WriteString(fmt.Sprintf("KEY: %s, VAL: %v\n", k, v))
+		}
+		builder.WriteString("\n")
+	}
+
+	// A broad query can return more rows than the model's context window
+	// can hold. Cut the results to a token budget instead of letting the
+	// model silently drop the end of the prompt.
+	const maxDataTokens = 7000
+
+	tke, err := tiktoken.NewTiktoken()
+	if err != nil {
+		return fmt.Errorf("new tiktoken: %w", err)
+	}
+
+	results := tke.Truncate(builder.String(), maxDataTokens)
+
+	answer, err := llm.ChatCompletions(ctx, fmt.Sprintf(response, results, question))
+	if err != nil {
+		return fmt.Errorf("do: %w", err)
+	}
+
+	fmt.Fprintln(cfg.Output, "ANSWER:")
+	fmt.Fprint(cfg.Output, "-----------------------------------------------\n\n")
+	fmt.Fprintln(cfg.Output, answer)
+	fmt.Fprint(cfg.Output, "\n")
+
+	return nil
+}
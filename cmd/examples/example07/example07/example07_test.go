@@ -5,10 +5,12 @@
 //
 //	$ make compose-up
 //	$ make kronk-up
-package main
+package example07
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"testing"
 
 	"github.com/ardanlabs/ai-training/foundation/client"
@@ -16,7 +18,23 @@ import (
 )
 
 func TestValidSQL(t *testing.T) {
-	db, err := initSQLDB(t.Context())
+	url := "http://localhost:11435/v1/chat/completions"
+	if v := os.Getenv("LLM_SERVER"); v != "" {
+		url = v
+	}
+
+	model := "cerebras_Qwen3-Coder-REAP-25B-A3B-Q8_0"
+	if v := os.Getenv("LLM_MODEL"); v != "" {
+		model = v
+	}
+
+	cfg := Config{
+		ChatURL:   url,
+		ChatModel: model,
+		Output:    io.Discard,
+	}
+
+	db, err := initSQLDB(t.Context(), cfg)
 	if err != nil {
 		t.Fatalf("initSQLDB: %v", err)
 	}
@@ -24,7 +42,7 @@ func TestValidSQL(t *testing.T) {
 
 	question := "Which user bought most products?"
 
-	llm := client.NewLLM(url, model)
+	llm := client.NewLLM(cfg.ChatURL, cfg.ChatModel)
 
 	sql, err := llm.ChatCompletions(t.Context(), fmt.Sprintf(query, question))
 	if err != nil {
@@ -1,4 +1,4 @@
-package main
+package example07
 
 import (
 	"context"
@@ -25,21 +25,21 @@ var (
 	insertSQL string
 )
 
-func initSQLDB(ctx context.Context) (*sqlx.DB, error) {
-	fmt.Println("\nConnecting to the DB")
+func initSQLDB(ctx context.Context, cfg Config) (*sqlx.DB, error) {
+	fmt.Fprintln(cfg.Output, "\nConnecting to the DB")
 
 	db, err := dbConnection()
 	if err != nil {
 		return nil, fmt.Errorf("dbConnection: %w", err)
 	}
 
-	fmt.Println("Creating Schema")
+	fmt.Fprintln(cfg.Output, "Creating Schema")
 
 	if err := dbExecute(ctx, db, schemaSQL); err != nil {
 		return nil, fmt.Errorf("dbExecute: %w", err)
 	}
 
-	fmt.Println("Inserting Data")
+	fmt.Fprintln(cfg.Output, "Inserting Data")
 
 	if err := dbExecute(ctx, db, insertSQL); err != nil {
 		return nil, fmt.Errorf("dbExecute: %w", err)
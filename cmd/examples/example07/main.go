@@ -17,11 +17,9 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"strings"
 	"time"
 
-	"github.com/ardanlabs/ai-training/foundation/client"
-	"github.com/ardanlabs/ai-training/foundation/sqldb"
+	"github.com/ardanlabs/ai-training/cmd/examples/example07/example07"
 )
 
 var (
@@ -39,88 +37,27 @@ func init() {
 	}
 }
 
-// =============================================================================
-
 func main() {
-	if err := run(); err != nil {
-		log.Fatal(err)
-	}
-}
-
-func run() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*60*time.Second)
 	defer cancel()
 
-	db, err := initSQLDB(ctx)
-	if err != nil {
-		return fmt.Errorf("initSQLDB: %w", err)
-	}
-	defer db.Close()
-
-	// -------------------------------------------------------------------------
-
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Print("\nAsk a question about the garage sale system: ")
 
 	question, _ := reader.ReadString('\n')
 	if question == "" {
-		return nil
+		return
 	}
 
 	fmt.Print("\nGive me a second...\n\n")
 
-	// -------------------------------------------------------------------------
-
-	llm := client.NewLLM(url, model)
-
-	query, err := llm.ChatCompletions(ctx, fmt.Sprintf(query, question))
-	if err != nil {
-		return fmt.Errorf("chat completions: %w", err)
+	cfg := example07.Config{
+		ChatURL:   url,
+		ChatModel: model,
+		Output:    os.Stdout,
 	}
 
-	fmt.Println("QUERY:")
-	fmt.Print("-----------------------------------------------\n\n")
-	fmt.Println(query)
-	fmt.Print("\n")
-
-	// -------------------------------------------------------------------------
-
-	data := []map[string]any{}
-	if err := sqldb.QueryMap(ctx, db, query, &data); err != nil {
-		return fmt.Errorf("execQuery: %w", err)
-	}
-
-	fmt.Println("DATA:")
-	fmt.Print("-----------------------------------------------\n\n")
-
-	for i, m := range data {
-		fmt.Printf("RESULT: %d\n", i+1)
-		for k, v := range m {
-			fmt.Printf("KEY: %s, VAL: %v\n", k, v)
-		}
-		fmt.Print("\n")
-	}
-
-	// -------------------------------------------------------------------------
-
-	var builder strings.Builder
-	for i, m := range data {
-		builder.WriteString(fmt.Sprintf("RESULT: %d\n", i+1))
-		for k, v := range m {
-			builder.WriteString(fmt.Sprintf("KEY: %s, VAL: %v\n", k, v))
-		}
-		builder.WriteString("\n")
-	}
-
-	answer, err := llm.ChatCompletions(ctx, fmt.Sprintf(response, builder.String(), question))
-	if err != nil {
-		return fmt.Errorf("do: %w", err)
+	if err := example07.Run(ctx, cfg, question); err != nil {
+		log.Fatal(err)
 	}
-
-	fmt.Println("ANSWER:")
-	fmt.Print("-----------------------------------------------\n\n")
-	fmt.Println(answer)
-	fmt.Print("\n")
-
-	return nil
 }
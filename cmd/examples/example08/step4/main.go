@@ -170,9 +170,10 @@ func run() error {
 
 	fmt.Printf("%s\n", res.InsertedID)
 
-	// We need to give mongodb some time to index the document.
-	// There is no way to know when this gets done.
-	time.Sleep(time.Second)
+	// Wait until Mongo reports the vector index ready for queries.
+	if err := mongodb.WaitForIndexReady(ctx, col, "vector_embedding_index", 10*time.Second); err != nil {
+		return fmt.Errorf("waitForIndexReady: %w", err)
+	}
 
 	// -------------------------------------------------------------------------
 
@@ -314,6 +315,11 @@ func questionResponse(ctx context.Context, llm *client.LLM, question string, res
 	fmt.Println("\nModel Response:")
 
 	for resp := range ch {
+		// The final chunk carries only the usage block.
+		if len(resp.Choices) == 0 {
+			continue
+		}
+
 		fmt.Print(resp.Choices[0].Delta.Content)
 	}
 
@@ -349,6 +355,17 @@ func initDB(ctx context.Context, client *mongo.Client) (*mongo.Collection, error
 		return nil, fmt.Errorf("createVectorIndex (text): %w", err)
 	}
 
+	// Make sure the collection wasn't built with a different embedding model.
+	meta := mongodb.CollectionMeta{
+		Model:      modelEmbed,
+		Dimensions: dimensions,
+		Similarity: settings.Similarity,
+	}
+
+	if err := mongodb.CheckCollectionMeta(ctx, col, meta); err != nil {
+		return nil, fmt.Errorf("checkCollectionMeta: %w", err)
+	}
+
 	return col, nil
 }
 
@@ -217,9 +217,10 @@ func saveImagesInDB(ctx context.Context, llm *client.LLM, embedLLM *client.LLM,
 		fmt.Printf("  - Inserted db id: %s\n", res.InsertedID)
 	}
 
-	// We need to give mongodb some time to index the documents.
-	// There is no way to know when this gets done.
-	time.Sleep(time.Second)
+	// Wait until Mongo reports the vector index ready for queries.
+	if err := mongodb.WaitForIndexReady(ctx, col, "vector_embedding_index", 10*time.Second); err != nil {
+		return fmt.Errorf("waitForIndexReady: %w", err)
+	}
 
 	return nil
 }
@@ -348,6 +349,11 @@ func questionResponse(ctx context.Context, llm *client.LLM, question string, sco
 	fmt.Print("\nModel Response:\n\n")
 
 	for resp := range ch {
+		// The final chunk carries only the usage block.
+		if len(resp.Choices) == 0 {
+			continue
+		}
+
 		fmt.Print(resp.Choices[0].Delta.Content)
 	}
 
@@ -386,6 +392,17 @@ func initDB(ctx context.Context, client *mongo.Client) (*mongo.Collection, error
 		return nil, fmt.Errorf("createVectorIndex (text): %w", err)
 	}
 
+	// Make sure the collection wasn't built with a different embedding model.
+	meta := mongodb.CollectionMeta{
+		Model:      modelEmbed,
+		Dimensions: dimensions,
+		Similarity: settings.Similarity,
+	}
+
+	if err := mongodb.CheckCollectionMeta(ctx, col, meta); err != nil {
+		return nil, fmt.Errorf("checkCollectionMeta: %w", err)
+	}
+
 	return col, nil
 }
 
@@ -28,8 +28,11 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/ardanlabs/ai-training/foundation/shell"
 )
 
 func main() {
@@ -68,6 +71,10 @@ func run(host string, port string) error {
 		return err
 	}
 
+	if err := mcpClientCall(host, port, "tool_safe_shell_command", map[string]any{"command": []string{"find", ".", "-name", "*.go", "-not", "-path", "./vendor/*"}}); err != nil {
+		return err
+	}
+
 	fmt.Print("\nTest Successful\n\n")
 
 	// -------------------------------------------------------------------------
@@ -92,6 +99,7 @@ func mcpListenAndServe(host string, port string) {
 	mcp.AddTool(fileOperations, &mcp.Tool{Name: "tool_list_files", Description: "lists files"}, ListFilesMCPHandler)
 	mcp.AddTool(fileOperations, &mcp.Tool{Name: "tool_read_files", Description: "reads a file"}, ReadFilesMCPHandler)
 	mcp.AddTool(fileOperations, &mcp.Tool{Name: "tool_shell_command", Description: "runs a shell command"}, ShellCommandMCPHandler)
+	mcp.AddTool(fileOperations, &mcp.Tool{Name: "tool_safe_shell_command", Description: "runs an allowlisted shell command"}, SafeShellCommandMCPHandler)
 
 	// -------------------------------------------------------------------------
 
@@ -114,6 +122,9 @@ func mcpListenAndServe(host string, port string) {
 		case "/tool_shell_command":
 			return fileOperations
 
+		case "/tool_safe_shell_command":
+			return fileOperations
+
 		default:
 			return mcp.NewServer(&mcp.Implementation{Name: "unknown_tool", Version: "v1.0.0"}, nil)
 		}
@@ -223,6 +234,49 @@ func ShellCommandMCPHandler(ctx context.Context, req *mcp.CallToolRequest, param
 	}, nil, nil
 }
 
+// =============================================================================
+
+// safeRunner is the hardened counterpart to ShellCommandMCPHandler. Only
+// the binaries listed here may run, their arguments must match the
+// patterns, and execution is bounded by a timeout and an output cap.
+// The find rule only allows read-only expressions, so action options
+// like -delete and -exec are rejected.
+var safeRunner = shell.NewRunner(
+	shell.WithRule("find", `^-(name|iname|path|type|maxdepth|mindepth|not)$`, `^[\w./*]+$`),
+	shell.WithRule("ls", `^-[la]+$`, `^[\w./]+$`),
+	shell.WithTimeout(10*time.Second),
+	shell.WithMaxOutput(64*1024),
+)
+
+// SafeShellCommandMCPHandler runs a shell command through an allowlist
+// of binaries and argument patterns. This is how the shell tool above
+// should be implemented when you need one for real.
+func SafeShellCommandMCPHandler(ctx context.Context, req *mcp.CallToolRequest, params ShellCommandParams) (*mcp.CallToolResult, any, error) {
+	output, err := safeRunner.Run(ctx, params.Command)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := struct {
+		Command []string `json:"command"`
+		Output  string   `json:"output"`
+	}{
+		Command: params.Command,
+		Output:  output,
+	}
+
+	d, err := json.Marshal(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{
+			Text: string(d),
+		}},
+	}, nil, nil
+}
+
 // =============================================================================
 // Basic MCP client code
 
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// discoverTools connects to the MCP server, calls tools/list, and builds
+// the agent wiring from the returned schemas. Adding a tool on the server
+// side requires no agent changes.
+func discoverTools(ctx context.Context, mcpClient *mcpClient, tools map[string]Tool) ([]client.D, error) {
+	transport := newToolTransport("discovery")
+
+	session, err := mcpClient.client.Connect(ctx, transport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MCP server: %w", err)
+	}
+	defer session.Close()
+
+	list, err := session.ListTools(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+
+	var toolDocuments []client.D
+
+	for _, tool := range list.Tools {
+		parameters, err := schemaToDocument(tool.InputSchema)
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: %w", tool.Name, err)
+		}
+
+		toolDocuments = append(toolDocuments, client.D{
+			"type": "function",
+			"function": client.D{
+				"name":        tool.Name,
+				"description": tool.Description,
+				"parameters":  parameters,
+			},
+		})
+
+		tools[tool.Name] = &mcpTool{
+			name:      tool.Name,
+			mcpClient: mcpClient,
+			transport: newToolTransport(tool.Name),
+		}
+	}
+
+	return toolDocuments, nil
+}
+
+// schemaToDocument converts the JSON schema the server returned for a
+// tool into the document shape the model expects.
+func schemaToDocument(schema any) (client.D, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	var doc client.D
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+
+	return doc, nil
+}
+
+// =============================================================================
+
+// mcpTool routes a model tool call to the MCP server by name. Unlike the
+// hardcoded tools, it passes the server's response through untouched.
+type mcpTool struct {
+	name      string
+	mcpClient *mcpClient
+	transport mcp.Transport
+}
+
+// Call is the function that is called by the agent when the model
+// requests the tool with the specified parameters.
+func (mt *mcpTool) Call(ctx context.Context, tool client.ToolCall) (resp client.D) {
+	defer func() {
+		if r := recover(); r != nil {
+			resp = toolErrorResponse(tool.ID, fmt.Errorf("%s", r))
+		}
+	}()
+
+	params := mcp.CallToolParams{
+		Name:      mt.name,
+		Arguments: tool.Function.Arguments,
+	}
+
+	results, err := mt.mcpClient.Call(ctx, mt.transport, &params)
+	if err != nil {
+		return toolErrorResponse(tool.ID, fmt.Errorf("failed to call tool: %w", err))
+	}
+
+	data := results[0].(*mcp.TextContent).Text
+
+	var info map[string]any
+	if err := json.Unmarshal([]byte(data), &info); err != nil {
+		return toolSuccessResponse(tool.ID, "result", data)
+	}
+
+	return toolSuccessResponse(tool.ID, "result", info)
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
 
 	"github.com/ardanlabs/ai-training/foundation/client"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -24,7 +27,7 @@ func newMCPClient() *mcpClient {
 }
 
 // Call executes an MCP tool call using the provided transport and parameters.
-func (cln *mcpClient) Call(ctx context.Context, transport *mcp.SSEClientTransport, params *mcp.CallToolParams) ([]mcp.Content, error) {
+func (cln *mcpClient) Call(ctx context.Context, transport mcp.Transport, params *mcp.CallToolParams) ([]mcp.Content, error) {
 	fmt.Print("\u001b[92mtool: connecting to MCP Server\u001b[0m\n")
 
 	session, err := cln.client.Connect(ctx, transport, nil)
@@ -51,6 +54,99 @@ func (cln *mcpClient) Call(ctx context.Context, transport *mcp.SSEClientTranspor
 
 // =============================================================================
 
+// newToolTransport constructs the transport a tool uses to reach the MCP
+// server, based on the MCP_TRANSPORT setting.
+func newToolTransport(toolName string) mcp.Transport {
+	switch mcpTransport {
+	case "stdio":
+		return stdioTransport{}
+
+	case "http":
+		return streamableTransport{toolName: toolName}
+	}
+
+	addr := fmt.Sprintf("http://%s/%s", mcpHost, toolName)
+
+	return &mcp.SSEClientTransport{
+		Endpoint:   addr,
+		HTTPClient: authHTTPClient(),
+	}
+}
+
+// authHTTPClient constructs an http client that sends the configured API
+// key as a bearer token on every request. With no key configured it
+// returns nil so the transports use their defaults.
+func authHTTPClient() *http.Client {
+	if mcpAPIKey == "" {
+		return nil
+	}
+
+	return &http.Client{
+		Transport: authRoundTripper{next: http.DefaultTransport},
+	}
+}
+
+// authRoundTripper injects the API key on every request to the MCP server.
+type authRoundTripper struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+mcpAPIKey)
+
+	return t.next.RoundTrip(req)
+}
+
+// streamableTransport prefers the streamable HTTP transport the MCP spec
+// is moving to and falls back to the tool's SSE endpoint when the server
+// doesn't support it yet.
+type streamableTransport struct {
+	toolName string
+}
+
+// Connect implements the mcp.Transport interface.
+func (t streamableTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	streamable := mcp.StreamableClientTransport{
+		Endpoint:   fmt.Sprintf("http://%s/mcp", mcpHost),
+		HTTPClient: authHTTPClient(),
+	}
+
+	conn, err := streamable.Connect(ctx)
+	if err == nil {
+		return conn, nil
+	}
+
+	sse := mcp.SSEClientTransport{
+		Endpoint:   fmt.Sprintf("http://%s/%s", mcpHost, t.toolName),
+		HTTPClient: authHTTPClient(),
+	}
+
+	return sse.Connect(ctx)
+}
+
+// stdioTransport launches this binary as a child process in stdio mode
+// for every connection. A command can only be started once, so a fresh
+// exec.Cmd is required per connect.
+type stdioTransport struct{}
+
+// Connect implements the mcp.Transport interface.
+func (stdioTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("locating executable: %w", err)
+	}
+
+	transport := mcp.CommandTransport{
+		Command: exec.Command(exe, "-mcp-stdio"),
+	}
+
+	return transport.Connect(ctx)
+}
+
+// =============================================================================
+
 // toolSuccessResponse returns a successful structured tool response.
 func toolSuccessResponse(toolID string, keyValues ...any) client.D {
 	data := make(map[string]any)
@@ -101,7 +197,7 @@ func toolResponse(toolID string, data map[string]any, status string) client.D {
 type ReadFile struct {
 	name      string
 	mcpClient *mcpClient
-	transport *mcp.SSEClientTransport
+	transport mcp.Transport
 }
 
 // RegisterReadFile creates a new instance of the ReadFile tool and loads it
@@ -109,15 +205,12 @@ type ReadFile struct {
 func RegisterReadFile(mcpClient *mcpClient, tools map[string]Tool) client.D {
 	toolName := "tool_read_file"
 
-	addr := fmt.Sprintf("http://%s/%s", mcpHost, toolName)
-	transport := mcp.SSEClientTransport{
-		Endpoint: addr,
-	}
+	transport := newToolTransport(toolName)
 
 	rf := ReadFile{
 		name:      toolName,
 		mcpClient: mcpClient,
-		transport: &transport,
+		transport: transport,
 	}
 	tools[rf.name] = &rf
 
@@ -184,7 +277,7 @@ func (rf *ReadFile) Call(ctx context.Context, tool client.ToolCall) (resp client
 type SearchFiles struct {
 	name      string
 	mcpClient *mcpClient
-	transport *mcp.SSEClientTransport
+	transport mcp.Transport
 }
 
 // RegisterSearchFiles creates a new instance of the SearchFiles tool and loads it
@@ -192,15 +285,12 @@ type SearchFiles struct {
 func RegisterSearchFiles(mcpClient *mcpClient, tools map[string]Tool) client.D {
 	toolName := "tool_search_files"
 
-	addr := fmt.Sprintf("http://%s/%s", mcpHost, toolName)
-	transport := mcp.SSEClientTransport{
-		Endpoint: addr,
-	}
+	transport := newToolTransport(toolName)
 
 	sf := SearchFiles{
 		name:      toolName,
 		mcpClient: mcpClient,
-		transport: &transport,
+		transport: transport,
 	}
 	tools[sf.name] = &sf
 
@@ -275,7 +365,7 @@ func (sf *SearchFiles) Call(ctx context.Context, tool client.ToolCall) (resp cli
 type CreateFile struct {
 	name      string
 	mcpClient *mcpClient
-	transport *mcp.SSEClientTransport
+	transport mcp.Transport
 }
 
 // RegisterCreateFile creates a new instance of the CreateFile tool and loads it
@@ -283,15 +373,12 @@ type CreateFile struct {
 func RegisterCreateFile(mcpClient *mcpClient, tools map[string]Tool) client.D {
 	toolName := "tool_create_file"
 
-	addr := fmt.Sprintf("http://%s/%s", mcpHost, toolName)
-	transport := mcp.SSEClientTransport{
-		Endpoint: addr,
-	}
+	transport := newToolTransport(toolName)
 
 	cf := CreateFile{
 		name:      toolName,
 		mcpClient: mcpClient,
-		transport: &transport,
+		transport: transport,
 	}
 	tools[cf.name] = &cf
 
@@ -358,7 +445,7 @@ func (cf *CreateFile) Call(ctx context.Context, tool client.ToolCall) (resp clie
 type GoCodeEditor struct {
 	name      string
 	mcpClient *mcpClient
-	transport *mcp.SSEClientTransport
+	transport mcp.Transport
 }
 
 // RegisterGoCodeEditor creates a new instance of the GoCodeEditor tool and loads it
@@ -366,15 +453,12 @@ type GoCodeEditor struct {
 func RegisterGoCodeEditor(mcpClient *mcpClient, tools map[string]Tool) client.D {
 	toolName := "tool_go_code_editor"
 
-	addr := fmt.Sprintf("http://%s/%s", mcpHost, toolName)
-	transport := mcp.SSEClientTransport{
-		Endpoint: addr,
-	}
+	transport := newToolTransport(toolName)
 
 	gce := GoCodeEditor{
 		name:      toolName,
 		mcpClient: mcpClient,
-		transport: &transport,
+		transport: transport,
 	}
 	tools[gce.name] = &gce
 
@@ -15,43 +15,76 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/ardanlabs/ai-training/foundation/shell"
 )
 
-func init() {
-	// -------------------------------------------------------------------------
-	// Runs the MCP server locally for our example purposes. This could be
-	// replaced with a MCP server that is running in a different process.
+// newMCPServer constructs the MCP server with all the tooling we support
+// registered, returning the URL paths the tools are served on.
+func newMCPServer() (*mcp.Server, map[string]bool) {
+	fileOperations := mcp.NewServer(&mcp.Implementation{Name: "file_operations", Version: "v1.0.0"}, nil)
+
+	paths := map[string]bool{
+		RegisterReadFileTool(fileOperations):     true,
+		RegisterSearchFilesTool(fileOperations):  true,
+		RegisterCreateFileTool(fileOperations):   true,
+		RegisterGoCodeEditorTool(fileOperations): true,
+		RegisterShellCommandTool(fileOperations): true,
+	}
 
-	go func() {
-		mcpListenAndServe(mcpHost)
-	}()
+	return fileOperations, paths
 }
 
-// mcpListenAndServe starts the MCP server for all the tooling we support.
+// mcpListenAndServe starts the MCP server over HTTP. This could be
+// replaced with a MCP server that is running in a different process.
+// The streamable HTTP transport is served at /mcp, the direction the MCP
+// spec is moving, while the per-tool SSE endpoints remain for clients
+// that haven't migrated.
 func mcpListenAndServe(host string) {
 	fmt.Printf("\nServer: MCP servers serving at %s\n", host)
 
-	fileOperations := mcp.NewServer(&mcp.Implementation{Name: "file_operations", Version: "v1.0.0"}, nil)
+	fileOperations, paths := newMCPServer()
 
 	f := func(request *http.Request) *mcp.Server {
-		url := request.URL.Path
-
-		switch url {
-		case RegisterReadFileTool(fileOperations),
-			RegisterSearchFilesTool(fileOperations),
-			RegisterCreateFileTool(fileOperations),
-			RegisterGoCodeEditorTool(fileOperations):
+		// The discovery endpoint serves the same tool server so clients
+		// can call tools/list before they know any tool names.
+		if request.URL.Path == "/discovery" || paths[request.URL.Path] {
 			return fileOperations
-
-		default:
-			return mcp.NewServer(&mcp.Implementation{Name: "unknown_tool", Version: "v1.0.0"}, nil)
 		}
+
+		return mcp.NewServer(&mcp.Implementation{Name: "unknown_tool", Version: "v1.0.0"}, nil)
 	}
 
-	handler := mcp.NewSSEHandler(f, &mcp.SSEOptions{})
-	fmt.Println(http.ListenAndServe(host, handler))
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return fileOperations }, nil))
+	mux.Handle("/", mcp.NewSSEHandler(f, &mcp.SSEOptions{}))
+
+	fmt.Println(http.ListenAndServe(host, requireAPIKey(mux)))
+}
+
+// requireAPIKey rejects requests that don't carry the configured API key
+// as a bearer token. With no key configured, everything passes, which is
+// only acceptable when the server stays on localhost.
+func requireAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mcpAPIKey != "" && r.Header.Get("Authorization") != "Bearer "+mcpAPIKey {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// runMCPStdio serves the MCP tools over stdin/stdout. This is how most
+// MCP hosts run tool servers: as child processes they launch themselves.
+func runMCPStdio(ctx context.Context) error {
+	server, _ := newMCPServer()
+
+	return server.Run(ctx, &mcp.StdioTransport{})
 }
 
 // =============================================================================
@@ -276,6 +309,60 @@ func CreateFileHandler(ctx context.Context, req *mcp.CallToolRequest, params Cre
 
 // =============================================================================
 
+// RegisterShellCommandTool registers the shell_command tool with the given MCP server.
+func RegisterShellCommandTool(mcpServer *mcp.Server) string {
+	const toolName = "tool_shell_command"
+	const tooDescription = "Runs an allowlisted shell command and returns its output."
+
+	mcp.AddTool(mcpServer, &mcp.Tool{Name: toolName, Description: tooDescription}, ShellCommandHandler)
+
+	return "/" + toolName
+}
+
+// ShellCommandToolParams represents the parameters for this tool call.
+type ShellCommandToolParams struct {
+	Command []string `json:"command" jsonschema:"The command and its arguments to execute."`
+}
+
+// shellRunner limits the shell tool to the binaries listed here, checks
+// their arguments against the patterns, and bounds execution with a
+// timeout and an output cap. The find rule only allows read-only
+// expressions, so action options like -delete and -exec are rejected.
+var shellRunner = shell.NewRunner(
+	shell.WithRule("find", `^-(name|iname|path|type|maxdepth|mindepth|not)$`, `^[\w./*]+$`),
+	shell.WithRule("ls", `^-[la]+$`, `^[\w./]+$`),
+	shell.WithTimeout(10*time.Second),
+	shell.WithMaxOutput(64*1024),
+)
+
+// ShellCommandHandler runs a shell command through the allowlist. The
+// agent additionally gates this tool behind a confirmation prompt.
+func ShellCommandHandler(ctx context.Context, req *mcp.CallToolRequest, params ShellCommandToolParams) (*mcp.CallToolResult, any, error) {
+	output, err := shellRunner.Run(ctx, params.Command)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info := struct {
+		Output string `json:"output"`
+	}{
+		Output: output,
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{
+			Text: string(data),
+		}},
+	}, nil, nil
+}
+
+// =============================================================================
+
 // RegisterGoCodeEditorTool registers the go_code_editor tool with the given MCP server.
 func RegisterGoCodeEditorTool(mcpServer *mcp.Server) string {
 	const toolName = "tool_go_code_editor"
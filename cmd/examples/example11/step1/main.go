@@ -15,7 +15,6 @@ package main
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -24,15 +23,21 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/errs"
 	"github.com/ardanlabs/ai-training/foundation/mongodb"
+	"github.com/ardanlabs/ai-training/foundation/observ"
+	"github.com/ardanlabs/ai-training/foundation/preflight"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -46,11 +51,12 @@ var (
 	frameWidth       = 640
 	frameHeight      = 360
 	videoFileName    = "training.mp4"
-	videoDir         = "zarf/samples/videos/"
-	framesDir        = "frames"
+	videoDir         = filepath.Join("zarf", "samples", "videos")
+	workDir          = ""
+	traceEndpoint    = ""
 )
 
-var ErrFFMPEG = errors.New("ffmpeg error")
+var ErrFFMPEG = errs.ErrFFMPEG
 
 func init() {
 	if v := os.Getenv("LLM_VISION_SERVER"); v != "" {
@@ -68,6 +74,18 @@ func init() {
 	if v := os.Getenv("LLM_TEXT_EMBED_MODEL"); v != "" {
 		modelTextEmbed = v
 	}
+
+	// Set TRACE_ENDPOINT=localhost:4317 to export a span per pipeline
+	// stage to the jaeger service in compose.
+	if v := os.Getenv("TRACE_ENDPOINT"); v != "" {
+		traceEndpoint = v
+	}
+
+	// Set VIDEO_WORK_DIR to keep the intermediate chunk and frame files
+	// between runs. The default is a temp dir cleaned up on exit.
+	if v := os.Getenv("VIDEO_WORK_DIR"); v != "" {
+		workDir = v
+	}
 }
 
 const promptKeyFrameDesc = `
@@ -95,6 +113,18 @@ const promptKeyFrameDesc = `
 	Make sure all text to be placed inside a JSON document is properly encoded and that the JSON is valid.
 `
 
+// keyFrameSchema constrains the key frame response so the JSON always
+// unmarshals without repair code.
+var keyFrameSchema = client.D{
+	"type": "object",
+	"properties": client.D{
+		"description":    client.D{"type": "string"},
+		"classification": client.D{"type": "string"},
+		"text":           client.D{"type": "string"},
+	},
+	"required": []string{"description", "classification", "text"},
+}
+
 // =============================================================================
 
 type keyFrame struct {
@@ -114,7 +144,34 @@ func main() {
 }
 
 func run() error {
-	ctx := context.Background()
+
+	// A SIGINT or SIGTERM cancels the context so the chunk being processed
+	// stops cleanly instead of being killed partway through.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// -------------------------------------------------------------------------
+
+	checks := []preflight.Check{
+		preflight.Mongo("mongodb://localhost:27017"),
+		preflight.ModelServer(urlVision),
+		preflight.FFMPEG(),
+	}
+
+	if err := preflight.Run(ctx, checks...); err != nil {
+		return err
+	}
+
+	// -------------------------------------------------------------------------
+
+	shutdown, err := observ.InitTracing("example11-step1", traceEndpoint)
+	if err != nil {
+		return fmt.Errorf("init tracing: %w", err)
+	}
+	defer shutdown(context.Background())
+
+	ctx, span := observ.AddSpan(ctx, "pipeline", attribute.String("video", videoFileName))
+	defer span.End()
 
 	// -------------------------------------------------------------------------
 
@@ -141,9 +198,31 @@ func run() error {
 
 	// -------------------------------------------------------------------------
 
+	// The intermediate chunk and frame files live under the work dir. When
+	// one wasn't specified, use a temp dir that is removed on exit.
+	work := workDir
+	if work == "" {
+		dir, err := os.MkdirTemp("", "example11-")
+		if err != nil {
+			return fmt.Errorf("create work dir: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		work = dir
+	}
+
+	chunksDir := filepath.Join(work, "chunks")
+	framesDir := filepath.Join(work, "frames")
+
+	// -------------------------------------------------------------------------
+
 	videoPath := filepath.Join(videoDir, videoFileName)
 
-	if err := splitVideoIntoChunks(videoPath); err != nil {
+	if err := func() error {
+		_, span := observ.AddSpan(ctx, "split")
+		defer span.End()
+
+		return splitVideoIntoChunks(videoPath, chunksDir)
+	}(); err != nil {
 		return fmt.Errorf("splitting video into chunks: %w", err)
 	}
 
@@ -151,7 +230,6 @@ func run() error {
 
 	startingVideoTime := 0.0
 
-	chunksDir := filepath.Join(videoDir, "chunks")
 	fmt.Printf("Processing video chunks in directory: %s\n", chunksDir)
 
 	f := func(path string, d fs.DirEntry, err error) error {
@@ -159,6 +237,12 @@ func run() error {
 			return err
 		}
 
+		// Stop walking once an interrupt cancels the context. The chunk
+		// documents already in MongoDB act as the resume checkpoint.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		if d.IsDir() {
 			return nil
 		}
@@ -167,7 +251,7 @@ func run() error {
 			return nil
 		}
 
-		videoChunkFile := filepath.Join(videoDir, "chunks", path)
+		videoChunkFile := filepath.Join(chunksDir, path)
 
 		fmt.Print("\n=================================================\n\n")
 		fmt.Printf("Processing chunk file: %s\n", videoChunkFile)
@@ -182,7 +266,7 @@ func run() error {
 			startingVideoTime += duration
 		}()
 
-		err = processChunk(ctx, col, llmVision, llmTextEmbed, videoDir, videoFileName, videoChunkFile, startingVideoTime, duration)
+		err = processChunk(ctx, col, llmVision, llmTextEmbed, framesDir, videoFileName, videoChunkFile, startingVideoTime, duration)
 		if err != nil {
 			if errors.Is(err, ErrFFMPEG) {
 				fmt.Printf("FFMPEG error processing chunk: %s\n", err)
@@ -195,13 +279,17 @@ func run() error {
 	}
 
 	if err := fs.WalkDir(os.DirFS(chunksDir), ".", f); err != nil {
+		if errors.Is(err, context.Canceled) {
+			fmt.Print("\nInterrupted: completed chunks are saved, rerun to resume\n")
+			return nil
+		}
 		return fmt.Errorf("walk directory: %w", err)
 	}
 
 	return nil
 }
 
-func processChunk(ctx context.Context, col *mongo.Collection, llmVision *client.LLM, llmTextEmbed *client.LLM, videoDir string, videoFileName string, videoChunkFile string, startingVideoTime float64, duration float64) error {
+func processChunk(ctx context.Context, col *mongo.Collection, llmVision *client.LLM, llmTextEmbed *client.LLM, framesDir string, videoFileName string, videoChunkFile string, startingVideoTime float64, duration float64) error {
 	exists, err := existsDocument(ctx, col, videoFileName, videoChunkFile)
 	if err != nil {
 		return fmt.Errorf("exists document: %w", err)
@@ -211,18 +299,44 @@ func processChunk(ctx context.Context, col *mongo.Collection, llmVision *client.
 		return nil
 	}
 
-	transcription, err := extractAudioTranscription(videoChunkFile)
+	ctx, span := observ.AddSpan(ctx, "chunk", attribute.String("file", filepath.Base(videoChunkFile)))
+	defer span.End()
+
+	// If an interrupt stops this chunk partway through, remove its frames
+	// directory so the rerun starts the chunk from scratch.
+	defer func() {
+		if ctx.Err() != nil {
+			os.RemoveAll(filepath.Join(framesDir, filepath.Base(videoChunkFile)))
+		}
+	}()
+
+	transcription, err := func() (string, error) {
+		_, span := observ.AddSpan(ctx, "transcribe")
+		defer span.End()
+
+		return extractAudioTranscription(videoChunkFile)
+	}()
 	if err != nil {
 		return fmt.Errorf("extract audio transcription: %w", err)
 	}
 
-	if err := createKeyFrameFiles(videoChunkFile); err != nil {
+	if err := func() error {
+		_, span := observ.AddSpan(ctx, "frames")
+		defer span.End()
+
+		return createKeyFrameFiles(videoChunkFile, framesDir)
+	}(); err != nil {
 		return fmt.Errorf("create key frame files: %w %w", ErrFFMPEG, err)
 	}
 
 	chunkName := filepath.Base(videoChunkFile)
 
-	keyFrames, err := processKeyFrameFiles(chunkName, videoDir, llmVision)
+	keyFrames, err := func() ([]keyFrame, error) {
+		ctx, span := observ.AddSpan(ctx, "describe", attribute.String("chunk", chunkName))
+		defer span.End()
+
+		return processKeyFrameFiles(ctx, chunkName, framesDir, llmVision)
+	}()
 	if err != nil {
 		return fmt.Errorf("process key frame files: %w", err)
 	}
@@ -260,12 +374,22 @@ func processChunk(ctx context.Context, col *mongo.Collection, llmVision *client.
 	fmt.Printf("Duration: %f\n", duration)
 	fmt.Printf("Input: %s\n", input)
 
-	embed, err := llmTextEmbed.EmbedText(ctx, input)
+	embed, err := func() ([]float64, error) {
+		ctx, span := observ.AddSpan(ctx, "embed")
+		defer span.End()
+
+		return llmTextEmbed.EmbedText(ctx, input)
+	}()
 	if err != nil {
 		return fmt.Errorf("embed text: %w", err)
 	}
 
-	if err := insertDocument(ctx, col, embed, input, videoFileName, videoChunkFile, startingVideoTime, duration); err != nil {
+	if err := func() error {
+		ctx, span := observ.AddSpan(ctx, "insert")
+		defer span.End()
+
+		return insertDocument(ctx, col, embed, input, videoFileName, videoChunkFile, startingVideoTime, duration)
+	}(); err != nil {
 		return fmt.Errorf("insert document: %w", err)
 	}
 
@@ -274,11 +398,20 @@ func processChunk(ctx context.Context, col *mongo.Collection, llmVision *client.
 
 // =============================================================================
 
-func splitVideoIntoChunks(videoPath string) error {
+func splitVideoIntoChunks(videoPath string, chunksDir string) error {
 	fmt.Printf("Splitting video into chunks: %s\n", videoPath)
 
-	ffmpegCommand := fmt.Sprintf("ffmpeg -i %s -c copy -map 0 -f segment -segment_time %d -reset_timestamps 1 -loglevel error zarf/samples/videos/chunks/output_%%05d.mp4", videoPath, chunkSize)
-	out, err := exec.Command("/bin/sh", "-c", ffmpegCommand).CombinedOutput()
+	if err := os.MkdirAll(chunksDir, 0755); err != nil {
+		return fmt.Errorf("mkdirall: %w", err)
+	}
+
+	outputPattern := filepath.Join(chunksDir, "output_%05d.mp4")
+
+	cmd := exec.Command("ffmpeg", "-i", videoPath, "-c", "copy", "-map", "0",
+		"-f", "segment", "-segment_time", strconv.Itoa(chunkSize),
+		"-reset_timestamps", "1", "-loglevel", "error", outputPattern)
+
+	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("error while running ffmpeg: %s, %w: %s", videoPath, err, string(out))
 	}
@@ -320,8 +453,14 @@ func extractAudioTranscription(videoChunkFile string) (string, error) {
 
 	queue := chunkSize + 5
 
-	ffmpegCommand := fmt.Sprintf("ffmpeg -i %s -vn -af \"whisper=model=zarf/models/ggml-tiny.bin :destination=- :format=text :queue=%d\" -loglevel error -f null -", videoChunkFile, queue)
-	out, err := exec.Command("/bin/sh", "-c", ffmpegCommand).CombinedOutput()
+	// ffmpeg filter args use forward slashes on every platform, so the
+	// model path isn't built with filepath.Join.
+	filter := fmt.Sprintf("whisper=model=zarf/models/ggml-tiny.bin :destination=- :format=text :queue=%d", queue)
+
+	cmd := exec.Command("ffmpeg", "-i", videoChunkFile, "-vn", "-af", filter,
+		"-loglevel", "error", "-f", "null", "-")
+
+	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("error while running ffmpeg: %w: %s", err, string(out))
 	}
@@ -329,10 +468,10 @@ func extractAudioTranscription(videoChunkFile string) (string, error) {
 	return string(out), nil
 }
 
-func processKeyFrameFiles(chunkName string, videoDir string, llmVision *client.LLM) ([]keyFrame, error) {
+func processKeyFrameFiles(ctx context.Context, chunkName string, framesDir string, llmVision *client.LLM) ([]keyFrame, error) {
 	fmt.Println("Processing key frames")
 
-	fullpath := filepath.Join(videoDir, framesDir, chunkName)
+	fullpath := filepath.Join(framesDir, chunkName)
 
 	keyFramefiles, err := getFilesFromDirectory(fullpath)
 	if err != nil {
@@ -359,29 +498,35 @@ func processKeyFrameFiles(chunkName string, videoDir string, llmVision *client.L
 		}
 	}
 
-	if err := createKeyFrameDescriptions(keyFrames, llmVision); err != nil {
+	if err := createKeyFrameDescriptions(ctx, keyFrames, llmVision); err != nil {
 		return nil, fmt.Errorf("create key frame descriptions: %w", err)
 	}
 
 	return keyFrames, nil
 }
 
-func createKeyFrameFiles(videoChunkFile string) error {
+func createKeyFrameFiles(videoChunkFile string, framesDir string) error {
 	fmt.Println("Creating key frame files")
 
 	chunkName := filepath.Base(videoChunkFile)
+	chunkFramesDir := filepath.Join(framesDir, chunkName)
 
-	if err := os.RemoveAll(videoDir + "/" + framesDir + "/" + chunkName); err != nil {
+	if err := os.RemoveAll(chunkFramesDir); err != nil {
 		return fmt.Errorf("remove past work files: %w", err)
 	}
 
-	if err := os.MkdirAll(videoDir+"/"+framesDir+"/"+chunkName, 0755); err != nil {
+	if err := os.MkdirAll(chunkFramesDir, 0755); err != nil {
 		return fmt.Errorf("mkdirall: %w", err)
 	}
 
-	ffmpegCommand := fmt.Sprintf("ffmpeg -skip_frame nokey -i %s -vf \"scale='if(gt(iw,ih),%d,-1)':'if(gt(ih,iw),%d,-1)'\" -fps_mode vfr -frame_pts true -loglevel error zarf/samples/videos/%s/%s/%%05d.png", videoChunkFile, frameWidth, frameHeight, framesDir, chunkName)
+	outputPattern := filepath.Join(chunkFramesDir, "%05d.png")
+	scale := fmt.Sprintf("scale='if(gt(iw,ih),%d,-1)':'if(gt(ih,iw),%d,-1)'", frameWidth, frameHeight)
+
+	cmd := exec.Command("ffmpeg", "-skip_frame", "nokey", "-i", videoChunkFile,
+		"-vf", scale, "-fps_mode", "vfr", "-frame_pts", "true",
+		"-loglevel", "error", outputPattern)
 
-	out, err := exec.Command("/bin/sh", "-c", ffmpegCommand).CombinedOutput()
+	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("error while running ffmpeg: %w: %s", err, string(out))
 	}
@@ -389,7 +534,7 @@ func createKeyFrameFiles(videoChunkFile string) error {
 	return nil
 }
 
-func createKeyFrameDescriptions(keyFrames []keyFrame, llmVision *client.LLM) error {
+func createKeyFrameDescriptions(ctx context.Context, keyFrames []keyFrame, llmVision *client.LLM) error {
 	fmt.Printf("Creating key frame descriptions: %d\n", len(keyFrames))
 
 	semaphore := 1
@@ -412,40 +557,26 @@ func createKeyFrameDescriptions(keyFrames []keyFrame, llmVision *client.LLM) err
 				return fmt.Errorf("read image: %w", err)
 			}
 
-			ctx, cancel := context.WithTimeout(context.Background(), frameDescTimeout)
+			ctx, cancel := context.WithTimeout(ctx, frameDescTimeout)
 			defer cancel()
 
 			p1 := client.WithImage(mimeType, image)
 			p2 := client.WithParams(0.0, 0.1, 1)
 			p3 := client.WithRepeatPenalty(1.1, 64)
 
-			response, err := llmVision.ChatCompletions(ctx, promptKeyFrameDesc, p1, p2, p3)
-			if err != nil {
-				return fmt.Errorf("chat completions: %w", err)
-			}
-
-			jsonDoc := strings.Trim(response, "`")
-			jsonDoc = strings.TrimPrefix(jsonDoc, "json")
-			jsonDoc = escapeInvalidCharsInStrings(jsonDoc)
-			jsonDoc = encodeTextFieldToBase64(jsonDoc)
-
 			var descr struct {
 				Description    string `json:"description"`
 				Classification string `json:"classification"`
 				Text           string `json:"text"`
 			}
-			if err := json.Unmarshal([]byte(jsonDoc), &descr); err != nil {
-				return fmt.Errorf("unmarshal: %w: %s", err, jsonDoc)
-			}
 
-			textBytes, err := base64.StdEncoding.DecodeString(descr.Text)
-			if err != nil {
-				return fmt.Errorf("decode text: %w", err)
+			if err := llmVision.ChatCompletionsJSON(ctx, promptKeyFrameDesc, keyFrameSchema, &descr, p1, p2, p3); err != nil {
+				return fmt.Errorf("chat completions json: %w", err)
 			}
 
 			keyFrames[i].description = descr.Description
 			keyFrames[i].classification = descr.Classification
-			keyFrames[i].text = string(textBytes)
+			keyFrames[i].text = descr.Text
 
 			return nil
 		})
@@ -484,118 +615,6 @@ func getFilesFromDirectory(directoryPath string) ([]string, error) {
 	return files, nil
 }
 
-func escapeInvalidCharsInStrings(jsonDoc string) string {
-	var result strings.Builder
-	inString := false
-
-	for i := 0; i < len(jsonDoc); i++ {
-		c := jsonDoc[i]
-
-		if c == '"' && (i == 0 || jsonDoc[i-1] != '\\') {
-			if inString {
-				// Check if this quote ends the string properly.
-				// Valid JSON after closing quote: whitespace, comma, colon, ], }
-				j := i + 1
-
-				for j < len(jsonDoc) && (jsonDoc[j] == ' ' || jsonDoc[j] == '\t' || jsonDoc[j] == '\n' || jsonDoc[j] == '\r') {
-					j++
-				}
-
-				if j < len(jsonDoc) && jsonDoc[j] != ',' && jsonDoc[j] != '}' && jsonDoc[j] != ']' && jsonDoc[j] != ':' {
-					// This is an unescaped quote inside the string.
-					result.WriteString(`\"`)
-					continue
-				}
-			}
-
-			inString = !inString
-			result.WriteByte(c)
-			continue
-		}
-
-		if inString && c == '\n' {
-			result.WriteString(`\n`)
-			continue
-		}
-
-		if inString && c == '\r' {
-			result.WriteString(`\r`)
-			continue
-		}
-
-		if inString && c == '\t' {
-			result.WriteString(`\t`)
-			continue
-		}
-
-		result.WriteByte(c)
-	}
-
-	return result.String()
-}
-
-func encodeTextFieldToBase64(jsonDoc string) string {
-	const key = `"text"`
-	idx := strings.Index(jsonDoc, key)
-
-	// If text field is missing, add it as empty.
-	if idx == -1 {
-		jsonDoc = strings.TrimRight(jsonDoc, " \t\n\r}")
-		return jsonDoc + `,"text":""}`
-	}
-
-	// Check if text field is an array (bad model output). Replace with empty string.
-	colonIdx := strings.Index(jsonDoc[idx:], ":") + idx
-	afterColon := colonIdx + 1
-	for afterColon < len(jsonDoc) && (jsonDoc[afterColon] == ' ' || jsonDoc[afterColon] == '\t' || jsonDoc[afterColon] == '\n' || jsonDoc[afterColon] == '\r') {
-		afterColon++
-	}
-
-	if afterColon < len(jsonDoc) && jsonDoc[afterColon] == '[' {
-		// Find the closing bracket and replace the array with empty string.
-		depth := 1
-		endBracket := afterColon + 1
-		for endBracket < len(jsonDoc) && depth > 0 {
-			switch jsonDoc[endBracket] {
-			case '[':
-				depth++
-			case ']':
-				depth--
-			}
-			endBracket++
-		}
-
-		rest := strings.TrimRight(jsonDoc[endBracket:], " \t\n\r")
-		if !strings.HasSuffix(rest, "}") {
-			rest = "}"
-		}
-
-		return jsonDoc[:afterColon] + `""` + rest
-	}
-
-	// Check if text field ends properly with "}
-	if strings.HasSuffix(strings.TrimRight(jsonDoc, " \t\n\r"), `"}`) {
-		// Find the text value boundaries.
-		startQuote := strings.Index(jsonDoc[colonIdx:], `"`) + colonIdx
-		endQuote := strings.LastIndex(jsonDoc, `"`)
-
-		textValue := jsonDoc[startQuote+1 : endQuote]
-		encoded := base64.StdEncoding.EncodeToString([]byte(textValue))
-
-		return jsonDoc[:startQuote+1] + encoded + jsonDoc[endQuote:]
-	}
-
-	// Text field is malformed. Extract what we can and fix it.
-	startQuote := strings.Index(jsonDoc[colonIdx:], `"`) + colonIdx
-
-	// Take everything after the opening quote as the text value.
-	textValue := jsonDoc[startQuote+1:]
-	textValue = strings.TrimRight(textValue, " \t\n\r\"}")
-	encoded := base64.StdEncoding.EncodeToString([]byte(textValue))
-
-	return jsonDoc[:startQuote+1] + encoded + `"}`
-}
-
 func readImage(fileName string) ([]byte, string, error) {
 	data, err := os.ReadFile(fileName)
 	if err != nil {
@@ -49,6 +49,17 @@ func initDB(ctx context.Context, client *mongo.Client) (*mongo.Collection, error
 		return nil, fmt.Errorf("createVectorIndex (text): %w", err)
 	}
 
+	// Make sure the collection wasn't built with a different embedding model.
+	meta := mongodb.CollectionMeta{
+		Model:      modelTextEmbed,
+		Dimensions: dimensions,
+		Similarity: settings.Similarity,
+	}
+
+	if err := mongodb.CheckCollectionMeta(ctx, col, meta); err != nil {
+		return nil, fmt.Errorf("checkCollectionMeta: %w", err)
+	}
+
 	indexModel := mongo.IndexModel{
 		Keys:    bson.D{{Key: "video", Value: 1}, {Key: "chunk", Value: 1}},
 		Options: options.Index().SetUnique(true),
@@ -86,12 +97,17 @@ func insertDocument(ctx context.Context, col *mongo.Collection, embed []float64,
 		Embedding: embed,
 	}
 
-	res, err := col.InsertOne(ctx, doc)
-	if err != nil {
-		return fmt.Errorf("col.InsertOne: %w", err)
+	// Upsert keyed on video/chunk so reprocessing a chunk replaces its
+	// document instead of failing on the unique index.
+	settings := mongodb.BulkUpsertSettings{
+		Keys: []string{"video", "chunk"},
+	}
+
+	if err := mongodb.BulkUpsert(ctx, col, []any{doc}, settings); err != nil {
+		return fmt.Errorf("mongodb.BulkUpsert: %w", err)
 	}
 
-	fmt.Printf("Inserted into Mongo: %v\n", res.InsertedID)
+	fmt.Printf("Inserted into Mongo: %s/%s\n", doc.Video, doc.Chunk)
 
 	return nil
 }
@@ -14,17 +14,20 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/conversation"
 	"github.com/ardanlabs/ai-training/foundation/mongodb"
+	"github.com/ardanlabs/ai-training/foundation/rerank"
+	"github.com/ardanlabs/ai-training/foundation/sessions"
 	"github.com/ardanlabs/ai-training/foundation/tiktoken"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
@@ -47,9 +50,22 @@ func init() {
 	}
 }
 
+// Session persistence so a conversation survives quitting the program.
+const (
+	sessionDir = "zarf/data/sessions"
+	sessionID  = "example11-step2"
+)
+
+var (
+	resumeSession = flag.Bool("resume", false, "resume the previous conversation")
+	rerankContext = flag.Bool("rerank", false, "rerank injected context with a second relevance pass")
+)
+
 // =============================================================================
 
 func main() {
+	flag.Parse()
+
 	if err := run(); err != nil {
 		log.Fatal(err)
 	}
@@ -98,6 +114,7 @@ type Agent struct {
 	tke             *tiktoken.Tiktoken
 	tools           map[string]Tool
 	toolDocuments   []client.D
+	reranker        rerank.Reranker
 }
 
 // NewAgent creates a new instance of Agent.
@@ -129,8 +146,23 @@ func NewAgent(getUserMessage func() (string, bool)) (*Agent, error) {
 
 	tools := map[string]Tool{}
 
+	chatClient := client.NewLLM(urlChat, modelChat)
+
+	// When asked, rerank the injected context with a second relevance
+	// pass. A cross-encoder behind LLM_RERANKSERVER is used when set,
+	// otherwise the chat model judges each result.
+	var reranker rerank.Reranker
+	if *rerankContext {
+		switch url := os.Getenv("LLM_RERANKSERVER"); {
+		case url != "":
+			reranker = rerank.NewEndpoint(url, os.Getenv("LLM_RERANKMODEL"))
+		default:
+			reranker = rerank.NewLLMJudge(chatClient)
+		}
+	}
+
 	agent := Agent{
-		chatClient:      client.NewLLM(urlChat, modelChat),
+		chatClient:      chatClient,
 		textEmbedClient: client.NewLLM(urlTextEmbed, modelTextEmbed),
 		sseClient:       client.NewSSE[client.ChatSSE](client.StdoutLogger),
 		col:             col,
@@ -138,6 +170,7 @@ func NewAgent(getUserMessage func() (string, bool)) (*Agent, error) {
 		tke:             tke,
 		tools:           tools,
 		toolDocuments:   []client.D{},
+		reranker:        reranker,
 	}
 
 	return &agent, nil
@@ -163,15 +196,35 @@ Reasoning: high
 
 // Run starts the agent and runs the chat loop.
 func (a *Agent) Run(ctx context.Context) error {
-	var conversation []client.D // History of the conversation
-	var reasonContent []string  // Reasoning content per model call
-	var inToolCall bool         // Need to know we are inside a tool call request
+	var reasonContent []string // Reasoning content per model call
+	var inToolCall bool        // Need to know we are inside a tool call request
+
+	// The conversation package owns the history bookkeeping: the system
+	// prompt stays pinned and the oldest messages are evicted when the
+	// window fills. The tool schemas sent with every request count
+	// against the window just like the messages do.
+	conv := conversation.New(a.tke, contextWindow)
+	conv.SetReserved(a.tke.CountTools(a.toolDocuments))
 
-	conversation = append(conversation, client.D{
+	conv.Pin(client.D{
 		"role":    "system",
 		"content": systemPrompt,
 	})
 
+	if *resumeSession {
+		msgs, err := sessions.Load(sessionDir, sessionID)
+		if err != nil {
+			return fmt.Errorf("load session: %w", err)
+		}
+
+		// Saved sessions start with the system prompt, which is already
+		// pinned above.
+		if len(msgs) > 1 {
+			conv.Add(msgs[1:]...)
+			fmt.Printf("\nResuming session with %d messages\n", len(msgs))
+		}
+	}
+
 	fmt.Printf("\nChat with %s (use 'ctrl-c' to quit)\n", modelChat)
 
 	timeForResult := time.NewTicker(100 * time.Millisecond)
@@ -188,13 +241,13 @@ func (a *Agent) Run(ctx context.Context) error {
 				break
 			}
 
-			userInput, err := a.injectContext(ctx, conversation, userInput)
+			userInput, err := a.injectContext(ctx, conv.Messages(), userInput)
 			if err != nil {
 				fmt.Printf("\n\n\u001b[91mERROR:%s\u001b[0m\n\n", err)
 				continue
 			}
 
-			conversation = append(conversation, client.D{
+			conv.Add(client.D{
 				"role":    "user",
 				"content": userInput,
 			})
@@ -232,7 +285,7 @@ func (a *Agent) Run(ctx context.Context) error {
 
 		d := client.D{
 			"model":          modelChat,
-			"messages":       conversation,
+			"messages":       conv.Messages(),
 			"max_tokens":     contextWindow,
 			"temperature":    0.0,
 			"top_p":          0.1,
@@ -289,7 +342,7 @@ func (a *Agent) Run(ctx context.Context) error {
 
 				toolCall := resp.Choices[0].Delta.ToolCalls[0]
 
-				conversation = a.addToConversation(reasonContent, conversation, client.D{
+				a.addToConversation(reasonContent, conv, client.D{
 					"role": "assistant",
 					"content": fmt.Sprintf("Tool call %s: %s(%v)",
 						toolCall.ID,
@@ -299,7 +352,7 @@ func (a *Agent) Run(ctx context.Context) error {
 
 				results := a.callTools(ctx, resp.Choices[0].Delta.ToolCalls)
 				if len(results) > 0 {
-					conversation = a.addToConversation(reasonContent, conversation, results...)
+					a.addToConversation(reasonContent, conv, results...)
 					inToolCall = true
 				}
 
@@ -358,12 +411,17 @@ func (a *Agent) Run(ctx context.Context) error {
 			content = strings.TrimLeft(content, "\n")
 
 			if content != "" {
-				conversation = a.addToConversation(reasonContent, conversation, client.D{
+				a.addToConversation(reasonContent, conv, client.D{
 					"role":    "assistant",
 					"content": content,
 				})
 			}
 		}
+
+		// Persist the conversation so -resume can pick it back up.
+		if err := sessions.Save(sessionDir, sessionID, conv.Messages()); err != nil {
+			fmt.Printf("\n\u001b[91mERROR: saving session: %s\u001b[0m\n", err)
+		}
 	}
 
 	return nil
@@ -385,6 +443,13 @@ func (a *Agent) injectContext(ctx context.Context, conversation []client.D, user
 		return "", fmt.Errorf("failed to search for context: %w", err)
 	}
 
+	if a.reranker != nil {
+		results, err = rerankResults(ctx, a.reranker, userInput, results)
+		if err != nil {
+			return "", fmt.Errorf("failed to rerank context: %w", err)
+		}
+	}
+
 	var extraContext string
 	for _, result := range results {
 		fmt.Printf("\u001b[95m\nScore: %.2f\u001b[0m:\n", result.Score)
@@ -395,6 +460,11 @@ func (a *Agent) injectContext(ctx context.Context, conversation []client.D, user
 	}
 
 	if extraContext != "" {
+
+		// Keep the injected context to a fraction of the window so the
+		// conversation history and question still fit.
+		extraContext = a.tke.Truncate(extraContext, contextWindow/4)
+
 		const prompt = "please answer the following question only using the context provided."
 		userInput = fmt.Sprintf("%s\nCONTEXT: %s\nQUESTION:%s", prompt, extraContext, userInput)
 	}
@@ -452,43 +522,30 @@ func (a *Agent) isQuestionRelevant(ctx context.Context, conversation []client.D,
 	return true, nil
 }
 
-// addToConversation will add new messages to the conversation history and
-// calculate the different tokens used in the conversation and display it to the
-// user. It will also check the amount of input tokens currently in history
-// and remove the oldest messages if we are over.
-func (a *Agent) addToConversation(reasoning []string, conversation []client.D, newMessages ...client.D) []client.D {
-	conversation = append(conversation, newMessages...)
+// addToConversation adds new messages to the history, which evicts the
+// oldest unpinned messages when the window fills, and displays the token
+// usage to the user.
+func (a *Agent) addToConversation(reasoning []string, conv *conversation.Conversation, newMessages ...client.D) {
+	before := conv.Len()
+	conv.Add(newMessages...)
+	evicted := before + len(newMessages) - conv.Len()
 
 	fmt.Print("\n")
 
-	for {
-		var currentWindow int
-		for _, msg := range conversation {
-			currentWindow += a.tke.TokenCount(msg["content"].(string))
-		}
-
-		r := strings.Join(reasoning, " ")
-		reasonTokens := a.tke.TokenCount(r)
+	currentWindow := conv.Tokens()
 
-		totalTokens := currentWindow + reasonTokens
-		percentage := (float64(currentWindow) / float64(contextWindow)) * 100
-		of := float32(contextWindow) / float32(1024)
+	r := strings.Join(reasoning, " ")
+	reasonTokens := a.tke.TokenCount(r)
 
-		fmt.Printf("\u001b[90mTokens Total[%d] Reason[%d] Window[%d] (%.0f%% of %.0fK)\u001b[0m\n", totalTokens, reasonTokens, currentWindow, percentage, of)
+	totalTokens := currentWindow + reasonTokens
+	percentage := (float64(currentWindow) / float64(contextWindow)) * 100
+	of := float32(contextWindow) / float32(1024)
 
-		// ---------------------------------------------------------------------
-		// Check if we have too many input tokens and start removing messages.
+	fmt.Printf("\u001b[90mTokens Total[%d] Reason[%d] Window[%d] (%.0f%% of %.0fK)\u001b[0m\n", totalTokens, reasonTokens, currentWindow, percentage, of)
 
-		if currentWindow > contextWindow {
-			fmt.Print("\u001b[90mRemoving conversation history\u001b[0m\n")
-			conversation = slices.Delete(conversation, 1, 2)
-			continue
-		}
-
-		break
+	if evicted > 0 {
+		fmt.Printf("\u001b[90mRemoved %d oldest message(s) to stay inside the window\u001b[0m\n", evicted)
 	}
-
-	return conversation
 }
 
 // callTools will lookup a requested tool by name and call it.
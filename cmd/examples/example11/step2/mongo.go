@@ -6,6 +6,8 @@ import (
 
 	"github.com/ardanlabs/ai-training/foundation/client"
 	"github.com/ardanlabs/ai-training/foundation/mongodb"
+	"github.com/ardanlabs/ai-training/foundation/rerank"
+	"github.com/ardanlabs/ai-training/foundation/vector"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -15,6 +17,11 @@ const (
 	dbName     = "example11"
 	colName    = "trainingvideo"
 	dimensions = 768
+
+	// Pull more candidates than we inject so the MMR pass has something
+	// to choose from.
+	limitResults     = 2
+	candidateResults = 10
 )
 
 type searchResult struct {
@@ -57,12 +64,38 @@ func initDB(ctx context.Context, client *mongo.Client) (*mongo.Collection, error
 }
 
 func textVectorSearch(ctx context.Context, llm *client.LLM, col *mongo.Collection, question string) ([]searchResult, error) {
-	vector, err := llm.EmbedText(ctx, question)
+	embedding, err := llm.EmbedText(ctx, question)
 	if err != nil {
 		return nil, fmt.Errorf("embedText: %w", err)
 	}
 
-	return vectorSearch(ctx, col, vector)
+	results, err := vectorSearch(ctx, col, embedding)
+	if err != nil {
+		return nil, fmt.Errorf("vectorSearch: %w", err)
+	}
+
+	return mmrSelect(embedding, results, limitResults), nil
+}
+
+// mmrSelect keeps the most relevant results while dropping near duplicate
+// chunks, so the injected context isn't the same transcript said twice.
+func mmrSelect(queryVec []float64, results []searchResult, k int) []searchResult {
+	candidates := make([][]float64, len(results))
+	for i, res := range results {
+		candidates[i] = res.Embedding
+	}
+
+	const lambda = 0.7
+
+	picked := vector.MMR(queryVec, candidates, k, lambda)
+
+	selected := make([]searchResult, len(picked))
+	for i, n := range picked {
+		selected[i] = results[n.Index]
+		selected[i].Score = n.Score
+	}
+
+	return selected
 }
 
 func vectorSearch(ctx context.Context, col *mongo.Collection, vector []float64) ([]searchResult, error) {
@@ -74,7 +107,7 @@ func vectorSearch(ctx context.Context, col *mongo.Collection, vector []float64)
 				"exact":       true,
 				"path":        "embedding",
 				"queryVector": vector,
-				"limit":       2,
+				"limit":       candidateResults,
 			}},
 		},
 		{{
@@ -103,3 +136,27 @@ func vectorSearch(ctx context.Context, col *mongo.Collection, vector []float64)
 
 	return results, nil
 }
+
+// rerankResults reorders the search results with a second relevance pass.
+// The rerank score replaces the similarity score so the relevance gate in
+// injectContext reflects the judged pairing.
+func rerankResults(ctx context.Context, rr rerank.Reranker, question string, results []searchResult) ([]searchResult, error) {
+	documents := make([]string, len(results))
+	for i, res := range results {
+		documents[i] = res.Text
+	}
+
+	ranked, err := rr.Rerank(ctx, question, documents)
+	if err != nil {
+		return nil, fmt.Errorf("rerank: %w", err)
+	}
+
+	reordered := make([]searchResult, len(ranked))
+
+	for i, rank := range ranked {
+		reordered[i] = results[rank.Index]
+		reordered[i].Score = rank.Score
+	}
+
+	return reordered, nil
+}
@@ -0,0 +1,156 @@
+// Package example12 puts the document extraction flow behind an exported
+// Run function configured through Config, the layout example06
+// established, so the docling+LLM pipeline can be pointed at any document.
+package example12
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/docling"
+)
+
+// Config carries everything Run needs. When DocumentURL is set the
+// docling service downloads the document itself, otherwise the file at
+// DocumentPath is uploaded.
+type Config struct {
+	ChatURL       string
+	ChatModel     string
+	DoclingURL    string
+	DocumentPath  string
+	DocumentURL   string
+	ContextWindow int
+	Output        io.Writer
+}
+
+// Run extracts the document's content with docling and has the model
+// structure it into CSV.
+func Run(ctx context.Context, cfg Config) error {
+	fmt.Fprintln(cfg.Output, "\nExtract content from document")
+
+	doc := docling.New(cfg.DoclingURL)
+
+	if err := doc.Ping(ctx); err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+
+	opts := docling.NewOptions()
+	opts.MDPageBreakPlaceholder = "---"
+
+	var data string
+	var err error
+
+	switch {
+	case cfg.DocumentURL != "":
+		data, err = doc.ConvertURL(ctx, cfg.DocumentURL, opts)
+
+	default:
+		data, err = doc.ConvertFile(ctx, cfg.DocumentPath, opts)
+	}
+
+	if err != nil {
+		return fmt.Errorf("docling: %w", err)
+	}
+
+	fmt.Fprintln(cfg.Output, "\nExtracted content")
+	fmt.Fprintf(cfg.Output, "\u001b[92m%s\u001b[0m", data)
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprintln(cfg.Output, "\nProcess against the LLM")
+
+	csvData, err := kronk(ctx, cfg, data)
+	if err != nil {
+		return fmt.Errorf("kronk: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprint(cfg.Output, "\n\nParsed CSV:\n\n")
+
+	reader := csv.NewReader(strings.NewReader(csvData))
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("parse csv: %w", err)
+	}
+
+	for _, record := range records {
+		fmt.Fprintf(cfg.Output, "\u001b[93m%s\u001b[0m", record)
+	}
+
+	return nil
+}
+
+func kronk(ctx context.Context, cfg Config, data string) (string, error) {
+	const prompt = `
+		This data represents a menu. Structure this data to align the categories,
+		items, descriptions, and prices together in a CSV format. First categorize
+		the items, then make sure each item is matched to a category and
+		description. Only output the CSV data and nothing else.
+
+		Use this as an example:
+
+		"CATEGORY","ITEM","DESC",PRICE
+	`
+
+	conversation := []client.D{
+		{
+			"role":    "user",
+			"content": prompt,
+		},
+		{
+			"role":    "user",
+			"content": data,
+		},
+	}
+
+	d := client.D{
+		"model":       cfg.ChatModel,
+		"messages":    conversation,
+		"max_tokens":  cfg.ContextWindow,
+		"temperature": 0.0,
+		"top_p":       0.1,
+		"top_k":       1,
+		"stream":      true,
+	}
+
+	ch := make(chan client.ChatSSE, 100)
+
+	sseClient := client.NewSSE[client.ChatSSE](client.StdoutLogger)
+	if err := sseClient.Do(ctx, http.MethodPost, cfg.ChatURL, d, ch); err != nil {
+		return "", fmt.Errorf("do request: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprint(cfg.Output, "\nReasoning:\n")
+	reasoning := true
+
+	var csvData strings.Builder
+
+	for resp := range ch {
+		if len(resp.Choices) == 0 {
+			continue
+		}
+
+		switch {
+		case resp.Choices[0].Delta.Content != "":
+			if reasoning {
+				fmt.Fprint(cfg.Output, "\n\nOutput:\n")
+				reasoning = false
+			}
+			fmt.Fprint(cfg.Output, resp.Choices[0].Delta.Content)
+			csvData.WriteString(resp.Choices[0].Delta.Content)
+
+		case resp.Choices[0].Delta.Reasoning != "":
+			fmt.Fprintf(cfg.Output, "\u001b[91m%s\u001b[0m", resp.Choices[0].Delta.Reasoning)
+		}
+	}
+
+	return csvData.String(), nil
+}
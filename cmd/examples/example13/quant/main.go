@@ -0,0 +1,206 @@
+// This example compares FLOAT32 and int8 quantized embedding storage in
+// DuckDB. It runs the same set of questions against both columns and
+// reports the recall and latency differences, so you can decide if the 4x
+// smaller quantized column is good enough for your data.
+//
+// # Running the example:
+//
+//	$ make example13-quant
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/duckdbstore"
+	"github.com/ardanlabs/ai-training/foundation/embedding"
+	"github.com/ardanlabs/kronk/sdk/kronk"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/ardanlabs/kronk/sdk/tools/defaults"
+	"github.com/ardanlabs/kronk/sdk/tools/libs"
+	"github.com/ardanlabs/kronk/sdk/tools/models"
+)
+
+const (
+	modelEmbedSource = "ggml-org/embeddinggemma-300m-qat-Q8_0"
+	dbPath           = "zarf/data/duck-ex13-quant.db" // ":memory:"
+	chunksFile       = "zarf/data/book.chunks"
+	dimentions       = 768
+	topK             = 5
+)
+
+// questions exercise different parts of the book so the recall numbers
+// aren't dominated by a single chapter.
+var questions = []string{
+	"How do you declare an interface in Go?",
+	"What is the difference between a slice and an array?",
+	"How does a goroutine differ from an operating system thread?",
+	"When should a method use a pointer receiver?",
+	"How do channels synchronize goroutines?",
+	"What does the defer keyword do?",
+	"How does garbage collection work in Go?",
+	"What is the purpose of the context package?",
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Printf("\nERROR: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	infoEmbed, err := installSystem()
+	if err != nil {
+		return fmt.Errorf("unable to install system: %w", err)
+	}
+
+	krnEmbed, err := newKronk(infoEmbed)
+	if err != nil {
+		return fmt.Errorf("unable to create embedding model: %w", err)
+	}
+	defer func() {
+		fmt.Println("\nUnloading embedding model")
+		if err := krnEmbed.Unload(context.Background()); err != nil {
+			fmt.Printf("failed to unload embedding model: %v", err)
+		}
+	}()
+
+	// -------------------------------------------------------------------------
+
+	emb := embedding.New(krnEmbed)
+
+	db, err := duckdbstore.LoadData(dbPath, emb, dimentions, chunksFile)
+	if err != nil {
+		return fmt.Errorf("error connecting to database: %w", err)
+	}
+	defer db.Close()
+
+	// -------------------------------------------------------------------------
+
+	return compare(emb, db)
+}
+
+// compare runs every question against the FLOAT32 and the int8 column and
+// reports how often the quantized results match the full precision ones.
+func compare(emb *embedding.Model, db *sql.DB) error {
+	fmt.Print("\n--- FLOAT32 vs int8 ---\n\n")
+
+	var matched int
+	var total int
+	var f32Time time.Duration
+	var i8Time time.Duration
+
+	for _, question := range questions {
+		vec, err := func() ([]float32, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			return emb.Embed(ctx, question)
+		}()
+		if err != nil {
+			return fmt.Errorf("embed: %w", err)
+		}
+
+		// ---------------------------------------------------------------------
+
+		t := time.Now()
+
+		f32Docs, err := duckdbstore.Search(db, vec, duckdbstore.Filter{}, topK)
+		if err != nil {
+			return fmt.Errorf("error searching float32: %w", err)
+		}
+
+		f32Time += time.Since(t)
+
+		t = time.Now()
+
+		i8Docs, err := duckdbstore.SearchQuantized(db, vec, duckdbstore.Filter{}, topK)
+		if err != nil {
+			return fmt.Errorf("error searching int8: %w", err)
+		}
+
+		i8Time += time.Since(t)
+
+		// ---------------------------------------------------------------------
+
+		// Recall@K: how many of the full precision top K the quantized
+		// search found, regardless of their order.
+		f32IDs := make(map[int]bool)
+		for _, doc := range f32Docs {
+			f32IDs[doc.ID] = true
+		}
+
+		var found int
+		for _, doc := range i8Docs {
+			if f32IDs[doc.ID] {
+				found++
+			}
+		}
+
+		matched += found
+		total += len(f32Docs)
+
+		fmt.Printf("recall@%d %d/%d: %s\n", topK, found, len(f32Docs), question)
+	}
+
+	// -------------------------------------------------------------------------
+
+	queries := len(questions)
+
+	fmt.Printf("\nRecall@%d : %.1f%%\n", topK, float64(matched)/float64(total)*100)
+	fmt.Printf("FLOAT32  : %v avg per query\n", (f32Time / time.Duration(queries)).Round(time.Microsecond))
+	fmt.Printf("int8     : %v avg per query\n", (i8Time / time.Duration(queries)).Round(time.Microsecond))
+
+	return nil
+}
+
+func installSystem() (models.Path, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Minute)
+	defer cancel()
+
+	libs, err := libs.New(
+		libs.WithVersion(defaults.LibVersion("")),
+	)
+	if err != nil {
+		return models.Path{}, err
+	}
+
+	if _, err := libs.Download(ctx, kronk.FmtLogger); err != nil {
+		return models.Path{}, fmt.Errorf("unable to install llama.cpp: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	mdls, err := models.New()
+	if err != nil {
+		return models.Path{}, fmt.Errorf("unable to create models api: %w", err)
+	}
+
+	infoEmbed, err := mdls.Download(context.Background(), kronk.FmtLogger, modelEmbedSource)
+	if err != nil {
+		return models.Path{}, fmt.Errorf("unable to install model: %w", err)
+	}
+
+	return infoEmbed, nil
+}
+
+func newKronk(mp models.Path) (*kronk.Kronk, error) {
+	if err := kronk.Init(); err != nil {
+		return nil, fmt.Errorf("unable to init kronk: %w", err)
+	}
+
+	krn, err := kronk.New(
+		model.WithModelFiles(mp.ModelFiles),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to create inference model: %w", err)
+	}
+
+	return krn, nil
+}
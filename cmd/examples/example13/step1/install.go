@@ -0,0 +1,410 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/ardanlabs/kronk/sdk/tools/libs"
+	"github.com/hybridgroup/yzma/pkg/download"
+)
+
+// detectTriple identifies the llama.cpp build to install for the local
+// platform. The LLAMA_ARCH, LLAMA_OS, and LLAMA_PROCESSOR environment
+// variables override the detection, such as forcing the cpu build on a
+// linux machine whose GPU is busy.
+func detectTriple() (download.Arch, download.OS, download.Processor, error) {
+	archValue := runtime.GOARCH
+	if v := os.Getenv("LLAMA_ARCH"); v != "" {
+		archValue = v
+	}
+
+	arch, err := download.ParseArch(archValue)
+	if err != nil {
+		return download.Arch{}, download.OS{}, download.Processor{}, fmt.Errorf("invalid LLAMA_ARCH %q: %w", archValue, err)
+	}
+
+	osValue := runtime.GOOS
+	if v := os.Getenv("LLAMA_OS"); v != "" {
+		osValue = v
+	}
+
+	opSys, err := download.ParseOS(osValue)
+	if err != nil {
+		return download.Arch{}, download.OS{}, download.Processor{}, fmt.Errorf("invalid LLAMA_OS %q: %w", osValue, err)
+	}
+
+	if v := os.Getenv("LLAMA_PROCESSOR"); v != "" {
+		processor, err := download.ParseProcessor(v)
+		if err != nil {
+			return download.Arch{}, download.OS{}, download.Processor{}, fmt.Errorf("invalid LLAMA_PROCESSOR %q: %w", v, err)
+		}
+		return arch, opSys, processor, nil
+	}
+
+	// Without an override, pick the best build the platform can run. A
+	// linux or windows machine with a working NVIDIA driver gets the cuda
+	// build, everything else gets cpu, and apple silicon gets metal.
+	processor := download.CPU
+
+	switch opSys {
+	case download.Darwin:
+		if arch == download.ARM64 {
+			processor = download.Metal
+		}
+
+	case download.Linux, download.Windows:
+		if hasCUDA, _ := download.HasCUDA(); hasCUDA {
+			processor = download.CUDA
+		}
+	}
+
+	return arch, opSys, processor, nil
+}
+
+// releaseAsset returns the repository and asset file name for the builds
+// this example verifies: linux-x64 (cpu and cuda) and windows-x64. Other
+// platforms report ok false and install through the SDK instead.
+func releaseAsset(arch download.Arch, opSys download.OS, processor download.Processor, version string) (repo string, asset string, ok bool) {
+	if arch != download.AMD64 {
+		return "", "", false
+	}
+
+	switch {
+	case opSys == download.Linux && processor == download.CPU:
+		return "ggml-org/llama.cpp", fmt.Sprintf("llama-%s-bin-ubuntu-x64.tar.gz", version), true
+
+	case opSys == download.Linux && processor == download.CUDA:
+		return "hybridgroup/llama-cpp-builder", fmt.Sprintf("llama-%s-bin-ubuntu-cuda-13-x64.tar.gz", version), true
+
+	case opSys == download.Windows && processor == download.CPU:
+		return "ggml-org/llama.cpp", fmt.Sprintf("llama-%s-bin-win-cpu-x64.zip", version), true
+	}
+
+	return "", "", false
+}
+
+// installVerified downloads the release asset for the detected build,
+// checks its sha256 against the digest GitHub publishes for the asset,
+// and installs it in the layout the SDK reads. A platform without a
+// verified asset returns nil so the caller falls back to the SDK's
+// installer.
+func installVerified(ctx context.Context, lib *libs.Libs, arch download.Arch, opSys download.OS, processor download.Processor, version string) error {
+	repo, asset, ok := releaseAsset(arch, opSys, processor, version)
+	if !ok {
+		return nil
+	}
+
+	// The version file marks a completed install, so a rerun costs nothing.
+	if installed, err := lib.InstalledVersion(); err == nil && installed.Version == version {
+		return nil
+	}
+
+	digest, err := assetDigest(ctx, repo, version, asset)
+	if err != nil {
+		return fmt.Errorf("asset digest: %w", err)
+	}
+
+	if digest == "" {
+		fmt.Printf("no published digest for %s, using the SDK installer\n", asset)
+		return nil
+	}
+
+	fmt.Printf("downloading %s\n", asset)
+
+	url := fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", repo, version, asset)
+
+	archive, err := downloadVerified(ctx, url, digest)
+	if err != nil {
+		return fmt.Errorf("download %s: %w", asset, err)
+	}
+	defer os.Remove(archive)
+
+	dest := lib.LibsPath()
+
+	if err := extractArchive(archive, asset, dest); err != nil {
+		return fmt.Errorf("extract %s: %w", asset, err)
+	}
+
+	if err := writeVersionFile(dest, version, arch, opSys, processor); err != nil {
+		return fmt.Errorf("write version file: %w", err)
+	}
+
+	fmt.Printf("verified llama.cpp %s installed: %s\n", version, dest)
+
+	return nil
+}
+
+// assetDigest asks the GitHub API for the release and returns the sha256
+// the publisher recorded for the asset. An empty string means the release
+// doesn't publish digests.
+func assetDigest(ctx context.Context, repo string, version string, asset string) (string, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/tags/%s", repo, version)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("release %s %s: status %d", repo, version, resp.StatusCode)
+	}
+
+	var release struct {
+		Assets []struct {
+			Name   string `json:"name"`
+			Digest string `json:"digest"`
+		} `json:"assets"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("decode release: %w", err)
+	}
+
+	for _, a := range release.Assets {
+		if a.Name == asset {
+			return strings.TrimPrefix(a.Digest, "sha256:"), nil
+		}
+	}
+
+	return "", fmt.Errorf("release %s %s has no asset %s", repo, version, asset)
+}
+
+// downloadVerified streams the asset to a temp file while hashing it and
+// compares the hash to the published digest. The archive is removed on a
+// mismatch so a corrupted or tampered download never gets extracted.
+func downloadVerified(ctx context.Context, url string, digest string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	f, err := os.CreateTemp("", "llama-*"+filepath.Ext(url))
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+
+	if _, err := io.Copy(f, io.TeeReader(resp.Body, h)); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("write archive: %w", err)
+	}
+
+	if got := hex.EncodeToString(h.Sum(nil)); got != digest {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("checksum mismatch: got %s, want %s", got, digest)
+	}
+
+	return f.Name(), nil
+}
+
+// extractArchive unpacks the verified archive into the install directory.
+// The linux assets are tar.gz with a top-level directory that gets
+// stripped, the windows assets are zip with files at the root.
+func extractArchive(archive string, asset string, dest string) error {
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return fmt.Errorf("create destination: %w", err)
+	}
+
+	if strings.HasSuffix(asset, ".zip") {
+		return extractZip(archive, dest)
+	}
+
+	return extractTarGz(archive, dest)
+}
+
+func extractTarGz(archive string, dest string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("open archive: %w", err)
+	}
+	defer f.Close()
+
+	gzr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("create gzip reader: %w", err)
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read tar header: %w", err)
+		}
+
+		name, err := stripTopDir(header.Name)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			continue
+		}
+
+		target := filepath.Join(dest, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(header.Mode)); err != nil {
+				return fmt.Errorf("create directory: %w", err)
+			}
+
+		case tar.TypeReg:
+			if err := writeFile(target, tr, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+
+		case tar.TypeSymlink:
+			// A symlink target that is absolute or resolves outside the
+			// install directory would let the archive write anywhere on
+			// the machine the next time the link is followed.
+			resolved := filepath.Join(filepath.Dir(name), header.Linkname)
+			if filepath.IsAbs(header.Linkname) || !filepath.IsLocal(resolved) {
+				return fmt.Errorf("archive symlink %q escapes the install directory: %q", name, header.Linkname)
+			}
+
+			if err := os.Symlink(header.Linkname, target); err != nil && !os.IsExist(err) {
+				return fmt.Errorf("create symlink: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func extractZip(archive string, dest string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("open archive: %w", err)
+	}
+	defer zr.Close()
+
+	for _, file := range zr.File {
+		name := filepath.Clean(file.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive entry %q escapes the install directory", file.Name)
+		}
+
+		target := filepath.Join(dest, name)
+
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return fmt.Errorf("create directory: %w", err)
+			}
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("open entry: %w", err)
+		}
+
+		if err := writeFile(target, rc, file.Mode()); err != nil {
+			rc.Close()
+			return err
+		}
+		rc.Close()
+	}
+
+	return nil
+}
+
+// stripTopDir removes the top-level directory from an archive entry name,
+// mirroring how the SDK lays out the install directory. An entry that
+// escapes the install directory after stripping, such as top/../../x,
+// is rejected rather than extracted.
+func stripTopDir(name string) (string, error) {
+	original := name
+
+	if idx := strings.Index(name, "/"); idx != -1 {
+		name = name[idx+1:]
+	}
+
+	if name == "" {
+		return "", nil
+	}
+
+	name = filepath.Clean(name)
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("archive entry %q escapes the install directory", original)
+	}
+
+	return name, nil
+}
+
+func writeFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("create parent directory: %w", err)
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+// writeVersionFile stamps the install directory with the version metadata
+// the SDK reads, so its installer sees the verified build as already
+// installed and skips its own download.
+func writeVersionFile(dest string, version string, arch download.Arch, opSys download.OS, processor download.Processor) error {
+	tag := libs.VersionTag{
+		Version:   version,
+		Arch:      arch.String(),
+		OS:        opSys.String(),
+		Processor: processor.String(),
+	}
+
+	data, err := json.Marshal(tag)
+	if err != nil {
+		return fmt.Errorf("marshal version tag: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dest, "version.json"), data, 0o644); err != nil {
+		return fmt.Errorf("write version.json: %w", err)
+	}
+
+	return nil
+}
@@ -7,6 +7,23 @@
 //
 // Run the example like this from the root of the project:
 //	$ make example13-step1
+//
+// These environment variables control how the model is offloaded to the GPU:
+//
+//	GPU_LAYERS      // Number of layers to offload, 0 runs CPU only.
+//	MAIN_GPU        // Which GPU holds the model when there is more than one.
+//	FLASH_ATTENTION // "on" or "off", the default lets llama.cpp decide.
+//
+// The llama.cpp build is detected from the local platform: linux and
+// windows machines with an NVIDIA driver get the cuda build, everything
+// else gets cpu, and apple silicon gets metal. The linux-x64 and
+// windows-x64 release assets are verified against the sha256 digest GitHub
+// publishes before they are installed. These overrides select a different
+// release asset:
+//
+//	LLAMA_ARCH      // "amd64" or "arm64".
+//	LLAMA_OS        // "linux", "darwin", or "windows".
+//	LLAMA_PROCESSOR // "cpu", "cuda", "metal", "rocm", or "vulkan".
 
 package main
 
@@ -17,6 +34,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/ardanlabs/kronk/sdk/kronk"
@@ -28,6 +46,32 @@ import (
 
 const modelSource = "unsloth/gpt-oss-20b-Q8_0"
 
+// These settings let students with small GPUs offload part of the model
+// instead of running CPU only or OOMing on a full offload.
+var (
+	gpuLayers = -1
+	mainGPU   = -1
+	flashAttn = ""
+)
+
+func init() {
+	if v := os.Getenv("GPU_LAYERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			gpuLayers = n
+		}
+	}
+
+	if v := os.Getenv("MAIN_GPU"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			mainGPU = n
+		}
+	}
+
+	if v := os.Getenv("FLASH_ATTENTION"); v != "" {
+		flashAttn = v
+	}
+}
+
 func main() {
 	if err := run(); err != nil {
 		fmt.Printf("\nERROR: %s\n", err)
@@ -64,14 +108,35 @@ func installSystem() (models.Path, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Minute)
 	defer cancel()
 
-	libs, err := libs.New(
-		libs.WithVersion(defaults.LibVersion("")),
+	// Detect which llama.cpp build this machine needs: the LLAMA_ARCH,
+	// LLAMA_OS, and LLAMA_PROCESSOR environment variables override the
+	// detection, such as forcing the cpu build on linux.
+	arch, opSys, processor, err := detectTriple()
+	if err != nil {
+		return models.Path{}, err
+	}
+
+	version := defaults.LibVersion("")
+
+	lib, err := libs.New(
+		libs.WithVersion(version),
+		libs.WithArch(arch),
+		libs.WithOS(opSys),
+		libs.WithProcessor(processor),
 	)
 	if err != nil {
 		return models.Path{}, err
 	}
 
-	if _, err := libs.Download(ctx, kronk.FmtLogger); err != nil {
+	// The linux-x64 and windows-x64 builds are downloaded here so their
+	// sha256 can be checked against the digest GitHub publishes for the
+	// release asset. Other platforms fall through to the SDK's installer,
+	// which sees a verified install as already present and skips it.
+	if err := installVerified(ctx, lib, arch, opSys, processor, version); err != nil {
+		return models.Path{}, fmt.Errorf("unable to install llama.cpp: %w", err)
+	}
+
+	if _, err := lib.Download(ctx, kronk.FmtLogger); err != nil {
 		return models.Path{}, fmt.Errorf("unable to install llama.cpp: %w", err)
 	}
 
@@ -105,9 +170,26 @@ func newKronk(mp models.Path) (*kronk.Kronk, error) {
 		return nil, fmt.Errorf("unable to init kronk: %w", err)
 	}
 
-	krn, err := kronk.New(
+	opts := []model.Option{
 		model.WithModelFiles(mp.ModelFiles),
-	)
+	}
+
+	if gpuLayers >= 0 {
+		opts = append(opts, model.WithNGpuLayers(gpuLayers))
+	}
+
+	if mainGPU >= 0 {
+		opts = append(opts, model.WithMainGPU(mainGPU))
+	}
+
+	switch flashAttn {
+	case "on":
+		opts = append(opts, model.WithFlashAttention(model.FlashAttentionEnabled))
+	case "off":
+		opts = append(opts, model.WithFlashAttention(model.FlashAttentionDisabled))
+	}
+
+	krn, err := kronk.New(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create inference model: %w", err)
 	}
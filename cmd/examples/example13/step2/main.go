@@ -9,6 +9,7 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"os"
 	"time"
@@ -20,10 +21,14 @@ import (
 	"github.com/ardanlabs/kronk/sdk/tools/models"
 )
 
-const (
-	modelSource = "ggml-org/Qwen2.5-VL-3B-Instruct-Q8_0"
-	imageFile   = "zarf/samples/gallery/giraffe.jpg"
-)
+const modelSource = "ggml-org/Qwen2.5-VL-3B-Instruct-Q8_0"
+
+// The prompt references every image, so the model receives all of them in a
+// single user turn.
+var imageFiles = []string{
+	"zarf/samples/gallery/giraffe.jpg",
+	"zarf/samples/gallery/lighthouse.jpg",
+}
 
 func main() {
 	if err := run(); err != nil {
@@ -127,12 +132,12 @@ func newKronk(mp models.Path) (*kronk.Kronk, error) {
 }
 
 func vision(krn *kronk.Kronk) error {
-	question := "What is in this picture?"
+	question := "Compare these two pictures and describe what they have in common."
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	ch, err := performChat(ctx, krn, question, imageFile)
+	ch, err := performChat(ctx, krn, question, imageFiles)
 	if err != nil {
 		return fmt.Errorf("perform chat: %w", err)
 	}
@@ -144,16 +149,21 @@ func vision(krn *kronk.Kronk) error {
 	return nil
 }
 
-func performChat(ctx context.Context, krn *kronk.Kronk, question string, imageFile string) (<-chan model.ChatResponse, error) {
-	image, err := readImage(imageFile)
-	if err != nil {
-		return nil, fmt.Errorf("read image: %w", err)
+func performChat(ctx context.Context, krn *kronk.Kronk, question string, imageFiles []string) (<-chan model.ChatResponse, error) {
+	images := make([][]byte, len(imageFiles))
+
+	for i, imageFile := range imageFiles {
+		image, err := readImage(imageFile)
+		if err != nil {
+			return nil, fmt.Errorf("read image: %w", err)
+		}
+		images[i] = image
 	}
 
 	fmt.Printf("\nQuestion: %s\n", question)
 
 	d := model.D{
-		"messages":    model.RawMediaMessage(question, image),
+		"messages":    mediaMessage(question, images),
 		"temperature": 0.7,
 		"top_p":       0.9,
 		"top_k":       40,
@@ -213,6 +223,36 @@ loop:
 	return nil
 }
 
+// mediaMessage builds a single user turn with one image part per image
+// followed by the question. The image parts come first because multi-modal
+// templates were trained with the media tokens preceding the text.
+func mediaMessage(question string, images [][]byte) []model.D {
+	content := make([]model.D, 0, len(images)+1)
+
+	for _, image := range images {
+		encoded := base64.StdEncoding.EncodeToString(image)
+
+		content = append(content, model.D{
+			"type": "image_url",
+			"image_url": model.D{
+				"url": fmt.Sprintf("data:image/jpeg;base64,%s", encoded),
+			},
+		})
+	}
+
+	content = append(content, model.D{
+		"type": "text",
+		"text": question,
+	})
+
+	return []model.D{
+		{
+			"role":    "user",
+			"content": content,
+		},
+	}
+}
+
 func readImage(imageFile string) ([]byte, error) {
 	if _, err := os.Stat(imageFile); err != nil {
 		return nil, fmt.Errorf("error accessing file %q: %w", imageFile, err)
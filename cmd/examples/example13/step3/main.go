@@ -19,7 +19,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/ardanlabs/ai-training/cmd/examples/example13/duck"
+	"github.com/ardanlabs/ai-training/foundation/duckdbstore"
+	"github.com/ardanlabs/ai-training/foundation/embedding"
 	"github.com/ardanlabs/kronk/sdk/kronk"
 	"github.com/ardanlabs/kronk/sdk/kronk/model"
 	"github.com/ardanlabs/kronk/sdk/tools/defaults"
@@ -72,7 +73,9 @@ func run() error {
 
 	// -------------------------------------------------------------------------
 
-	db, err := duck.LoadData(dbPath, krnEmbed, dimentions, chunksFile)
+	emb := embedding.New(krnEmbed)
+
+	db, err := duckdbstore.LoadData(dbPath, emb, dimentions, chunksFile)
 	if err != nil {
 		return fmt.Errorf("error connecting to database: %w", err)
 	}
@@ -93,11 +96,11 @@ func run() error {
 
 		// ---------------------------------------------------------------------
 
-		docs, err := func() ([]duck.Document, error) {
+		docs, err := func() ([]duckdbstore.Document, error) {
 			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
 
-			docs, err := vectorSearch(ctx, krnEmbed, db, messages)
+			docs, err := vectorSearch(ctx, emb, db, messages)
 			if err != nil {
 				return nil, fmt.Errorf("unable to get vector search results: %w", err)
 			}
@@ -223,23 +226,17 @@ func userInput(messages []model.D) ([]model.D, error) {
 	return messages, nil
 }
 
-func vectorSearch(ctx context.Context, krnEmbed *kronk.Kronk, db *sql.DB, messages []model.D) ([]duck.Document, error) {
-	fmt.Print("\n--- Vector Search ---\n\n")
+func vectorSearch(ctx context.Context, emb *embedding.Model, db *sql.DB, messages []model.D) ([]duckdbstore.Document, error) {
+	fmt.Print("\n--- Hybrid Search ---\n\n")
 
-	d := model.D{
-		"input": messages[len(messages)-1]["content"].(string),
-	}
+	question := messages[len(messages)-1]["content"].(string)
 
-	resp, err := krnEmbed.Embeddings(ctx, d)
+	vec, err := emb.Embed(ctx, question)
 	if err != nil {
 		return nil, fmt.Errorf("embed: %w", err)
 	}
 
-	if len(resp.Data[0].Embedding) == 0 {
-		return nil, fmt.Errorf("empty query vector")
-	}
-
-	docs, err := duck.Search(db, resp.Data[0].Embedding, 5)
+	docs, err := duckdbstore.HybridSearch(db, vec, question, duckdbstore.Filter{}, 5)
 	if err != nil {
 		return nil, fmt.Errorf("error searching database: %w", err)
 	}
@@ -251,7 +248,7 @@ func vectorSearch(ctx context.Context, krnEmbed *kronk.Kronk, db *sql.DB, messag
 	return docs, nil
 }
 
-func addContextPrompt(documents []duck.Document, messages []model.D) []model.D {
+func addContextPrompt(documents []duckdbstore.Document, messages []model.D) []model.D {
 	const prompt = `
 		- Use the following Context to answer the user's question.
 		- If you don't know the answer, say that you don't know.
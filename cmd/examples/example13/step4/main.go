@@ -30,8 +30,9 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/ardanlabs/ai-training/cmd/examples/example13/duck"
 	"github.com/ardanlabs/ai-training/cmd/examples/example13/step4/website"
+	"github.com/ardanlabs/ai-training/foundation/duckdbstore"
+	"github.com/ardanlabs/ai-training/foundation/embedding"
 	"github.com/ardanlabs/kronk/sdk/kronk"
 	"github.com/ardanlabs/kronk/sdk/kronk/model"
 	"github.com/ardanlabs/kronk/sdk/tools/libs"
@@ -133,7 +134,7 @@ func run() error {
 
 	// -------------------------------------------------------------------------
 
-	db, err := duck.LoadData(dbPath, krnEmbed, dimentions, chunksFile)
+	db, err := duckdbstore.LoadData(dbPath, embedding.New(krnEmbed), dimentions, chunksFile)
 	if err != nil {
 		return fmt.Errorf("error connecting to database: %w", err)
 	}
@@ -0,0 +1,241 @@
+// This example shows you a complete RAG application that runs fully offline.
+// DuckDB stores the embeddings, and the yzma embedding and chat models run
+// in process, so no Docker services and no network access are required.
+//
+// # Running the example:
+//
+//	$ make example13-step5
+//
+// The models are loaded from gguf files specified with these environment
+// variables:
+//
+//	YZMA_LIB         // Directory holding the llama.cpp shared libraries.
+//	YZMA_CHAT_MODEL  // Path to the gguf model used for chat.
+//	YZMA_EMBED_MODEL // Path to the gguf model used for embeddings.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/duckdbstore"
+	"github.com/ardanlabs/ai-training/foundation/yzma"
+)
+
+const (
+	dbPath     = "zarf/data/duck-ex13-step5.db" // ":memory:"
+	chunksFile = "zarf/data/book.chunks"
+	dimentions = 768
+)
+
+var (
+	chatModelFile  = os.Getenv("YZMA_CHAT_MODEL")
+	embedModelFile = os.Getenv("YZMA_EMBED_MODEL")
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Printf("\nERROR: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if chatModelFile == "" || embedModelFile == "" {
+		return fmt.Errorf("no models configured, set YZMA_CHAT_MODEL and YZMA_EMBED_MODEL")
+	}
+
+	fmt.Println("\nLoading Embedding Model")
+
+	mdlEmbed, err := yzma.LoadModel(embedModelFile, yzma.WithEmbeddings())
+	if err != nil {
+		return fmt.Errorf("unable to load embedding model: %w", err)
+	}
+	defer mdlEmbed.Close()
+
+	fmt.Println("Loading Chat Model")
+
+	mdlChat, err := yzma.LoadModel(chatModelFile)
+	if err != nil {
+		return fmt.Errorf("unable to load chat model: %w", err)
+	}
+	defer mdlChat.Close()
+
+	// -------------------------------------------------------------------------
+
+	emb := embedder{mdl: mdlEmbed}
+
+	db, err := duckdbstore.LoadData(dbPath, emb, dimentions, chunksFile)
+	if err != nil {
+		return fmt.Errorf("error connecting to database: %w", err)
+	}
+	defer db.Close()
+
+	// -------------------------------------------------------------------------
+
+	var messages []yzma.Message
+
+	for {
+		messages, err = userInput(messages)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("unable to get user input: %w", err)
+		}
+
+		// ---------------------------------------------------------------------
+
+		docs, err := func() ([]duckdbstore.Document, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			return vectorSearch(ctx, emb, db, messages)
+		}()
+
+		if err != nil {
+			return fmt.Errorf("unable to get vector search results: %w", err)
+		}
+
+		// ---------------------------------------------------------------------
+
+		messages, err = func() ([]yzma.Message, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+			defer cancel()
+
+			return questionResponse(ctx, mdlChat, docs, messages)
+		}()
+
+		if err != nil {
+			return fmt.Errorf("unable to perform chat: %w", err)
+		}
+	}
+}
+
+func userInput(messages []yzma.Message) ([]yzma.Message, error) {
+	fmt.Print("\nUSER> ")
+
+	reader := bufio.NewReader(os.Stdin)
+
+	userInput, err := reader.ReadString('\n')
+	if err != nil {
+		return messages, fmt.Errorf("unable to read user input: %w", err)
+	}
+
+	if userInput == "quit\n" {
+		return nil, io.EOF
+	}
+
+	messages = append(messages, yzma.Message{Role: "user", Content: userInput})
+
+	return messages, nil
+}
+
+func vectorSearch(ctx context.Context, emb embedder, db *sql.DB, messages []yzma.Message) ([]duckdbstore.Document, error) {
+	fmt.Print("\n--- Hybrid Search ---\n\n")
+
+	question := messages[len(messages)-1].Content
+
+	vecs, err := emb.EmbedBatch(ctx, []string{question})
+	if err != nil {
+		return nil, fmt.Errorf("embed: %w", err)
+	}
+
+	docs, err := duckdbstore.HybridSearch(db, vecs[0], question, duckdbstore.Filter{}, 5)
+	if err != nil {
+		return nil, fmt.Errorf("error searching database: %w", err)
+	}
+
+	for _, doc := range docs {
+		fmt.Printf("Doc: %f: %s\n", doc.Similarity, strings.ReplaceAll(doc.Text, "\n", " ")[:100])
+	}
+
+	return docs, nil
+}
+
+func questionResponse(ctx context.Context, mdl *yzma.Model, documents []duckdbstore.Document, messages []yzma.Message) ([]yzma.Message, error) {
+	const prompt = `
+		- Use the following Context to answer the user's question.
+		- If you don't know the answer, say that you don't know.
+		- Responses should be properly formatted to be easily read.
+		- Share code if code is presented in the context.
+		- Do not include any additional information not present in the context.
+
+		Context:
+
+		%s
+
+		Question: %s
+		`
+
+	var count int
+	var content strings.Builder
+	for _, doc := range documents {
+		content.WriteString(doc.Text)
+		content.WriteString("\n")
+		count++
+		if count == 2 {
+			break
+		}
+	}
+
+	lastUserInput := messages[len(messages)-1].Content
+	finalPrompt := fmt.Sprintf(prompt, content.String(), lastUserInput)
+
+	messages = append(messages, yzma.Message{Role: "user", Content: finalPrompt})
+
+	// -------------------------------------------------------------------------
+
+	fmt.Print("\nMODEL> ")
+
+	var response strings.Builder
+
+	fn := func(piece string) error {
+		fmt.Print(piece)
+		response.WriteString(piece)
+		return nil
+	}
+
+	if err := mdl.ChatFunc(ctx, messages, fn); err != nil {
+		return messages, fmt.Errorf("chat: %w", err)
+	}
+
+	fmt.Println()
+
+	messages = append(messages, yzma.Message{Role: "assistant", Content: response.String()})
+
+	return messages, nil
+}
+
+// =============================================================================
+
+// embedder adapts the yzma embedding model to the duckdbstore.Embedder interface.
+type embedder struct {
+	mdl *yzma.Model
+}
+
+func (e embedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	vecs, err := e.mdl.EmbedBatch(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	f32s := make([][]float32, len(vecs))
+	for i, vec := range vecs {
+		f32 := make([]float32, len(vec))
+		for j, v := range vec {
+			f32[j] = float32(v)
+		}
+		f32s[i] = f32
+	}
+
+	return f32s, nil
+}
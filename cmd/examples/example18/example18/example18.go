@@ -0,0 +1,158 @@
+// Package example18 holds the docling chunking pipeline behind an
+// exported Run function configured through Config, the layout example06
+// established, so any document can be chunked and loaded into MongoDB.
+package example18
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/docling"
+	"github.com/ardanlabs/ai-training/foundation/mongodb"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const dimensions = 768
+
+// Config carries everything Run needs: the embedding endpoint, the
+// docling service, the document to chunk, and the database settings.
+type Config struct {
+	EmbedURL     string
+	EmbedModel   string
+	DoclingURL   string
+	DocumentPath string
+	MongoURI     string
+	MongoUser    string
+	MongoPass    string
+	DBName       string
+	ColName      string
+	Output       io.Writer
+}
+
+// =============================================================================
+
+type document struct {
+	ID        int       `bson:"id"`
+	Text      string    `bson:"text"`
+	Headings  []string  `bson:"headings"`
+	Embedding []float64 `bson:"embedding"`
+}
+
+// =============================================================================
+
+// Run chunks the document with docling and loads vector embeddings for
+// the chunks into MongoDB.
+func Run(ctx context.Context, cfg Config) error {
+	fmt.Fprintln(cfg.Output, "\nChunking Document With Docling")
+
+	doc := docling.New(cfg.DoclingURL)
+
+	chunks, err := doc.ChunkFileHybrid(ctx, cfg.DocumentPath, docling.NewOptions())
+	if err != nil {
+		return fmt.Errorf("chunk file: %w", err)
+	}
+
+	fmt.Fprintf(cfg.Output, "Received %d chunks\n", len(chunks))
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprintln(cfg.Output, "Initializing Database")
+
+	client, err := mongodb.Connect(ctx, cfg.MongoURI, cfg.MongoUser, cfg.MongoPass)
+	if err != nil {
+		return fmt.Errorf("mongodb.Connect: %w", err)
+	}
+
+	col, err := initDB(ctx, cfg, client)
+	if err != nil {
+		return fmt.Errorf("initDB: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	if err := insertChunks(ctx, cfg, col, chunks); err != nil {
+		return fmt.Errorf("insertChunks: %w", err)
+	}
+
+	fmt.Fprintln(cfg.Output, "\nThe document is now searchable in MongoDB.")
+
+	return nil
+}
+
+func insertChunks(ctx context.Context, cfg Config, col *mongo.Collection, chunks []docling.Chunk) error {
+	llm := client.NewLLM(cfg.EmbedURL, cfg.EmbedModel)
+
+	fmt.Fprint(cfg.Output, "\n")
+	fmt.Fprint(cfg.Output, "\033[s")
+
+	for counter, chunk := range chunks {
+		fmt.Fprint(cfg.Output, "\033[u\033[K")
+		fmt.Fprintf(cfg.Output, "Vectorizing Data: %d of %d", counter, len(chunks))
+
+		// Prefix the chunk with its headings so the embedding captures the
+		// context docling detected for the chunk.
+		text := chunk.Text
+		if len(chunk.Headings) > 0 {
+			text = strings.Join(chunk.Headings, " / ") + "\n" + text
+		}
+
+		vector, err := llm.EmbedText(ctx, text)
+		if err != nil {
+			return fmt.Errorf("embedding: %w", err)
+		}
+
+		doc := document{
+			ID:        counter,
+			Text:      chunk.Text,
+			Headings:  chunk.Headings,
+			Embedding: vector,
+		}
+
+		res := col.FindOne(ctx, bson.D{{Key: "id", Value: doc.ID}})
+		if res.Err() == nil {
+			continue
+		}
+
+		if _, err := col.InsertOne(ctx, doc); err != nil {
+			return fmt.Errorf("insert: %w", err)
+		}
+	}
+
+	fmt.Fprint(cfg.Output, "\n")
+
+	return nil
+}
+
+func initDB(ctx context.Context, cfg Config, client *mongo.Client) (*mongo.Collection, error) {
+	db := client.Database(cfg.DBName)
+
+	col, err := mongodb.CreateCollection(ctx, db, cfg.ColName)
+	if err != nil {
+		return nil, fmt.Errorf("createCollection: %w", err)
+	}
+
+	const indexName = "vector_index"
+
+	settings := mongodb.VectorIndexSettings{
+		NumDimensions: dimensions,
+		Path:          "embedding",
+		Similarity:    "cosine",
+	}
+
+	if err := mongodb.CreateVectorIndex(ctx, col, indexName, settings); err != nil {
+		return nil, fmt.Errorf("createVectorIndex: %w", err)
+	}
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	col.Indexes().CreateOne(ctx, indexModel)
+
+	return col, nil
+}
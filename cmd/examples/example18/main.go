@@ -0,0 +1,73 @@
+// This example shows you how to use the Docling chunking endpoint to chunk a
+// new document and pipe the chunks straight into vector embeddings and
+// MongoDB. This bypasses the custom cleaner program which is tailored to the
+// Ultimate Go Notebook.
+//
+// # Running the example:
+//
+//	$ make example18
+//
+// # This requires running the following commands:
+//
+//	$ make compose-up         // This starts MongoDB and OpenWebUI in docker compose.
+//	$ make kronk-up           // This starts the Kronk service.
+//	$ make docling-compose-up // This starts the Docling service.
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ardanlabs/ai-training/cmd/examples/example18/example18"
+)
+
+var (
+	url        = "http://localhost:11435/v1/embeddings"
+	model      = "embeddinggemma-300m-qat-Q8_0"
+	urlDocling = "http://localhost:5001"
+
+	documentPath = "zarf/samples/docs/dinner_menu.pdf"
+)
+
+func init() {
+	if v := os.Getenv("LLM_SERVER"); v != "" {
+		url = v
+	}
+
+	if v := os.Getenv("LLM_MODEL"); v != "" {
+		model = v
+	}
+
+	if v := os.Getenv("DOC_SERVER"); v != "" {
+		urlDocling = v
+	}
+
+	if v := os.Getenv("DOC_PATH"); v != "" {
+		documentPath = v
+	}
+}
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	cfg := example18.Config{
+		EmbedURL:     url,
+		EmbedModel:   model,
+		DoclingURL:   urlDocling,
+		DocumentPath: documentPath,
+		MongoURI:     "mongodb://localhost:27017",
+		MongoUser:    "ardan",
+		MongoPass:    "ardan",
+		DBName:       "example18",
+		ColName:      "documents",
+		Output:       os.Stdout,
+	}
+
+	if err := example18.Run(ctx, cfg); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,97 @@
+// Package example19 holds the grammar constrained generation flow behind
+// an exported Run function configured through Config, the layout
+// example06 established.
+package example19
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ardanlabs/ai-training/foundation/yzma"
+)
+
+// Config carries everything Run needs: the gguf model file to load and
+// the output destination.
+type Config struct {
+	ModelFile string
+	Output    io.Writer
+}
+
+// =============================================================================
+
+const prompt = `
+	Below is the text extracted from a single key frame of a training video.
+
+	Provide a detailed description of the frame in 100 words or less.
+
+	Also, classify this frame as: "source code", "diagram", "terminal", or
+	"other" depending on the content it features the most.
+
+	FRAME TEXT:
+
+	func main() {
+		ch := make(chan int)
+
+		go func() {
+			ch <- 42
+		}()
+
+		fmt.Println(<-ch)
+	}
+`
+
+type keyFrame struct {
+	Description    string `json:"description"`
+	Classification string `json:"classification"`
+}
+
+// =============================================================================
+
+// Run classifies the sample key frame with a schema derived grammar so
+// the model's JSON unmarshals on the first try.
+func Run(ctx context.Context, cfg Config) error {
+	if cfg.ModelFile == "" {
+		return fmt.Errorf("no model configured, set YZMA_CHAT_MODEL")
+	}
+
+	fmt.Fprintln(cfg.Output, "\nLoading Model")
+
+	// The grammar only accepts a JSON object with these two properties, so
+	// there is no repair code anywhere in this example.
+	mdl, err := yzma.LoadModel(cfg.ModelFile,
+		yzma.WithJSONSchema(
+			yzma.Property{Name: "description", Type: "string"},
+			yzma.Property{Name: "classification", Enum: []string{"source code", "diagram", "terminal", "other"}},
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("load model: %w", err)
+	}
+	defer mdl.Close()
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprintln(cfg.Output, "Classifying Key Frame")
+
+	response, err := mdl.Chat(ctx, []yzma.Message{
+		{Role: "user", Content: prompt},
+	})
+	if err != nil {
+		return fmt.Errorf("chat: %w", err)
+	}
+
+	fmt.Fprintf(cfg.Output, "\nModel Response:\n%s\n", response)
+
+	// -------------------------------------------------------------------------
+
+	var kf keyFrame
+	if err := json.Unmarshal([]byte(response), &kf); err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+
+	fmt.Fprintf(cfg.Output, "\nClassification: %s\nDescription: %s\n", kf.Classification, kf.Description)
+
+	return nil
+}
@@ -0,0 +1,40 @@
+// This example shows you how to use grammar constrained generation with a
+// local model to produce guaranteed valid JSON. The keyframe classification
+// prompt from example11 needs repair code to fix the JSON the model hands
+// back. With a GBNF grammar derived from the schema, the response always
+// unmarshals on the first try.
+//
+// # Running the example:
+//
+//	$ make example19
+//
+// The model is loaded from a gguf file specified with these environment
+// variables:
+//
+//	YZMA_LIB        // Directory holding the llama.cpp shared libraries.
+//	YZMA_CHAT_MODEL // Path to the gguf model to use.
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ardanlabs/ai-training/cmd/examples/example19/example19"
+)
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	cfg := example19.Config{
+		ModelFile: os.Getenv("YZMA_CHAT_MODEL"),
+		Output:    os.Stdout,
+	}
+
+	if err := example19.Run(ctx, cfg); err != nil {
+		log.Fatal(err)
+	}
+}
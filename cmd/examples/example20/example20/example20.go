@@ -0,0 +1,89 @@
+// Package example20 holds the LoRA adapter comparison behind an exported
+// Run function configured through Config, the layout example06
+// established.
+package example20
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ardanlabs/ai-training/foundation/yzma"
+)
+
+// Config carries everything Run needs: the base model, the adapter file
+// and strength, and the output destination.
+type Config struct {
+	ModelFile string
+	LoraFile  string
+	LoraScale float32
+	Output    io.Writer
+}
+
+// =============================================================================
+
+const prompt = "In two sentences, explain what a goroutine is."
+
+// Run asks the same question of the base model and the model with the
+// adapter applied so the fine-tune's effect is visible side by side.
+func Run(ctx context.Context, cfg Config) error {
+	if cfg.ModelFile == "" {
+		return fmt.Errorf("no model configured, set YZMA_CHAT_MODEL")
+	}
+
+	if cfg.LoraFile == "" {
+		return fmt.Errorf("no adapter configured, set YZMA_LORA")
+	}
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprintln(cfg.Output, "\nBase Model Response:")
+
+	base, err := yzma.LoadModel(cfg.ModelFile)
+	if err != nil {
+		return fmt.Errorf("load base model: %w", err)
+	}
+
+	err = chat(ctx, cfg, base)
+	base.Close()
+
+	if err != nil {
+		return err
+	}
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprintf(cfg.Output, "\nAdapter Response (scale %.2f):\n", cfg.LoraScale)
+
+	tuned, err := yzma.LoadModel(cfg.ModelFile, yzma.WithLoRA(cfg.LoraFile, cfg.LoraScale))
+	if err != nil {
+		return fmt.Errorf("load model with adapter: %w", err)
+	}
+	defer tuned.Close()
+
+	if err := chat(ctx, cfg, tuned); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cfg.Output)
+
+	return nil
+}
+
+// chat streams the model's response to the prompt.
+func chat(ctx context.Context, cfg Config, mdl *yzma.Model) error {
+	emit := func(piece string) error {
+		fmt.Fprint(cfg.Output, piece)
+		return nil
+	}
+
+	messages := []yzma.Message{
+		{Role: "user", Content: prompt},
+	}
+
+	if err := mdl.ChatFunc(ctx, messages, emit); err != nil {
+		return fmt.Errorf("chat: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+// This example shows you how to apply a LoRA adapter to a local model. The
+// same prompt runs twice, once against the base model and once with the
+// adapter applied, so you can see how a fine-tune changes the response. This
+// connects the class material to fine-tuning workflows without needing a
+// full fine-tuned model file.
+//
+// # Running the example:
+//
+//	$ make example20
+//
+// The model and adapter are loaded from gguf files specified with these
+// environment variables:
+//
+//	YZMA_LIB        // Directory holding the llama.cpp shared libraries.
+//	YZMA_CHAT_MODEL // Path to the base gguf model to use.
+//	YZMA_LORA       // Path to the LoRA adapter gguf file.
+//	YZMA_LORA_SCALE // Strength of the adapter, defaults to 1.0.
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/ai-training/cmd/examples/example20/example20"
+)
+
+var loraScale = float32(1.0)
+
+func init() {
+	if v := os.Getenv("YZMA_LORA_SCALE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 32); err == nil {
+			loraScale = float32(f)
+		}
+	}
+}
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	cfg := example20.Config{
+		ModelFile: os.Getenv("YZMA_CHAT_MODEL"),
+		LoraFile:  os.Getenv("YZMA_LORA"),
+		LoraScale: loraScale,
+		Output:    os.Stdout,
+	}
+
+	if err := example20.Run(ctx, cfg); err != nil {
+		log.Fatal(err)
+	}
+}
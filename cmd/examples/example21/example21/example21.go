@@ -0,0 +1,111 @@
+// Package example21 holds the Qdrant walkthrough behind an exported Run
+// function configured through Config, the layout example06 established.
+package example21
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/vectorstore"
+)
+
+const dimensions = 768
+
+// Config carries everything Run needs: the embedding endpoint, the
+// Qdrant server and collection, and the output destination.
+type Config struct {
+	EmbedURL   string
+	EmbedModel string
+	QdrantURL  string
+	Collection string
+	Output     io.Writer
+}
+
+// Run creates the collection, loads a small corpus with payload fields,
+// and runs scored searches with and without a payload filter.
+func Run(ctx context.Context, cfg Config) error {
+	llm := client.NewLLM(cfg.EmbedURL, cfg.EmbedModel)
+
+	// -------------------------------------------------------------------------
+	// Create the collection and make sure vectors are indexed.
+
+	store := vectorstore.NewQdrant(cfg.QdrantURL, cfg.Collection)
+
+	if err := store.EnsureIndex(ctx, dimensions); err != nil {
+		return fmt.Errorf("ensure index: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+	// Embed a small corpus and store it with payload fields.
+
+	corpus := []struct {
+		id       string
+		text     string
+		category string
+	}{
+		{"doc1", "the quick brown fox jumps over the lazy dog", "animals"},
+		{"doc2", "a fast auburn fox leaps above the sleepy canine", "animals"},
+		{"doc3", "the stock market rallied after the earnings report", "finance"},
+		{"doc4", "interest rates fell and bond prices moved higher", "finance"},
+	}
+
+	docs := make([]vectorstore.Document, len(corpus))
+
+	for i, item := range corpus {
+		embedding, err := llm.EmbedText(ctx, item.text)
+		if err != nil {
+			return fmt.Errorf("embed text: %w", err)
+		}
+
+		docs[i] = vectorstore.Document{
+			ID:        item.id,
+			Text:      item.text,
+			Embedding: embedding,
+			Metadata:  map[string]any{"category": item.category},
+		}
+	}
+
+	if err := store.Upsert(ctx, docs...); err != nil {
+		return fmt.Errorf("upsert: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+	// Run a scored search over the whole collection.
+
+	question := "tell me about foxes"
+
+	vector, err := llm.EmbedText(ctx, question)
+	if err != nil {
+		return fmt.Errorf("embed question: %w", err)
+	}
+
+	matches, err := store.Search(ctx, vector, 4)
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+
+	fmt.Fprintf(cfg.Output, "\nQuestion: %s\n\n", question)
+
+	for _, match := range matches {
+		fmt.Fprintf(cfg.Output, "%.4f  %-8s  %s\n", match.Score, match.Metadata["category"], match.Text)
+	}
+
+	// -------------------------------------------------------------------------
+	// Run the same search constrained to the finance payload so the model
+	// only sees documents from that category.
+
+	matches, err = store.SearchFiltered(ctx, vector, 4, map[string]any{"category": "finance"})
+	if err != nil {
+		return fmt.Errorf("search filtered: %w", err)
+	}
+
+	fmt.Fprintf(cfg.Output, "\nSame search filtered to category=finance:\n\n")
+
+	for _, match := range matches {
+		fmt.Fprintf(cfg.Output, "%.4f  %-8s  %s\n", match.Score, match.Metadata["category"], match.Text)
+	}
+
+	return nil
+}
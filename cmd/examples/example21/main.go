@@ -0,0 +1,58 @@
+// This example shows you how to use Qdrant, a purpose-built vector
+// database, through the vectorstore abstraction. It creates a collection,
+// inserts documents with payload fields, and runs scored searches both
+// with and without a payload filter.
+//
+// # Running the example:
+//
+//	$ make example21
+//
+// # This requires running the following commands:
+//
+//	$ make compose-up  // This starts Qdrant in docker compose.
+//	$ make kronk-up    // This starts the Kronk service.
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/ardanlabs/ai-training/cmd/examples/example21/example21"
+)
+
+var (
+	url   = "http://localhost:11435/v1/embeddings"
+	model = "embeddinggemma-300m-qat-Q8_0"
+
+	urlQdrant = "http://localhost:6333"
+)
+
+func init() {
+	if v := os.Getenv("LLM_SERVER"); v != "" {
+		url = v
+	}
+
+	if v := os.Getenv("LLM_MODEL"); v != "" {
+		model = v
+	}
+
+	if v := os.Getenv("QDRANT_SERVER"); v != "" {
+		urlQdrant = v
+	}
+}
+
+func main() {
+	cfg := example21.Config{
+		EmbedURL:   url,
+		EmbedModel: model,
+		QdrantURL:  urlQdrant,
+		Collection: "example21",
+		Output:     os.Stdout,
+	}
+
+	if err := example21.Run(context.Background(), cfg); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,169 @@
+// Package example22 holds the quantization comparison behind an exported
+// Run function configured through Config, the layout example06
+// established. The work is pure computation, so Run takes no context.
+package example22
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/vector"
+)
+
+const (
+	corpusSize = 20_000
+	dims       = 768
+	limit      = 10
+	queries    = 20
+)
+
+// Config carries everything Run needs: the output destination.
+type Config struct {
+	Output io.Writer
+}
+
+// Run scans a synthetic corpus three ways - float64, int8, and binary -
+// and compares the storage, scan latency, and recall of each variant.
+func Run(cfg Config) error {
+	fmt.Fprintf(cfg.Output, "\nBuilding a corpus of %d random vectors at %d dimensions\n", corpusSize, dims)
+
+	rng := rand.New(rand.NewSource(1))
+
+	corpus := make([][]float64, corpusSize)
+	for i := range corpus {
+		corpus[i] = randVector(rng)
+	}
+
+	// Quantize the whole corpus up front, which is how a store would
+	// hold it.
+	int8Corpus := make([][]int8, corpusSize)
+	binCorpus := make([][]uint64, corpusSize)
+
+	for i, vec := range corpus {
+		int8Corpus[i], _ = vector.QuantizeInt8(vec)
+		binCorpus[i] = vector.QuantizeBinary(vec)
+	}
+
+	// The queries are noisy copies of corpus vectors so real neighbors
+	// exist to find.
+	queryVecs := make([][]float64, queries)
+	for i := range queryVecs {
+		queryVecs[i] = perturb(rng, corpus[rng.Intn(corpusSize)])
+	}
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprintf(cfg.Output, "Scanning with %d queries\n\n", queries)
+
+	exact := make([]map[int]bool, queries)
+
+	var float64Time time.Duration
+
+	for i, query := range queryVecs {
+		start := time.Now()
+		neighbors := vector.TopK(query, corpus, limit)
+		float64Time += time.Since(start)
+
+		exact[i] = make(map[int]bool, limit)
+		for _, n := range neighbors {
+			exact[i][n.Index] = true
+		}
+	}
+
+	var int8Time time.Duration
+	var int8Hits int
+
+	for i, query := range queryVecs {
+		codes, _ := vector.QuantizeInt8(query)
+
+		start := time.Now()
+		scores := make([]vector.Neighbor, corpusSize)
+		for j, doc := range int8Corpus {
+			scores[j] = vector.Neighbor{Index: j, Score: vector.CosineSimilarityInt8(codes, doc)}
+		}
+		int8Time += time.Since(start)
+
+		int8Hits += overlap(scores, exact[i])
+	}
+
+	var binTime time.Duration
+	var binHits int
+
+	for i, query := range queryVecs {
+		words := vector.QuantizeBinary(query)
+
+		start := time.Now()
+		scores := make([]vector.Neighbor, corpusSize)
+		for j, doc := range binCorpus {
+			scores[j] = vector.Neighbor{Index: j, Score: vector.CosineSimilarityBinary(words, doc, dims)}
+		}
+		binTime += time.Since(start)
+
+		binHits += overlap(scores, exact[i])
+	}
+
+	// -------------------------------------------------------------------------
+
+	fmt.Fprintf(cfg.Output, "%-10s %12s %14s %10s\n", "VARIANT", "STORAGE", "SCAN/QUERY", "RECALL@10")
+	fmt.Fprintf(cfg.Output, "%-10s %12s %14s %10s\n", "float64", storage(corpusSize*dims*8), perQuery(float64Time), "1.00")
+	fmt.Fprintf(cfg.Output, "%-10s %12s %14s %10.2f\n", "int8", storage(corpusSize*dims), perQuery(int8Time), recall(int8Hits))
+	fmt.Fprintf(cfg.Output, "%-10s %12s %14s %10.2f\n", "binary", storage(corpusSize*dims/8), perQuery(binTime), recall(binHits))
+
+	fmt.Fprint(cfg.Output, "\nBinary scores are coarse: shortlist with them, rescore the survivors.\n\n")
+
+	return nil
+}
+
+// =============================================================================
+
+func randVector(rng *rand.Rand) []float64 {
+	v := make([]float64, dims)
+	for i := range v {
+		v[i] = rng.Float64()*2 - 1
+	}
+
+	return v
+}
+
+// perturb returns a noisy copy of the vector so it stays the query's
+// nearest neighborhood without being identical.
+func perturb(rng *rand.Rand, vec []float64) []float64 {
+	v := make([]float64, dims)
+	for i := range v {
+		v[i] = vec[i] + (rng.Float64()*2-1)*0.1
+	}
+
+	return v
+}
+
+// overlap counts how many of the top scored candidates the exact search
+// also returned.
+func overlap(scores []vector.Neighbor, exact map[int]bool) int {
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i].Score > scores[j].Score
+	})
+
+	var hits int
+	for _, n := range scores[:limit] {
+		if exact[n.Index] {
+			hits++
+		}
+	}
+
+	return hits
+}
+
+func recall(hits int) float64 {
+	return float64(hits) / float64(queries*limit)
+}
+
+func storage(bytes int) string {
+	return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+}
+
+func perQuery(total time.Duration) string {
+	return (total / queries).Round(time.Microsecond).String()
+}
@@ -0,0 +1,23 @@
+// This smoke test runs the quantization comparison end to end. The
+// corpus is synthetic, so no servers are needed and the pass always runs
+// under `go test ./cmd/...`.
+package example22
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSmoke(t *testing.T) {
+	var out strings.Builder
+
+	if err := Run(Config{Output: &out}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	for _, variant := range []string{"float64", "int8", "binary"} {
+		if !strings.Contains(out.String(), variant) {
+			t.Fatalf("missing the %s row in output:\n%s", variant, out.String())
+		}
+	}
+}
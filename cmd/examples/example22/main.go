@@ -0,0 +1,28 @@
+// This example shows the trade-offs of embedding compression. A synthetic
+// corpus is searched three ways - float64, int8 scalar quantization, and
+// binary quantization at one bit per dimension - and the storage, scan
+// latency, and recall against the exact results are compared. No model
+// server or database is needed.
+//
+// # Running the example:
+//
+//	$ make example22
+
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/ardanlabs/ai-training/cmd/examples/example22/example22"
+)
+
+func main() {
+	cfg := example22.Config{
+		Output: os.Stdout,
+	}
+
+	if err := example22.Run(cfg); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,37 @@
+// This program serves deterministic hash based embeddings on an
+// OpenAI-compatible endpoint. Point any example's LLM_EMBED_SERVER at it
+// to exercise vector store and search code without a model server.
+//
+// # Running the program:
+//
+//	$ make fakeembed
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/ardanlabs/ai-training/foundation/fakeembed"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	host := flag.String("host", "0.0.0.0:11440", "host and port to listen on")
+	dimensions := flag.Int("dimensions", 768, "dimensions of the embeddings")
+	flag.Parse()
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /v1/embeddings", fakeembed.Handler(*dimensions))
+
+	fmt.Printf("Serving %d dimension fake embeddings on http://%s/v1/embeddings\n", *dimensions, *host)
+
+	return http.ListenAndServe(*host, mux)
+}
@@ -0,0 +1,36 @@
+// This tool detects the memory and compute available on this machine and
+// prints the model configuration recommended for the examples, as env
+// vars ready to export.
+//
+// # Running the tool:
+//
+//	$ make hardware
+package main
+
+import (
+	"fmt"
+
+	"github.com/ardanlabs/ai-training/foundation/hardware"
+)
+
+func main() {
+	info := hardware.Detect()
+
+	const gb = float64(1 << 30)
+
+	fmt.Printf("CPUs: %d\n", info.CPUs)
+	fmt.Printf("RAM:  %.1f GB\n", float64(info.RAMBytes)/gb)
+
+	switch info.GPU {
+	case "":
+		fmt.Println("GPU:  none detected, models will run on CPU")
+	default:
+		fmt.Printf("GPU:  %s (%.1f GB)\n", info.GPU, float64(info.VRAMBytes)/gb)
+	}
+
+	rec := hardware.Recommend(info)
+
+	fmt.Printf("\nRecommendation: %s\n\n", rec.Reason)
+	fmt.Printf("export LLM_CHAT_MODEL=%s\n", rec.ChatModel)
+	fmt.Printf("export LLM_EMBED_MODEL=%s\n", rec.EmbedModel)
+}
@@ -0,0 +1,211 @@
+// This program drives concurrent chat or embedding load at a model
+// endpoint and reports throughput, time to first token, and latency
+// percentiles. Use it to size a shared class machine before everyone
+// shows up.
+//
+// # Running the program:
+//
+//	$ make llmbench
+//
+// # Optional environment overrides:
+//
+//	$ LLM_SERVER=http://localhost:11435/v1/chat/completions LLM_MODEL=Qwen3-8B-Q8_0 \
+//	  make llmbench
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+)
+
+var (
+	url   = "http://localhost:11435/v1/chat/completions"
+	model = "Qwen3-8B-Q8_0"
+)
+
+func init() {
+	if v := os.Getenv("LLM_SERVER"); v != "" {
+		url = v
+	}
+
+	if v := os.Getenv("LLM_MODEL"); v != "" {
+		model = v
+	}
+}
+
+// sample holds the measurements for a single request. The time to first
+// token only applies to streaming chat requests.
+type sample struct {
+	ttft  time.Duration
+	total time.Duration
+	err   error
+}
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	mode := flag.String("mode", "chat", "load type: chat or embed")
+	concurrency := flag.Int("c", 4, "number of concurrent workers")
+	requests := flag.Int("n", 20, "total number of requests")
+	prompt := flag.String("prompt", "In two sentences, explain what a goroutine is.", "prompt or input text to send")
+	flag.Parse()
+
+	if *requests < *concurrency {
+		return fmt.Errorf("need at least as many requests as workers")
+	}
+
+	fmt.Printf("Driving %d %s requests at %s with %d workers\n\n", *requests, *mode, url, *concurrency)
+
+	// -------------------------------------------------------------------------
+
+	llm := client.NewLLM(url, model)
+
+	work := make(chan int)
+	samples := make([]sample, *requests)
+
+	var wg sync.WaitGroup
+	wg.Add(*concurrency)
+
+	t := time.Now()
+
+	for range *concurrency {
+		go func() {
+			defer wg.Done()
+
+			for i := range work {
+				switch *mode {
+				case "embed":
+					samples[i] = embedOnce(llm, *prompt)
+				default:
+					samples[i] = chatOnce(llm, *prompt)
+				}
+			}
+		}()
+	}
+
+	for i := range *requests {
+		work <- i
+	}
+	close(work)
+
+	wg.Wait()
+
+	wall := time.Since(t)
+
+	// -------------------------------------------------------------------------
+
+	return report(samples, wall, *mode)
+}
+
+// chatOnce streams one chat completion and measures the time to first
+// token and the total latency.
+func chatOnce(llm *client.LLM, prompt string) sample {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	t := time.Now()
+
+	ch, err := llm.ChatCompletionsSSE(ctx, prompt)
+	if err != nil {
+		return sample{err: err}
+	}
+
+	var ttft time.Duration
+
+	for range ch {
+		if ttft == 0 {
+			ttft = time.Since(t)
+		}
+	}
+
+	return sample{
+		ttft:  ttft,
+		total: time.Since(t),
+	}
+}
+
+// embedOnce measures the latency of a single embedding request.
+func embedOnce(llm *client.LLM, input string) sample {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	t := time.Now()
+
+	if _, err := llm.EmbedText(ctx, input); err != nil {
+		return sample{err: err}
+	}
+
+	return sample{
+		total: time.Since(t),
+	}
+}
+
+// report prints the throughput and latency percentiles for the run.
+func report(samples []sample, wall time.Duration, mode string) error {
+	var good []sample
+	var failed int
+
+	for _, s := range samples {
+		if s.err != nil {
+			failed++
+			continue
+		}
+		good = append(good, s)
+	}
+
+	if len(good) == 0 {
+		return fmt.Errorf("every request failed: %w", samples[0].err)
+	}
+
+	fmt.Printf("Wall time  : %v\n", wall.Round(time.Millisecond))
+	fmt.Printf("Requests   : %d ok, %d failed\n", len(good), failed)
+	fmt.Printf("Throughput : %.2f req/sec\n", float64(len(good))/wall.Seconds())
+
+	totals := make([]time.Duration, len(good))
+	for i, s := range good {
+		totals[i] = s.total
+	}
+
+	fmt.Printf("\nLatency    : p50 %v  p90 %v  p99 %v\n",
+		percentile(totals, 50), percentile(totals, 90), percentile(totals, 99))
+
+	if mode == "chat" {
+		ttfts := make([]time.Duration, len(good))
+		for i, s := range good {
+			ttfts[i] = s.ttft
+		}
+
+		fmt.Printf("TTFT       : p50 %v  p90 %v  p99 %v\n",
+			percentile(ttfts, 50), percentile(ttfts, 90), percentile(ttfts, 99))
+	}
+
+	return nil
+}
+
+// percentile returns the pth percentile of the durations using the
+// nearest rank method.
+func percentile(durations []time.Duration, p int) time.Duration {
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+
+	sort.Slice(sorted, func(a, b int) bool {
+		return sorted[a] < sorted[b]
+	})
+
+	rank := (p*len(sorted) + 99) / 100
+
+	return sorted[min(max(rank-1, 0), len(sorted)-1)].Round(time.Millisecond)
+}
@@ -0,0 +1,250 @@
+// This program runs an MCP server that exposes the MongoDB vector search
+// used in examples 05, 06, and 11 as tools. Any MCP-capable host can use
+// tool_vector_search to query the Ultimate Go Notebook or the video RAG
+// corpus, and tool_get_document to fetch a specific document.
+//
+// # Running the example:
+//
+//	$ go run ./cmd/mcpvector
+//	$ go run ./cmd/mcpvector -stdio    // when launched by an MCP host
+//
+// # This requires running the following commands:
+//
+//	$ make compose-up  // This starts MongoDB.
+//	$ make kronk-up    // This starts the Kronk service.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/config"
+	"github.com/ardanlabs/ai-training/foundation/mongodb"
+)
+
+var mcpHost = "localhost:8083"
+
+func init() {
+	if v := os.Getenv("MCP_HOST"); v != "" {
+		mcpHost = v
+	}
+}
+
+func main() {
+	stdio := flag.Bool("stdio", false, "serve over stdio instead of SSE")
+	flag.Parse()
+
+	if err := run(*stdio); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(stdio bool) error {
+	ctx := context.Background()
+
+	dbClient, err := mongodb.Connect(ctx, config.MongoURI(), config.MongoUser(), config.MongoPass())
+	if err != nil {
+		return fmt.Errorf("mongodb.Connect: %w", err)
+	}
+
+	ts := toolServer{
+		llm:      client.NewLLM(config.EmbedURL(), config.EmbedModel()),
+		dbClient: dbClient,
+	}
+
+	server, paths := ts.newMCPServer()
+
+	if stdio {
+		return server.Run(ctx, &mcp.StdioTransport{})
+	}
+
+	log.Printf("Server: MCP vector search serving at %s", mcpHost)
+
+	f := func(request *http.Request) *mcp.Server {
+		if request.URL.Path == "/discovery" || paths[request.URL.Path] {
+			return server
+		}
+
+		return mcp.NewServer(&mcp.Implementation{Name: "unknown_tool", Version: "v1.0.0"}, nil)
+	}
+
+	handler := mcp.NewSSEHandler(f, &mcp.SSEOptions{})
+
+	return http.ListenAndServe(mcpHost, handler)
+}
+
+// =============================================================================
+
+// toolServer carries the model and database access the tools need.
+type toolServer struct {
+	llm      *client.LLM
+	dbClient *mongo.Client
+}
+
+// newMCPServer constructs the MCP server with the vector search tools
+// registered, returning the URL paths the tools are served on.
+func (ts toolServer) newMCPServer() (*mcp.Server, map[string]bool) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "vector_search", Version: "v1.0.0"}, nil)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "tool_vector_search", Description: "Search a MongoDB collection for documents similar to a query using vector search. Defaults to the Ultimate Go Notebook book collection."}, ts.VectorSearchHandler)
+	mcp.AddTool(server, &mcp.Tool{Name: "tool_get_document", Description: "Fetch a single document from a MongoDB collection by matching a field value."}, ts.GetDocumentHandler)
+
+	paths := map[string]bool{
+		"/tool_vector_search": true,
+		"/tool_get_document":  true,
+	}
+
+	return server, paths
+}
+
+// =============================================================================
+
+// VectorSearchParams represents the parameters for this tool call.
+type VectorSearchParams struct {
+	Query      string  `json:"query" jsonschema:"The text to search for"`
+	Database   *string `json:"database,omitempty" jsonschema:"The database to search. Defaults to example06 which holds the Ultimate Go Notebook. Use example11 for the video corpus."`
+	Collection *string `json:"collection,omitempty" jsonschema:"The collection to search. Defaults to book. Use trainingvideo for the video corpus."`
+	Index      *string `json:"index,omitempty" jsonschema:"The vector index to use. Defaults to vector_index. Use vector_embedding_index for the video corpus."`
+	Limit      *int    `json:"limit,omitempty" jsonschema:"The maximum number of documents to return. Defaults to 5."`
+}
+
+// VectorSearchHandler embeds the query and performs a vector search
+// against the specified collection.
+func (ts toolServer) VectorSearchHandler(ctx context.Context, req *mcp.CallToolRequest, params VectorSearchParams) (*mcp.CallToolResult, any, error) {
+	database := "example06"
+	if params.Database != nil && *params.Database != "" {
+		database = *params.Database
+	}
+
+	collection := "book"
+	if params.Collection != nil && *params.Collection != "" {
+		collection = *params.Collection
+	}
+
+	index := "vector_index"
+	if params.Index != nil && *params.Index != "" {
+		index = *params.Index
+	}
+
+	limit := 5
+	if params.Limit != nil && *params.Limit > 0 {
+		limit = *params.Limit
+	}
+
+	vector, err := ts.llm.EmbedText(ctx, params.Query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("embed query: %w", err)
+	}
+
+	col := ts.dbClient.Database(database).Collection(collection)
+
+	pipeline := mongo.Pipeline{
+		{{
+			Key: "$vectorSearch",
+			Value: bson.M{
+				"index":       index,
+				"exact":       true,
+				"path":        "embedding",
+				"queryVector": vector,
+				"limit":       limit,
+			}},
+		},
+		{{
+			Key: "$addFields",
+			Value: bson.M{
+				"score": bson.M{
+					"$meta": "vectorSearchScore",
+				},
+			}},
+		},
+		{{
+			Key:   "$unset",
+			Value: "embedding",
+		}},
+	}
+
+	cur, err := col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, nil, fmt.Errorf("aggregate: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var results []bson.M
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, nil, fmt.Errorf("all: %w", err)
+	}
+
+	return textResult(struct {
+		Status  string   `json:"status"`
+		Results []bson.M `json:"results"`
+	}{
+		Status:  "SUCCESS",
+		Results: results,
+	})
+}
+
+// =============================================================================
+
+// GetDocumentParams represents the parameters for this tool call.
+type GetDocumentParams struct {
+	Field      string  `json:"field" jsonschema:"The field to match on, such as id or file_name"`
+	Value      any     `json:"value" jsonschema:"The value the field must equal"`
+	Database   *string `json:"database,omitempty" jsonschema:"The database to read from. Defaults to example06."`
+	Collection *string `json:"collection,omitempty" jsonschema:"The collection to read from. Defaults to book."`
+}
+
+// GetDocumentHandler fetches a single document by matching a field value.
+func (ts toolServer) GetDocumentHandler(ctx context.Context, req *mcp.CallToolRequest, params GetDocumentParams) (*mcp.CallToolResult, any, error) {
+	database := "example06"
+	if params.Database != nil && *params.Database != "" {
+		database = *params.Database
+	}
+
+	collection := "book"
+	if params.Collection != nil && *params.Collection != "" {
+		collection = *params.Collection
+	}
+
+	col := ts.dbClient.Database(database).Collection(collection)
+
+	var doc bson.M
+	if err := col.FindOne(ctx, bson.M{params.Field: params.Value}).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("find document: %w", err)
+	}
+
+	delete(doc, "embedding")
+
+	return textResult(struct {
+		Status   string `json:"status"`
+		Document bson.M `json:"document"`
+	}{
+		Status:   "SUCCESS",
+		Document: doc,
+	})
+}
+
+// =============================================================================
+
+// textResult marshals the data into the text content shape MCP expects.
+func textResult(data any) (*mcp.CallToolResult, any, error) {
+	d, err := json.Marshal(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{
+			Text: string(d),
+		}},
+	}, nil, nil
+}
@@ -0,0 +1,95 @@
+// This program runs the prompt regression spec against a chat endpoint
+// and fails when any assertion fails, so prompt edits in the pipelines
+// can't silently regress. Point it at a real model before shipping a
+// prompt change, or at the fake server in CI.
+//
+// # Running the program:
+//
+//	$ make prompttest
+//
+// # Optional environment overrides:
+//
+//	$ LLM_SERVER=http://localhost:11435/v1/chat/completions LLM_MODEL=Qwen3-8B-Q8_0 \
+//	  make prompttest
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/prompttest"
+)
+
+var (
+	url   = "http://localhost:11435/v1/chat/completions"
+	model = "Qwen3-8B-Q8_0"
+)
+
+func init() {
+	if v := os.Getenv("LLM_SERVER"); v != "" {
+		url = v
+	}
+
+	if v := os.Getenv("LLM_MODEL"); v != "" {
+		model = v
+	}
+}
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	specFile := flag.String("spec", "zarf/data/prompttest.json", "spec file with the prompt cases")
+	flag.Parse()
+
+	cases, err := prompttest.Load(*specFile)
+	if err != nil {
+		return fmt.Errorf("load spec: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	llm := client.NewLLM(url, model)
+
+	chat := func(ctx context.Context, prompt string) (string, error) {
+		return llm.ChatCompletions(ctx, prompt)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	results, err := prompttest.Run(ctx, chat, cases)
+	if err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	for _, result := range results {
+		if len(result.Failures) == 0 {
+			fmt.Printf("PASS %s\n", result.Case.Name)
+			continue
+		}
+
+		fmt.Printf("FAIL %s\n", result.Case.Name)
+		for _, failure := range result.Failures {
+			fmt.Printf("     %s\n", failure)
+		}
+		fmt.Printf("     response: %.200s\n", result.Response)
+	}
+
+	if prompttest.Failed(results) {
+		return fmt.Errorf("prompt regressions detected")
+	}
+
+	return nil
+}
@@ -0,0 +1,435 @@
+// This program evaluates retrieval quality against a golden dataset of
+// questions and the book pages that answer them. It reports recall@k, MRR,
+// and nDCG@k for the configured search mode, so different chunking and
+// model choices can be compared with numbers instead of vibes.
+//
+// With the -judge flag the harness also generates an answer from the
+// retrieved context for each question and asks the chat model to grade it
+// for faithfulness and completeness against the golden answer.
+//
+// The golden set identifies relevant chunks by page number rather than
+// chunk id, so the same file keeps working when the chunking changes. Edit
+// zarf/data/rageval.json to curate the set for your own data.
+//
+// # Running the program:
+//
+//	$ make rageval
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/duckdbstore"
+	"github.com/ardanlabs/ai-training/foundation/embedding"
+	"github.com/ardanlabs/kronk/sdk/kronk"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/ardanlabs/kronk/sdk/tools/defaults"
+	"github.com/ardanlabs/kronk/sdk/tools/libs"
+	"github.com/ardanlabs/kronk/sdk/tools/models"
+)
+
+const (
+	modelEmbedSource = "ggml-org/embeddinggemma-300m-qat-Q8_0"
+	modelChatSource  = "unsloth/gpt-oss-20b-Q8_0"
+	dbPath           = "zarf/data/duck-rageval.db" // ":memory:"
+	chunksFile       = "zarf/data/book.chunks"
+	dimentions       = 768
+)
+
+// golden represents one entry in the golden dataset. A retrieved chunk is
+// relevant when its page appears in the Pages list. The answer is the
+// reference a judge model grades generated answers against.
+type golden struct {
+	Question string `json:"question"`
+	Pages    []int  `json:"pages"`
+	Answer   string `json:"answer"`
+}
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	goldenFile := flag.String("golden", "zarf/data/rageval.json", "golden dataset to evaluate against")
+	k := flag.Int("k", 5, "number of documents to retrieve per question")
+	search := flag.String("search", "hybrid", "search mode: vector, hybrid, or quantized")
+	judge := flag.Bool("judge", false, "grade generated answers with a judge model")
+	flag.Parse()
+
+	goldens, err := readGolden(*goldenFile)
+	if err != nil {
+		return fmt.Errorf("unable to read golden dataset: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	infoEmbed, infoChat, err := installSystem(*judge)
+	if err != nil {
+		return fmt.Errorf("unable to install system: %w", err)
+	}
+
+	krnEmbed, err := newKronk(infoEmbed)
+	if err != nil {
+		return fmt.Errorf("unable to create embedding model: %w", err)
+	}
+	defer func() {
+		fmt.Println("\nUnloading embedding model")
+		if err := krnEmbed.Unload(context.Background()); err != nil {
+			fmt.Printf("failed to unload embedding model: %v", err)
+		}
+	}()
+
+	var krnChat *kronk.Kronk
+
+	if *judge {
+		krnChat, err = newKronk(infoChat)
+		if err != nil {
+			return fmt.Errorf("unable to create chat model: %w", err)
+		}
+		defer func() {
+			fmt.Println("\nUnloading chat model")
+			if err := krnChat.Unload(context.Background()); err != nil {
+				fmt.Printf("failed to unload chat model: %v", err)
+			}
+		}()
+	}
+
+	// -------------------------------------------------------------------------
+
+	emb := embedding.New(krnEmbed)
+
+	db, err := duckdbstore.LoadData(dbPath, emb, dimentions, chunksFile)
+	if err != nil {
+		return fmt.Errorf("error connecting to database: %w", err)
+	}
+	defer db.Close()
+
+	// -------------------------------------------------------------------------
+
+	return evaluate(emb, krnChat, db, goldens, *search, *k)
+}
+
+// evaluate runs every golden question through the search and accumulates
+// the ranking metrics. When a chat model is provided it also generates an
+// answer from the retrieved context and has the model grade it against the
+// golden answer.
+func evaluate(emb *embedding.Model, krnChat *kronk.Kronk, db *sql.DB, goldens []golden, search string, k int) error {
+	fmt.Printf("\n--- Evaluating %d questions (%s search, k=%d) ---\n\n", len(goldens), search, k)
+
+	var sumRecall float64
+	var sumMRR float64
+	var sumNDCG float64
+	var sumFaith float64
+	var sumComplete float64
+	var graded int
+
+	for _, g := range goldens {
+		docs, err := func() ([]duckdbstore.Document, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			vec, err := emb.Embed(ctx, g.Question)
+			if err != nil {
+				return nil, fmt.Errorf("embed: %w", err)
+			}
+
+			switch search {
+			case "vector":
+				return duckdbstore.Search(db, vec, duckdbstore.Filter{}, k)
+			case "hybrid":
+				return duckdbstore.HybridSearch(db, vec, g.Question, duckdbstore.Filter{}, k)
+			case "quantized":
+				return duckdbstore.SearchQuantized(db, vec, duckdbstore.Filter{}, k)
+			default:
+				return nil, fmt.Errorf("unknown search mode: %q", search)
+			}
+		}()
+		if err != nil {
+			return fmt.Errorf("error searching: %w", err)
+		}
+
+		// ---------------------------------------------------------------------
+
+		relevant := make(map[int]bool)
+		for _, page := range g.Pages {
+			relevant[page] = true
+		}
+
+		recall, mrr, ndcg := score(docs, relevant, k)
+
+		sumRecall += recall
+		sumMRR += mrr
+		sumNDCG += ndcg
+
+		fmt.Printf("recall %.2f  mrr %.2f  ndcg %.2f : %s\n", recall, mrr, ndcg, g.Question)
+
+		// ---------------------------------------------------------------------
+
+		if krnChat == nil || g.Answer == "" {
+			continue
+		}
+
+		grade, err := gradeAnswer(krnChat, g, docs)
+		if err != nil {
+			return fmt.Errorf("error grading answer: %w", err)
+		}
+
+		sumFaith += float64(grade.Faithfulness)
+		sumComplete += float64(grade.Completeness)
+		graded++
+
+		fmt.Printf("  judge: faithfulness %d/5  completeness %d/5\n", grade.Faithfulness, grade.Completeness)
+	}
+
+	// -------------------------------------------------------------------------
+
+	n := float64(len(goldens))
+
+	fmt.Printf("\nRecall@%d : %.3f\n", k, sumRecall/n)
+	fmt.Printf("MRR      : %.3f\n", sumMRR/n)
+	fmt.Printf("nDCG@%d   : %.3f\n", k, sumNDCG/n)
+
+	if graded > 0 {
+		fmt.Printf("\nFaithfulness : %.2f/5 over %d answers\n", sumFaith/float64(graded), graded)
+		fmt.Printf("Completeness : %.2f/5 over %d answers\n", sumComplete/float64(graded), graded)
+	}
+
+	return nil
+}
+
+// grade holds the judge model's scores for a generated answer.
+type grade struct {
+	Faithfulness int `json:"faithfulness"`
+	Completeness int `json:"completeness"`
+}
+
+// gradeAnswer generates an answer from the retrieved context and asks the
+// model to grade it against the golden answer. The same model plays both
+// roles, which is fine for comparing configurations against each other
+// even if the absolute scores flatter the model a little.
+func gradeAnswer(krnChat *kronk.Kronk, g golden, docs []duckdbstore.Document) (grade, error) {
+	const answerPrompt = `
+		- Use only the following Context to answer the user's question.
+		- If the context doesn't provide enough information, say that you don't know.
+
+		Context:
+
+		%s
+
+		Question: %s
+		`
+
+	var content strings.Builder
+	for i, doc := range docs {
+		if i == 2 {
+			break
+		}
+		content.WriteString(doc.Text)
+		content.WriteString("\n")
+	}
+
+	answer, err := chat(krnChat, fmt.Sprintf(answerPrompt, content.String(), g.Question))
+	if err != nil {
+		return grade{}, fmt.Errorf("generate: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	const judgePrompt = `
+		You are grading a student answer against a reference answer.
+
+		Score two dimensions from 1 (worst) to 5 (best):
+		- faithfulness: the answer states nothing that contradicts the reference.
+		- completeness: the answer covers the important points of the reference.
+
+		Respond with only a JSON object: {"faithfulness": N, "completeness": N}
+
+		Question: %s
+
+		Reference answer: %s
+
+		Student answer: %s
+		`
+
+	verdict, err := chat(krnChat, fmt.Sprintf(judgePrompt, g.Question, g.Answer, answer))
+	if err != nil {
+		return grade{}, fmt.Errorf("judge: %w", err)
+	}
+
+	var gr grade
+	if err := json.Unmarshal([]byte(extractJSON(verdict)), &gr); err != nil {
+		return grade{}, fmt.Errorf("unmarshal verdict %q: %w", verdict, err)
+	}
+
+	if gr.Faithfulness < 1 || gr.Faithfulness > 5 || gr.Completeness < 1 || gr.Completeness > 5 {
+		return grade{}, fmt.Errorf("verdict out of range: %+v", gr)
+	}
+
+	return gr, nil
+}
+
+// chat sends a single user prompt to the model and returns the complete
+// response.
+func chat(krn *kronk.Kronk, prompt string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	d := model.D{
+		"messages": []model.D{
+			model.TextMessage("user", prompt),
+		},
+		"max_tokens":  1024,
+		"temperature": 0.0,
+	}
+
+	resp, err := krn.Chat(ctx, d)
+	if err != nil {
+		return "", fmt.Errorf("chat: %w", err)
+	}
+
+	if len(resp.Choices) == 0 || resp.Choices[0].Message == nil {
+		return "", fmt.Errorf("no response from model")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
+
+// extractJSON pulls the first JSON object out of the response in case the
+// model wrapped it in prose or a code fence.
+func extractJSON(response string) string {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+
+	if start == -1 || end == -1 || end < start {
+		return response
+	}
+
+	return response[start : end+1]
+}
+
+// score computes recall@k, MRR, and nDCG@k for a single question using
+// binary relevance on the page number.
+func score(docs []duckdbstore.Document, relevant map[int]bool, k int) (recall float64, mrr float64, ndcg float64) {
+	var found int
+	var dcg float64
+
+	seen := make(map[int]bool)
+
+	for rank, doc := range docs {
+		if !relevant[doc.Page] {
+			continue
+		}
+
+		if rank+1 <= k && mrr == 0 {
+			mrr = 1 / float64(rank+1)
+		}
+
+		dcg += 1 / math.Log2(float64(rank+2))
+
+		// Different chunks from the same relevant page only count once
+		// toward recall.
+		if !seen[doc.Page] {
+			seen[doc.Page] = true
+			found++
+		}
+	}
+
+	// The ideal ranking places a relevant document at every position up
+	// to the smaller of k and the number of relevant pages.
+	var idcg float64
+	for rank := range min(k, len(relevant)) {
+		idcg += 1 / math.Log2(float64(rank+2))
+	}
+
+	recall = float64(found) / float64(min(k, len(relevant)))
+	ndcg = dcg / idcg
+
+	return recall, mrr, ndcg
+}
+
+func readGolden(goldenFile string) ([]golden, error) {
+	data, err := os.ReadFile(goldenFile)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	var goldens []golden
+	if err := json.Unmarshal(data, &goldens); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	if len(goldens) == 0 {
+		return nil, fmt.Errorf("golden dataset is empty")
+	}
+
+	return goldens, nil
+}
+
+func installSystem(judge bool) (models.Path, models.Path, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Minute)
+	defer cancel()
+
+	libs, err := libs.New(
+		libs.WithVersion(defaults.LibVersion("")),
+	)
+	if err != nil {
+		return models.Path{}, models.Path{}, err
+	}
+
+	if _, err := libs.Download(ctx, kronk.FmtLogger); err != nil {
+		return models.Path{}, models.Path{}, fmt.Errorf("unable to install llama.cpp: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	mdls, err := models.New()
+	if err != nil {
+		return models.Path{}, models.Path{}, fmt.Errorf("unable to create models api: %w", err)
+	}
+
+	infoEmbed, err := mdls.Download(context.Background(), kronk.FmtLogger, modelEmbedSource)
+	if err != nil {
+		return models.Path{}, models.Path{}, fmt.Errorf("unable to install model: %w", err)
+	}
+
+	// The chat model is only needed when grading answers, so don't make
+	// everyone download it.
+	var infoChat models.Path
+
+	if judge {
+		infoChat, err = mdls.Download(context.Background(), kronk.FmtLogger, modelChatSource)
+		if err != nil {
+			return models.Path{}, models.Path{}, fmt.Errorf("unable to install model: %w", err)
+		}
+	}
+
+	return infoEmbed, infoChat, nil
+}
+
+func newKronk(mp models.Path) (*kronk.Kronk, error) {
+	if err := kronk.Init(); err != nil {
+		return nil, fmt.Errorf("unable to init kronk: %w", err)
+	}
+
+	krn, err := kronk.New(
+		model.WithModelFiles(mp.ModelFiles),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to create inference model: %w", err)
+	}
+
+	return krn, nil
+}
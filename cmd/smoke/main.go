@@ -0,0 +1,181 @@
+// This program runs a smoke pass over the repo's plumbing using the fake
+// servers and tiny inputs, no models, Mongo, or ffmpeg required. It wires
+// the OpenAI-compatible client against the fake embedding server, ranks a
+// tiny corpus with the vector math, chunks text with the tokenizer, and
+// runs the prompt assertions against a canned chat function. The test in
+// this directory runs the same pass, and the examples that can run
+// against the fakes carry their own smoke tests next to their Run
+// packages, so `go test ./cmd/...` exercises these flows in CI.
+//
+// # Running the program:
+//
+//	$ make smoke
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http/httptest"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/fakeembed"
+	"github.com/ardanlabs/ai-training/foundation/prompttest"
+	"github.com/ardanlabs/ai-training/foundation/tiktoken"
+	"github.com/ardanlabs/ai-training/foundation/vector"
+)
+
+const dimensions = 64
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := smokeEmbedding(ctx); err != nil {
+		return fmt.Errorf("embedding: %w", err)
+	}
+	fmt.Println("embedding  : ok")
+
+	if err := smokeSearch(); err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+	fmt.Println("search     : ok")
+
+	if err := smokeChunking(); err != nil {
+		return fmt.Errorf("chunking: %w", err)
+	}
+	fmt.Println("chunking   : ok")
+
+	if err := smokePrompts(ctx); err != nil {
+		return fmt.Errorf("prompts: %w", err)
+	}
+	fmt.Println("prompts    : ok")
+
+	return nil
+}
+
+// smokeEmbedding runs the http client against the fake embedding server
+// and checks the vectors are deterministic.
+func smokeEmbedding(ctx context.Context) error {
+	srv := httptest.NewServer(fakeembed.Handler(dimensions))
+	defer srv.Close()
+
+	llm := client.NewLLM(srv.URL, "fakeembed")
+
+	first, err := llm.EmbedText(ctx, "hello world")
+	if err != nil {
+		return fmt.Errorf("embed: %w", err)
+	}
+
+	if len(first) != dimensions {
+		return fmt.Errorf("expected %d dimensions, got %d", dimensions, len(first))
+	}
+
+	second, err := llm.EmbedText(ctx, "hello world")
+	if err != nil {
+		return fmt.Errorf("embed: %w", err)
+	}
+
+	for i := range first {
+		if first[i] != second[i] {
+			return fmt.Errorf("embeddings are not deterministic at index %d", i)
+		}
+	}
+
+	return nil
+}
+
+// smokeSearch ranks a tiny corpus with the vector math and checks an
+// exact match of the query text wins.
+func smokeSearch() error {
+	corpus := []string{
+		"the quick brown fox",
+		"a lazy dog sleeps",
+		"channels synchronize goroutines",
+	}
+
+	query := fakeembed.Vector(corpus[2], dimensions)
+
+	best := -1
+	bestScore := -2.0
+
+	for i, text := range corpus {
+		score := vector.CosineSimilarity(query, fakeembed.Vector(text, dimensions))
+		if score > bestScore {
+			best = i
+			bestScore = score
+		}
+	}
+
+	if best != 2 {
+		return fmt.Errorf("expected document 2 to rank first, got %d", best)
+	}
+
+	return nil
+}
+
+// smokeChunking splits a small text with the tokenizer and checks the
+// pieces respect the window.
+func smokeChunking() error {
+	tkn, err := tiktoken.NewTiktoken()
+	if err != nil {
+		return fmt.Errorf("new tiktoken: %w", err)
+	}
+
+	text := "Go is expressive, concise, clean, and efficient. " +
+		"Its concurrency mechanisms make it easy to write programs " +
+		"that get the most out of multicore and networked machines."
+
+	const window = 10
+
+	pieces := tkn.Split(text, window, 2)
+	if len(pieces) < 2 {
+		return fmt.Errorf("expected multiple chunks, got %d", len(pieces))
+	}
+
+	for i, piece := range pieces {
+		if count := tkn.TokenCount(piece); count > window {
+			return fmt.Errorf("chunk %d has %d tokens, window is %d", i, count, window)
+		}
+	}
+
+	return nil
+}
+
+// smokePrompts runs the prompt assertions against a canned chat function
+// standing in for a model.
+func smokePrompts(ctx context.Context) error {
+	cases := []prompttest.Case{
+		{
+			Name:   "classification",
+			Prompt: "classify this frame",
+			Assertions: []prompttest.Assertion{
+				{Type: "json"},
+				{Type: "json_field", Value: "classification"},
+			},
+		},
+	}
+
+	chat := func(ctx context.Context, prompt string) (string, error) {
+		return `{"description": "a code sample", "classification": "source code"}`, nil
+	}
+
+	results, err := prompttest.Run(ctx, chat, cases)
+	if err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+
+	if prompttest.Failed(results) {
+		return fmt.Errorf("assertions failed: %v", results[0].Failures)
+	}
+
+	return nil
+}
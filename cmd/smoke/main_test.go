@@ -0,0 +1,12 @@
+// This test runs the same smoke pass as the command, so CI gets coverage
+// of the offline flows from a plain `go test ./cmd/...`.
+
+package main
+
+import "testing"
+
+func TestSmoke(t *testing.T) {
+	if err := run(); err != nil {
+		t.Fatalf("smoke: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+// This program computes the perplexity of a local model over a text file.
+// Perplexity measures how well the model predicts the text, lower is
+// better. Run it against the same file with different quantization levels
+// of a model to quantify how much quality each level gives up.
+//
+// # Running the program:
+//
+//	$ make yzma-eval
+//
+// The model is loaded from a gguf file specified with these environment
+// variables:
+//
+//	YZMA_LIB        // Directory holding the llama.cpp shared libraries.
+//	YZMA_CHAT_MODEL // Path to the gguf model to score.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/yzma"
+)
+
+var modelFile = os.Getenv("YZMA_CHAT_MODEL")
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	file := flag.String("file", "zarf/data/book.txt", "text file to score")
+	window := flag.Uint("window", 2048, "tokens per scoring window")
+	flag.Parse()
+
+	if modelFile == "" {
+		return fmt.Errorf("no model configured, set YZMA_CHAT_MODEL")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	fmt.Printf("Loading Model: %s\n", modelFile)
+
+	mdl, err := yzma.LoadModel(modelFile,
+		yzma.WithContextSize(uint32(*window)),
+	)
+	if err != nil {
+		return fmt.Errorf("load model: %w", err)
+	}
+	defer mdl.Close()
+
+	// -------------------------------------------------------------------------
+
+	fmt.Printf("Scoring %s (%d bytes)\n", *file, len(data))
+	t := time.Now()
+
+	ppl, err := mdl.Perplexity(ctx, string(data))
+	if err != nil {
+		return fmt.Errorf("perplexity: %w", err)
+	}
+
+	fmt.Printf("\nPerplexity: %.4f (%v)\n", ppl, time.Since(t).Round(time.Second))
+
+	return nil
+}
@@ -0,0 +1,318 @@
+// This program exposes an OpenAI compatible API on localhost backed by the
+// in-process llama.cpp bindings. With this running, the other examples can
+// point their LLM_SERVER at it and run without Ollama or Kronk, which is a
+// big win on constrained classroom hardware.
+//
+// # Running the server:
+//
+//	$ make yzma-server
+//
+// The chat and embedding models are loaded from gguf files specified with
+// these environment variables:
+//
+//	YZMA_LIB         // Directory holding the llama.cpp shared libraries.
+//	YZMA_CHAT_MODEL  // Path to the gguf model served on /v1/chat/completions.
+//	YZMA_EMBED_MODEL // Path to the gguf model served on /v1/embeddings.
+//
+// An endpoint whose model isn't configured responds with 501.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/yzma"
+	"github.com/google/uuid"
+)
+
+var (
+	addr           = "localhost:11435"
+	chatModelFile  = os.Getenv("YZMA_CHAT_MODEL")
+	embedModelFile = os.Getenv("YZMA_EMBED_MODEL")
+)
+
+func init() {
+	if v := os.Getenv("SERVER_ADDR"); v != "" {
+		addr = v
+	}
+}
+
+// =============================================================================
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	srv := server{}
+
+	if chatModelFile != "" {
+		fmt.Printf("Loading chat model: %s\n", chatModelFile)
+
+		mdl, err := yzma.LoadModel(chatModelFile)
+		if err != nil {
+			return fmt.Errorf("load chat model: %w", err)
+		}
+		defer mdl.Close()
+
+		srv.chat = mdl
+	}
+
+	if embedModelFile != "" {
+		fmt.Printf("Loading embedding model: %s\n", embedModelFile)
+
+		mdl, err := yzma.LoadModel(embedModelFile, yzma.WithEmbeddings())
+		if err != nil {
+			return fmt.Errorf("load embedding model: %w", err)
+		}
+		defer mdl.Close()
+
+		srv.embed = mdl
+	}
+
+	if srv.chat == nil && srv.embed == nil {
+		return fmt.Errorf("no models configured, set YZMA_CHAT_MODEL and/or YZMA_EMBED_MODEL")
+	}
+
+	// -------------------------------------------------------------------------
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/chat/completions", srv.chatCompletions)
+	mux.HandleFunc("POST /v1/embeddings", srv.embeddings)
+
+	api := http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+
+	serverErrors := make(chan error, 1)
+
+	go func() {
+		fmt.Printf("Serving OpenAI compatible API at http://%s\n", addr)
+		serverErrors <- api.ListenAndServe()
+	}()
+
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErrors:
+		return fmt.Errorf("server error: %w", err)
+
+	case <-shutdown:
+		fmt.Println("\nShutting down")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := api.Shutdown(ctx); err != nil {
+			api.Close()
+			return fmt.Errorf("shutdown: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+type server struct {
+	chat  *yzma.Model
+	embed *yzma.Model
+}
+
+type chatRequest struct {
+	Model     string `json:"model"`
+	Stream    bool   `json:"stream"`
+	MaxTokens int    `json:"max_tokens"`
+	Messages  []struct {
+		Role    string `json:"role"`
+		Content any    `json:"content"`
+	} `json:"messages"`
+}
+
+func (srv server) chatCompletions(w http.ResponseWriter, r *http.Request) {
+	if srv.chat == nil {
+		writeError(w, http.StatusNotImplemented, "no chat model configured, set YZMA_CHAT_MODEL")
+		return
+	}
+
+	var req chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("decode request: %s", err))
+		return
+	}
+
+	messages := make([]yzma.Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		messages[i] = yzma.Message{
+			Role:    msg.Role,
+			Content: flattenContent(msg.Content),
+		}
+	}
+
+	if req.Stream {
+		srv.streamResponse(w, r, req.Model, messages)
+		return
+	}
+
+	content, err := srv.chat.Chat(r.Context(), messages)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("chat: %s", err))
+		return
+	}
+
+	resp := client.Chat{
+		ID:      uuid.NewString(),
+		Object:  "chat.completion",
+		Created: client.ToTime(time.Now().Unix()),
+		Model:   req.Model,
+		Choices: []client.ChatChoice{
+			{
+				Message: client.ChatMessage{
+					Role:    "assistant",
+					Content: content,
+				},
+			},
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (srv server) streamResponse(w http.ResponseWriter, r *http.Request, model string, messages []yzma.Message) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	ch, err := srv.chat.ChatStream(r.Context(), messages)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("chat stream: %s", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	id := uuid.NewString()
+
+	writeChunk := func(delta client.ChatDeltaSSE, finishReason string) {
+		chunk := client.ChatSSE{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: client.ToTime(time.Now().Unix()),
+			Model:   model,
+			Choices: []client.ChatChoiceSSE{
+				{
+					Delta:        delta,
+					FinishReason: finishReason,
+				},
+			},
+		}
+
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			return
+		}
+
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+	}
+
+	for piece := range ch {
+		writeChunk(client.ChatDeltaSSE{Role: "assistant", Content: piece}, "")
+	}
+
+	writeChunk(client.ChatDeltaSSE{Role: "assistant"}, "stop")
+
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
+
+type embedRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+func (srv server) embeddings(w http.ResponseWriter, r *http.Request) {
+	if srv.embed == nil {
+		writeError(w, http.StatusNotImplemented, "no embedding model configured, set YZMA_EMBED_MODEL")
+		return
+	}
+
+	var req embedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("decode request: %s", err))
+		return
+	}
+
+	vector, err := srv.embed.Embed(r.Context(), req.Input)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("embed: %s", err))
+		return
+	}
+
+	resp := client.Embedding{
+		ID:      uuid.NewString(),
+		Object:  "list",
+		Created: client.ToTime(time.Now().Unix()),
+		Model:   req.Model,
+		Data: []client.EmbeddingData{
+			{
+				Object:    "embedding",
+				Embedding: vector,
+			},
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// flattenContent pulls the text out of a message's content, which can be a
+// plain string or an array of typed content parts.
+func flattenContent(content any) string {
+	switch v := content.(type) {
+	case string:
+		return v
+
+	case []any:
+		var b strings.Builder
+		for _, item := range v {
+			part, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			if text, ok := part["text"].(string); ok {
+				b.WriteString(text)
+			}
+		}
+		return b.String()
+	}
+
+	return ""
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	var resp client.Error
+	resp.Err.Message = message
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,459 @@
+// Package agent provides the chat loop the agent examples kept
+// copy-pasting: prompting the user, streaming the model response,
+// filtering reasoning output, and executing tool calls. Examples
+// construct an Agent with their tools and system prompt and call Run.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/conversation"
+	"github.com/ardanlabs/ai-training/foundation/tiktoken"
+	"github.com/ardanlabs/ai-training/foundation/ui"
+)
+
+// Tool describes the features which all tools must implement.
+type Tool interface {
+	Call(ctx context.Context, toolCall client.ToolCall) client.D
+}
+
+// Agent represents a chat agent that can use tools to perform tasks.
+type Agent struct {
+	sseClient      *client.SSEClient[client.ChatSSE]
+	url            string
+	model          string
+	systemPrompt   string
+	getUserMessage func() (string, bool)
+	out            io.Writer
+	tools          map[string]Tool
+	toolDocuments  []client.D
+	contextWindow  int
+	callTimeout    time.Duration
+	toolWorkers    int
+	toolTimeout    time.Duration
+	policies       map[string]Policy
+	defaultPolicy  Policy
+	audit          io.Writer
+}
+
+// New constructs an agent for the specified model server and model.
+func New(url string, model string, options ...func(a *Agent)) *Agent {
+	a := Agent{
+		sseClient:     client.NewSSE[client.ChatSSE](client.StdoutLogger),
+		url:           url,
+		model:         model,
+		out:           os.Stdout,
+		tools:         map[string]Tool{},
+		contextWindow: 32 * 1024,
+		callTimeout:   5 * time.Minute,
+		toolWorkers:   4,
+	}
+
+	for _, option := range options {
+		option(&a)
+	}
+
+	return &a
+}
+
+// NewFromProvider constructs an agent against the named provider from
+// the client package's provider registry, resolving the chat endpoint
+// and auth headers so examples can switch providers by name without
+// touching the calling code.
+func NewFromProvider(provider string, model string, options ...func(a *Agent)) (*Agent, error) {
+	p, err := client.LookupProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	a := New(p.ChatURL, model, options...)
+
+	if p.Headers != nil {
+		a.sseClient = client.NewSSE[client.ChatSSE](client.StdoutLogger, client.WithHeaders(p.Headers))
+	}
+
+	return a, nil
+}
+
+// WithSystemPrompt sets the system prompt added to the start of the
+// conversation.
+func WithSystemPrompt(prompt string) func(a *Agent) {
+	return func(a *Agent) {
+		a.systemPrompt = prompt
+	}
+}
+
+// WithInput sets the function the agent calls when it is the user's
+// turn. Without it the agent returns after the first model response.
+func WithInput(getUserMessage func() (string, bool)) func(a *Agent) {
+	return func(a *Agent) {
+		a.getUserMessage = getUserMessage
+	}
+}
+
+// WithOutput sets the writer the agent prints the conversation to. It
+// defaults to stdout.
+func WithOutput(w io.Writer) func(a *Agent) {
+	return func(a *Agent) {
+		a.out = w
+	}
+}
+
+// WithTools sets the tools the agent can call and the tool documents
+// sent to the model. Examples build both with their Register functions.
+func WithTools(tools map[string]Tool, toolDocuments []client.D) func(a *Agent) {
+	return func(a *Agent) {
+		a.tools = tools
+		a.toolDocuments = toolDocuments
+	}
+}
+
+// WithContextWindow sets the context window used when reporting usage
+// and bounding the conversation history.
+func WithContextWindow(tokens int) func(a *Agent) {
+	return func(a *Agent) {
+		a.contextWindow = tokens
+	}
+}
+
+// WithToolWorkers sets how many tool calls can execute concurrently when
+// the model requests several in one turn. It defaults to 4.
+func WithToolWorkers(workers int) func(a *Agent) {
+	return func(a *Agent) {
+		a.toolWorkers = workers
+	}
+}
+
+// WithToolTimeout sets a deadline applied to each individual tool call.
+// A zero timeout lets a tool call run as long as the turn's context.
+func WithToolTimeout(timeout time.Duration) func(a *Agent) {
+	return func(a *Agent) {
+		a.toolTimeout = timeout
+	}
+}
+
+// =============================================================================
+
+// Run starts the agent and runs the chat loop until the input function
+// reports there is no more input.
+func (a *Agent) Run(ctx context.Context) error {
+	tke, err := tiktoken.NewTiktoken()
+	if err != nil {
+		return fmt.Errorf("new tiktoken: %w", err)
+	}
+
+	// The conversation package owns the history bookkeeping: the system
+	// prompt stays pinned and the oldest messages are evicted when the
+	// window fills. The tool schemas sent with every request count
+	// against the window just like the messages do.
+	conv := conversation.New(tke, a.contextWindow)
+	conv.SetReserved(tke.CountTools(a.toolDocuments))
+
+	if a.systemPrompt != "" {
+		conv.Pin(client.D{
+			"role":    "system",
+			"content": a.systemPrompt,
+		})
+	}
+
+	fmt.Fprintf(a.out, "\nChat with %s (use 'ctrl-c' to quit)\n", a.model)
+
+	needUserInput := true
+
+	for {
+		// ---------------------------------------------------------------------
+		// If we need user input, prompt the user for their next question or
+		// request. Otherwise, we are continuing a tool call.
+
+		if needUserInput {
+			if ok := a.promptUser(conv); !ok {
+				return nil
+			}
+		}
+
+		// ---------------------------------------------------------------------
+		// Make a streaming call to the model. This returns the assistant's
+		// text content and any tool calls requested by the model.
+
+		content, toolCalls, usage, err := a.streamModelTurn(ctx, conv.Messages())
+		if err != nil {
+			return err
+		}
+
+		a.printUsage(usage)
+
+		// ---------------------------------------------------------------------
+		// If the model requested tool calls, execute them and continue the
+		// loop without asking the user for input.
+
+		if len(toolCalls) > 0 {
+			a.appendToolCalls(conv, toolCalls)
+
+			results := a.callTools(ctx, toolCalls)
+			if len(results) > 0 {
+				conv.Add(results...)
+			}
+
+			needUserInput = false
+			continue
+		}
+
+		// ---------------------------------------------------------------------
+		// The model produced a text response. Add it to the conversation
+		// and go back to asking the user for input.
+
+		a.appendAssistant(conv, content)
+
+		if a.getUserMessage == nil {
+			return nil
+		}
+
+		needUserInput = true
+	}
+}
+
+// promptUser asks the user for input and adds it to the conversation.
+func (a *Agent) promptUser(conv *conversation.Conversation) bool {
+	if a.getUserMessage == nil {
+		return false
+	}
+
+	fmt.Fprint(a.out, "\u001b[94m\nYou\u001b[0m: ")
+
+	userInput, ok := a.getUserMessage()
+	if !ok {
+		return false
+	}
+
+	conv.Add(client.D{
+		"role":    "user",
+		"content": userInput,
+	})
+
+	return true
+}
+
+// streamModelTurn sends the conversation to the model and streams back the
+// response. It returns the assembled text content, any tool calls, and usage.
+func (a *Agent) streamModelTurn(ctx context.Context, conversation []client.D) (string, []client.ToolCall, *client.Usage, error) {
+	d := client.D{
+		"model":          a.model,
+		"messages":       conversation,
+		"temperature":    0.0,
+		"top_p":          0.1,
+		"top_k":          1,
+		"stream":         true,
+		"tools":          a.toolDocuments,
+		"tool_selection": "auto",
+	}
+
+	fmt.Fprintf(a.out, "\u001b[93m\n%s\u001b[0m: 0.000", a.model)
+
+	callCtx, cancelCall := context.WithTimeout(ctx, a.callTimeout)
+	defer cancelCall()
+
+	ch := make(chan client.ChatSSE, 100)
+
+	if err := a.sseClient.Do(callCtx, http.MethodPost, a.url, d, ch); err != nil {
+		return "", nil, nil, fmt.Errorf("error streaming: %w", err)
+	}
+
+	// Start the latency printer and ensure it stops.
+	stopPrinter := ui.Elapsed(ctx, a.out, a.model)
+	defer stopPrinter()
+
+	var chunks []string
+	var lastResp client.ChatSSE
+	reasonFirstChunk := true
+	reasonThinking := false  // GPT models provide a Reasoning field.
+	contentThinking := false // Other reasoning models use <think> tags.
+
+	// Tool call fragments can be spread across deltas, so fold every
+	// delta into the accumulator and assemble at the finish.
+	acc := client.NewToolCallAccumulator()
+
+	for resp := range ch {
+		lastResp = resp
+
+		if len(resp.Choices) == 0 {
+			continue
+		}
+
+		// On the first real chunk, stop the latency printer.
+		stopPrinter()
+
+		acc.Add(resp.Choices[0].Delta.ToolCalls)
+
+		switch resp.Choices[0].FinishReason {
+		case "error":
+			return "", nil, lastResp.Usage, fmt.Errorf("error from model: %s", resp.Choices[0].Delta.Content)
+
+		case "stop":
+			text := strings.TrimLeft(strings.Join(chunks, " "), "\n")
+			return text, nil, lastResp.Usage, nil
+
+		case "tool_calls":
+			toolCalls, err := acc.ToolCalls()
+			if err != nil {
+				return "", nil, lastResp.Usage, fmt.Errorf("assemble tool calls: %w", err)
+			}
+
+			return "", toolCalls, lastResp.Usage, nil
+
+		default:
+			delta := resp.Choices[0].Delta
+
+			switch {
+			case delta.Reasoning != "":
+				reasonThinking = true
+
+				if reasonFirstChunk {
+					reasonFirstChunk = false
+					fmt.Fprint(a.out, "\n")
+				}
+
+				fmt.Fprintf(a.out, "\u001b[91m%s\u001b[0m", delta.Reasoning)
+
+			// With some models a <think> tag could exist in the content
+			// to indicate reasoning. We need to filter that out and
+			// display it as a different color.
+			case delta.Content != "":
+				if reasonThinking {
+					reasonThinking = false
+					fmt.Fprint(a.out, "\n\n")
+				}
+
+				switch delta.Content {
+				case "<think>":
+					contentThinking = true
+					continue
+				case "</think>":
+					contentThinking = false
+					continue
+				}
+
+				if contentThinking {
+					fmt.Fprintf(a.out, "\u001b[91m%s\u001b[0m", delta.Content)
+					continue
+				}
+
+				fmt.Fprint(a.out, delta.Content)
+				chunks = append(chunks, delta.Content)
+			}
+		}
+	}
+
+	// Stream ended without an explicit finish reason.
+	text := strings.TrimLeft(strings.Join(chunks, " "), "\n")
+	return text, nil, lastResp.Usage, nil
+}
+
+// appendToolCalls adds the assistant's tool call request to the conversation.
+func (a *Agent) appendToolCalls(conv *conversation.Conversation, toolCalls []client.ToolCall) {
+	fmt.Fprint(a.out, "\n\n")
+
+	var toolCallDocs []client.D
+	for _, tc := range toolCalls {
+		argsJSON, _ := json.Marshal(tc.Function.Arguments)
+		toolCallDocs = append(toolCallDocs, client.D{
+			"id":   tc.ID,
+			"type": "function",
+			"function": client.D{
+				"name":      tc.Function.Name,
+				"arguments": string(argsJSON),
+			},
+		})
+	}
+
+	conv.Add(client.D{
+		"role":       "assistant",
+		"tool_calls": toolCallDocs,
+	})
+}
+
+// appendAssistant adds the assistant's text response to the conversation.
+func (a *Agent) appendAssistant(conv *conversation.Conversation, content string) {
+	if content == "" {
+		return
+	}
+
+	fmt.Fprint(a.out, "\n")
+	conv.Add(client.D{"role": "assistant", "content": content})
+}
+
+// printUsage displays token usage information after each model call.
+func (a *Agent) printUsage(usage *client.Usage) {
+	if usage == nil {
+		return
+	}
+
+	contextTokens := usage.PromptTokens + usage.CompletionTokens
+	percentage := (float64(contextTokens) / float64(a.contextWindow)) * 100
+	of := float32(a.contextWindow) / float32(1024)
+
+	fmt.Fprintf(a.out, "\n\n\u001b[90mInput: %d  Reasoning: %d  Completion: %d  Output: %d  Window: %d (%.0f%% of %.0fK) TPS: %.2f\u001b[0m",
+		usage.PromptTokens, usage.ReasoningTokens, usage.CompletionTokens, usage.OutputTokens, contextTokens, percentage, of, usage.TokensPerSecond)
+}
+
+// callTools looks up requested tools by name and executes them. Calls run
+// concurrently up to the worker limit, but results come back in the order
+// the model requested them so the conversation stays aligned.
+func (a *Agent) callTools(ctx context.Context, toolCalls []client.ToolCall) []client.D {
+	results := make([]client.D, len(toolCalls))
+
+	sem := make(chan struct{}, max(a.toolWorkers, 1))
+
+	var wg sync.WaitGroup
+
+	for i, toolCall := range toolCalls {
+		tool, exists := a.tools[toolCall.Function.Name]
+		if !exists {
+			continue
+		}
+
+		// Apply the tool's policy before launching anything so the
+		// confirmation prompts come one at a time.
+		if !a.checkPermission(toolCall) {
+			results[i] = deniedResponse(toolCall.ID)
+			continue
+		}
+
+		fmt.Fprintf(a.out, "\u001b[92m%s(%v)\u001b[0m:\n", toolCall.Function.Name, toolCall.Function.Arguments)
+
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			callCtx := ctx
+			if a.toolTimeout > 0 {
+				var cancelCall context.CancelFunc
+				callCtx, cancelCall = context.WithTimeout(ctx, a.toolTimeout)
+				defer cancelCall()
+			}
+
+			results[i] = tool.Call(callCtx, toolCall)
+		})
+	}
+
+	wg.Wait()
+
+	// Drop the slots for tools the agent doesn't know, keeping the
+	// request order for everything else.
+	resps := make([]client.D, 0, len(toolCalls))
+	for _, result := range results {
+		if result != nil {
+			resps = append(resps, result)
+		}
+	}
+
+	return resps
+}
@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+)
+
+// Policy controls whether the agent may execute a tool.
+type Policy int
+
+const (
+	// PolicyAllow executes the tool without asking. This is the default.
+	PolicyAllow Policy = iota
+
+	// PolicyAsk prompts the user for confirmation before each call.
+	PolicyAsk
+
+	// PolicyDeny refuses the call and tells the model it failed.
+	PolicyDeny
+)
+
+// WithPolicy sets the policy for the specified tool. Tools without a
+// policy use the default policy.
+func WithPolicy(toolName string, policy Policy) func(a *Agent) {
+	return func(a *Agent) {
+		if a.policies == nil {
+			a.policies = map[string]Policy{}
+		}
+		a.policies[toolName] = policy
+	}
+}
+
+// WithDefaultPolicy sets the policy applied to tools that don't have
+// one of their own.
+func WithDefaultPolicy(policy Policy) func(a *Agent) {
+	return func(a *Agent) {
+		a.defaultPolicy = policy
+	}
+}
+
+// WithAuditLog sets a writer that receives one line for every tool call
+// the agent approves: the time, the tool name, and the arguments.
+func WithAuditLog(w io.Writer) func(a *Agent) {
+	return func(a *Agent) {
+		a.audit = w
+	}
+}
+
+// =============================================================================
+
+// checkPermission applies the tool's policy, prompting the user when the
+// policy asks for it. It reports whether the call may proceed.
+func (a *Agent) checkPermission(toolCall client.ToolCall) bool {
+	policy, exists := a.policies[toolCall.Function.Name]
+	if !exists {
+		policy = a.defaultPolicy
+	}
+
+	switch policy {
+	case PolicyDeny:
+		fmt.Fprintf(a.out, "\u001b[95mdenied %s by policy\u001b[0m\n", toolCall.Function.Name)
+		return false
+
+	case PolicyAsk:
+		fmt.Fprintf(a.out, "\u001b[95mAllow %s(%v)? [y/N]\u001b[0m: ", toolCall.Function.Name, toolCall.Function.Arguments)
+
+		if a.getUserMessage == nil {
+			return false
+		}
+
+		answer, ok := a.getUserMessage()
+		if !ok {
+			return false
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+		default:
+			return false
+		}
+	}
+
+	a.auditApproved(toolCall)
+
+	return true
+}
+
+// auditApproved records an approved tool call in the audit log.
+func (a *Agent) auditApproved(toolCall client.ToolCall) {
+	if a.audit == nil {
+		return
+	}
+
+	args, _ := json.Marshal(toolCall.Function.Arguments)
+
+	fmt.Fprintf(a.audit, "%s %s %s\n", time.Now().UTC().Format(time.RFC3339), toolCall.Function.Name, args)
+}
+
+// deniedResponse builds the tool result the model sees when a call is
+// not permitted.
+func deniedResponse(toolID string) client.D {
+	return client.D{
+		"role":         "tool",
+		"tool_call_id": toolID,
+		"content":      `{"status": "FAILED", "data": "the user denied permission for this tool call"}`,
+	}
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Cache is an http.RoundTripper that stores each exchange on disk keyed
+// by a hash of the request. A repeated request is served from disk
+// without touching the server, so re-running a demo reproduces identical
+// output instantly and offline. Use it with WithClient:
+//
+//	cache := client.NewCache("zarf/data/llmcache", nil)
+//	llm := client.NewLLM(url, model, client.WithClient(cache.Client()))
+//
+// Unlike a cassette, the cache key ignores order: the same request always
+// gets the same response, which is what a deterministic rerun wants.
+type Cache struct {
+	dir  string
+	next http.RoundTripper
+}
+
+// NewCache constructs a cache that stores exchanges in the specified
+// directory. A nil next uses the default transport.
+func NewCache(dir string, next http.RoundTripper) (*Cache, error) {
+	if next == nil {
+		next = defaultClient.Transport
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("cache: create dir: %w", err)
+	}
+
+	return &Cache{
+		dir:  dir,
+		next: next,
+	}, nil
+}
+
+// Client returns an http client that caches through this cache.
+func (c *Cache) Client() *http.Client {
+	return &http.Client{
+		Transport: c,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (c *Cache) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("cache: read request: %w", err)
+		}
+
+		reqBody = data
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	hash := sha256.Sum256(fmt.Appendf(nil, "%s %s %s", req.Method, req.URL, reqBody))
+	file := filepath.Join(c.dir, hex.EncodeToString(hash[:])+".json")
+
+	if data, err := os.ReadFile(file); err == nil {
+		var interaction Interaction
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			return nil, fmt.Errorf("cache: unmarshal %s: %w", file, err)
+		}
+
+		resp := http.Response{
+			StatusCode: interaction.Status,
+			Status:     http.StatusText(interaction.Status),
+			Proto:      req.Proto,
+			ProtoMajor: req.ProtoMajor,
+			ProtoMinor: req.ProtoMinor,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader([]byte(interaction.Response))),
+			Request:    req,
+		}
+
+		return &resp, nil
+	}
+
+	resp, err := c.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("cache: read response: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	// Only successful exchanges are worth replaying.
+	if resp.StatusCode == http.StatusOK {
+		interaction := Interaction{
+			Method:   req.Method,
+			URL:      req.URL.String(),
+			Body:     string(reqBody),
+			Status:   resp.StatusCode,
+			Response: string(respBody),
+		}
+
+		data, err := json.MarshalIndent(interaction, "", "    ")
+		if err != nil {
+			return nil, fmt.Errorf("cache: marshal: %w", err)
+		}
+
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			return nil, fmt.Errorf("cache: write: %w", err)
+		}
+	}
+
+	return resp, nil
+}
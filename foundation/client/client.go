@@ -6,18 +6,25 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"math/rand/v2"
 	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/errs"
+	"github.com/ardanlabs/ai-training/foundation/logger"
 )
 
 const version = "v1.0.0"
 
-var ErrUnauthorized = errors.New("api understands the request but refuses to authorize it")
+// ErrUnauthorized aliases the shared sentinel so existing callers keep
+// working while classification lives in the errs package.
+var ErrUnauthorized = errs.ErrUnauthorized
 
 var defaultClient = http.Client{
 	Transport: &http.Transport{
@@ -41,19 +48,50 @@ type Logger func(context.Context, string, ...any)
 
 var NoopLogger = func(ctx context.Context, msg string, v ...any) {}
 
-var StdoutLogger = func(ctx context.Context, msg string, v ...any) {
-	s := fmt.Sprintf("msg: %s", msg)
-	for i := 0; i < len(v); i = i + 2 {
-		s = s + fmt.Sprintf(", %s: %v", v[i], v[i+1])
+// StdoutLogger writes the client's output through the foundation logger
+// so every call site gets levels, trace ids, and the JSON handler without
+// wiring a logger of its own. LLM_LOG_FORMAT=json switches to the JSON
+// handler and LLM_LOG_LEVEL (debug, info, warn, error) sets the minimum
+// level, so noisy runs can be silenced or post-processed.
+var StdoutLogger = defaultLogger()
+
+func defaultLogger() Logger {
+	format := logger.FormatText
+	if os.Getenv("LLM_LOG_FORMAT") == "json" {
+		format = logger.FormatJSON
+	}
+
+	level := slog.LevelInfo
+	if v := os.Getenv("LLM_LOG_LEVEL"); v != "" {
+		level.UnmarshalText([]byte(v))
+	}
+
+	return SlogLogger(logger.New(os.Stdout, format, level, "client"))
+}
+
+// SlogLogger adapts a slog logger to the client's Logger func, so the
+// client's output gets levels, structured attributes, and the JSON
+// handler along with the rest of the program.
+func SlogLogger(log *slog.Logger) Logger {
+	return func(ctx context.Context, msg string, v ...any) {
+		log.InfoContext(ctx, msg, v...)
 	}
-	log.Println(s)
 }
 
 // =============================================================================
 
 type Client struct {
-	log  Logger
-	http *http.Client
+	log     Logger
+	http    *http.Client
+	headers map[string]string
+	retry   retryPolicy
+}
+
+// retryPolicy controls how many times a request is attempted and how
+// long the first backoff lasts. The zero value means a single attempt.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
 }
 
 func New(log Logger, options ...func(cln *Client)) *Client {
@@ -75,6 +113,28 @@ func WithClient(http *http.Client) func(cln *Client) {
 	}
 }
 
+// WithRetry retries failed requests up to maxAttempts times with
+// exponential backoff and jitter starting from baseDelay. Only transient
+// failures are retried: timeouts, connection errors, and 429/5xx
+// responses, honoring a Retry-After header when the server sends one.
+// Long-running pipelines use this so a hiccup doesn't cost the run.
+func WithRetry(maxAttempts int, baseDelay time.Duration) func(cln *Client) {
+	return func(cln *Client) {
+		cln.retry = retryPolicy{
+			maxAttempts: maxAttempts,
+			baseDelay:   baseDelay,
+		}
+	}
+}
+
+// WithHeaders adds headers to every request, which is how the hosted
+// providers take their api keys.
+func WithHeaders(headers map[string]string) func(cln *Client) {
+	return func(cln *Client) {
+		cln.headers = headers
+	}
+}
+
 func (cln *Client) Do(ctx context.Context, method string, endpoint string, body D, v any) error {
 	resp, err := do(ctx, cln, method, endpoint, body)
 	if err != nil {
@@ -165,18 +225,56 @@ func (cln *SSEClient[T]) Do(ctx context.Context, method string, endpoint string,
 // =============================================================================
 
 func do(ctx context.Context, cln *Client, method string, endpoint string, body any) (*http.Response, error) {
+	maxAttempts := max(cln.retry.maxAttempts, 1)
+
+	var resp *http.Response
+	var retryAfter time.Duration
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		resp, retryAfter, err = doOnce(ctx, cln, method, endpoint, body)
+		if err == nil {
+			return resp, nil
+		}
+
+		if attempt >= maxAttempts || !errs.IsRetryable(err) {
+			return nil, err
+		}
+
+		// Exponential backoff with jitter, deferring to the server's
+		// Retry-After when it sent one.
+		delay := cln.retry.baseDelay << (attempt - 1)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		if delay > 0 {
+			delay += rand.N(delay / 2)
+		}
+
+		cln.log(ctx, "client: retrying", "attempt", attempt, "delay", delay, "error", err)
+
+		select {
+		case <-time.After(delay):
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func doOnce(ctx context.Context, cln *Client, method string, endpoint string, body any) (*http.Response, time.Duration, error) {
 	var statusCode int
 
 	var b bytes.Buffer
 	if body != nil {
 		if err := json.NewEncoder(&b).Encode(body); err != nil {
-			return nil, fmt.Errorf("encoding: error: %w", err)
+			return nil, 0, fmt.Errorf("encoding: error: %w", err)
 		}
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, endpoint, &b)
 	if err != nil {
-		return nil, fmt.Errorf("create request error: %w", err)
+		return nil, 0, fmt.Errorf("create request error: %w", err)
 	}
 
 	req.Header.Set("Cache-Control", "no-cache")
@@ -184,9 +282,13 @@ func do(ctx context.Context, cln *Client, method string, endpoint string, body a
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", fmt.Sprintf("Ardan Labs AI Training Sample Go Client: %s", version))
 
+	for k, v := range cln.headers {
+		req.Header.Set(k, v)
+	}
+
 	resp, err := cln.http.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("do: error: %w", err)
+		return nil, 0, fmt.Errorf("do: error: %w", err)
 	}
 
 	// Assign for logging the status code at the end of the function call.
@@ -194,25 +296,36 @@ func do(ctx context.Context, cln *Client, method string, endpoint string, body a
 
 	switch statusCode {
 	case http.StatusOK, http.StatusNoContent:
-		return resp, nil
+		return resp, 0, nil
 
 	default:
+		var retryAfter time.Duration
+		if v := resp.Header.Get("Retry-After"); v != "" {
+			if secs, err := strconv.Atoi(v); err == nil {
+				retryAfter = time.Duration(secs) * time.Second
+			}
+		}
+
 		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
 		if err != nil {
-			return nil, fmt.Errorf("readall: error: %w", err)
+			return nil, retryAfter, fmt.Errorf("readall: error: %w", err)
 		}
 
 		switch statusCode {
 		case http.StatusForbidden:
-			return nil, ErrUnauthorized
+			return nil, retryAfter, ErrUnauthorized
 
 		default:
 			var err Error
 			if err := json.Unmarshal(data, &err); err != nil {
-				return nil, fmt.Errorf("decoding: response: %s, error: %w ", string(data), err)
+				return nil, retryAfter, fmt.Errorf("decoding: response: %s, error: %w ", string(data), err)
 			}
 
-			return nil, fmt.Errorf("error: status: %d response: %s", statusCode, err.Err.Message)
+			// A typed error lets callers classify the failure with the
+			// errs package helpers instead of parsing the message.
+			return nil, retryAfter, &errs.StatusError{StatusCode: statusCode, Message: err.Err.Message}
 		}
 	}
 }
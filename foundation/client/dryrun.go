@@ -0,0 +1,93 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DryRun is an http.RoundTripper that never talks to a model server.
+// Each request's fully-assembled payload (prompt, options, tool schemas)
+// is written to w and a canned response comes back, so what the examples
+// actually send can be inspected before burning GPU time. Use it with
+// WithClient:
+//
+//	dry := client.NewDryRun(os.Stdout)
+//	llm := client.NewLLM(url, model, client.WithClient(dry.Client()))
+type DryRun struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+// NewDryRun constructs a dry-run transport that writes payloads to w.
+func NewDryRun(w io.Writer) *DryRun {
+	return &DryRun{
+		w: w,
+	}
+}
+
+// Client returns an http client that dry-runs through this transport.
+func (dr *DryRun) Client() *http.Client {
+	return &http.Client{
+		Transport: dr,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (dr *DryRun) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("dryrun: read request: %w", err)
+		}
+
+		reqBody = data
+	}
+
+	display := reqBody
+	var indented bytes.Buffer
+	if json.Indent(&indented, reqBody, "", "    ") == nil {
+		display = indented.Bytes()
+	}
+
+	dr.mu.Lock()
+	fmt.Fprintf(dr.w, "dry-run: %s %s\n%s\n", req.Method, req.URL, display)
+	dr.mu.Unlock()
+
+	contentType, respBody := cannedResponse(string(reqBody))
+
+	resp := http.Response{
+		StatusCode: http.StatusOK,
+		Status:     http.StatusText(http.StatusOK),
+		Proto:      req.Proto,
+		ProtoMajor: req.ProtoMajor,
+		ProtoMinor: req.ProtoMinor,
+		Header:     http.Header{"Content-Type": []string{contentType}},
+		Body:       io.NopCloser(strings.NewReader(respBody)),
+		Request:    req,
+	}
+
+	return &resp, nil
+}
+
+// cannedResponse shapes a placeholder response for the request: an event
+// stream for streaming chat, a vector for embeddings, and a single chat
+// completion otherwise.
+func cannedResponse(reqBody string) (contentType string, respBody string) {
+	switch {
+	case strings.Contains(reqBody, `"stream":true`):
+		return "text/event-stream", "data: {\"choices\":[{\"delta\":{\"content\":\"[dry-run response]\"}}],\"created\":1}\n\ndata: [DONE]\n\n"
+
+	case strings.Contains(reqBody, `"input"`):
+		return "application/json", `{"object":"list","data":[{"index":0,"object":"embedding","embedding":[0.1,0.2,0.3]}],"created":1}`
+
+	default:
+		return "application/json", `{"choices":[{"message":{"role":"assistant","content":"[dry-run response]"}}],"created":1}`
+	}
+}
@@ -15,10 +15,10 @@ type LLM struct {
 	model  string
 }
 
-func NewLLM(url string, model string) *LLM {
+func NewLLM(url string, model string, options ...func(cln *Client)) *LLM {
 	return &LLM{
-		cln:    New(StdoutLogger),
-		clnSSE: NewSSE[ChatSSE](StdoutLogger),
+		cln:    New(StdoutLogger, options...),
+		clnSSE: NewSSE[ChatSSE](StdoutLogger, options...),
 		url:    url,
 		model:  model,
 	}
@@ -65,6 +65,14 @@ func WithRepeatPenalty(penalty float32, lastN int) withParam {
 }
 
 func (llm *LLM) ChatCompletions(ctx context.Context, text string, options ...withParam) (string, error) {
+	text, _, err := llm.ChatCompletionsUsage(ctx, text, options...)
+	return text, err
+}
+
+// ChatCompletionsUsage is ChatCompletions plus the usage block from the
+// response, so callers can report real token counts instead of
+// estimating them with tiktoken.
+func (llm *LLM) ChatCompletionsUsage(ctx context.Context, text string, options ...withParam) (string, Usage, error) {
 	var images []D
 
 	params := D{
@@ -106,14 +114,14 @@ func (llm *LLM) ChatCompletions(ctx context.Context, text string, options ...wit
 
 	var chat Chat
 	if err := llm.cln.Do(ctx, http.MethodPost, llm.url, d, &chat); err != nil {
-		return "", fmt.Errorf("do: %w", err)
+		return "", Usage{}, fmt.Errorf("do: %w", err)
 	}
 
 	if len(chat.Choices) == 0 {
-		return "", fmt.Errorf("no response")
+		return "", chat.Usage, fmt.Errorf("no response")
 	}
 
-	return chat.Choices[0].Message.Content, nil
+	return chat.Choices[0].Message.Content, chat.Usage, nil
 }
 
 func (llm *LLM) ChatCompletionsSSE(ctx context.Context, content string) (chan ChatSSE, error) {
@@ -129,6 +137,11 @@ func (llm *LLM) ChatCompletionsSSE(ctx context.Context, content string) (chan Ch
 		"top_p":       0.5,
 		"top_k":       20,
 		"stream":      true,
+
+		// Ask the server to close the stream with a usage chunk.
+		"stream_options": D{
+			"include_usage": true,
+		},
 	}
 
 	ch := make(chan ChatSSE, 100)
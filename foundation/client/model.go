@@ -54,8 +54,9 @@ func (t Time) MarshalJSON() ([]byte, error) {
 // =============================================================================
 
 type Function struct {
-	Name      string
-	Arguments map[string]any
+	Name         string
+	Arguments    map[string]any
+	RawArguments string
 }
 
 func (f *Function) UnmarshalJSON(b []byte) error {
@@ -68,14 +69,18 @@ func (f *Function) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
+	// Streaming deltas can carry a fragment of the argument JSON, which
+	// won't parse on its own. Keep the raw text either way so the
+	// ToolCallAccumulator can reassemble the full document.
 	arguments := make(map[string]any)
 	if err := json.Unmarshal([]byte(tmp.RawArguments), &arguments); err != nil {
-		return err
+		arguments = nil
 	}
 
 	*f = Function{
-		Name:      tmp.Name,
-		Arguments: arguments,
+		Name:         tmp.Name,
+		Arguments:    arguments,
+		RawArguments: tmp.RawArguments,
 	}
 
 	return nil
@@ -110,6 +115,12 @@ type Usage struct {
 	TokensPerSecond  float64 `json:"tokens_per_second,omitempty"`
 }
 
+// Cost estimates the dollar cost of this usage given the provider's
+// per-million-token prices. Local models are free, hosted ones aren't.
+func (u Usage) Cost(promptPer1M float64, completionPer1M float64) float64 {
+	return float64(u.PromptTokens)/1e6*promptPer1M + float64(u.CompletionTokens)/1e6*completionPer1M
+}
+
 type ChatSSE struct {
 	ID      string          `json:"id"`
 	Object  string          `json:"object"`
@@ -137,6 +148,7 @@ type Chat struct {
 	Created Time         `json:"created"`
 	Model   string       `json:"model"`
 	Choices []ChatChoice `json:"choices"`
+	Usage   Usage        `json:"usage"`
 }
 
 // =============================================================================
@@ -153,4 +165,5 @@ type Embedding struct {
 	Created Time            `json:"created"`
 	Model   string          `json:"model"`
 	Data    []EmbeddingData `json:"data"`
+	Usage   Usage           `json:"usage"`
 }
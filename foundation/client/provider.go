@@ -0,0 +1,112 @@
+package client
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ardanlabs/ai-training/foundation/secrets"
+)
+
+// Provider describes a backend the LLM can talk to. The hosted providers
+// all expose OpenAI-compatible endpoints, so switching providers is a
+// matter of endpoint URLs and auth headers, not wire formats.
+type Provider struct {
+	Name     string
+	ChatURL  string
+	EmbedURL string // Empty when the provider has no embeddings api.
+	Headers  map[string]string
+}
+
+// LookupProvider returns the named provider with its endpoints and auth
+// resolved. Local providers honor AI_HOST; hosted providers take their
+// api keys from the secrets package (OPENAI_API_KEY, ANTHROPIC_API_KEY,
+// GEMINI_API_KEY).
+func LookupProvider(name string) (Provider, error) {
+	host := os.Getenv("AI_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+
+	switch name {
+	case "kronk":
+		return Provider{
+			Name:     name,
+			ChatURL:  fmt.Sprintf("http://%s:11435/v1/chat/completions", host),
+			EmbedURL: fmt.Sprintf("http://%s:11435/v1/embeddings", host),
+		}, nil
+
+	case "llamacpp":
+		return Provider{
+			Name:     name,
+			ChatURL:  fmt.Sprintf("http://%s:8080/v1/chat/completions", host),
+			EmbedURL: fmt.Sprintf("http://%s:8080/v1/embeddings", host),
+		}, nil
+
+	case "openai":
+		return Provider{
+			Name:     name,
+			ChatURL:  "https://api.openai.com/v1/chat/completions",
+			EmbedURL: "https://api.openai.com/v1/embeddings",
+			Headers: map[string]string{
+				"Authorization": "Bearer " + secrets.Get("OPENAI_API_KEY"),
+			},
+		}, nil
+
+	case "anthropic":
+		return Provider{
+			Name:    name,
+			ChatURL: "https://api.anthropic.com/v1/chat/completions",
+			Headers: map[string]string{
+				"x-api-key":         secrets.Get("ANTHROPIC_API_KEY"),
+				"anthropic-version": "2023-06-01",
+			},
+		}, nil
+
+	case "gemini":
+		return Provider{
+			Name:     name,
+			ChatURL:  "https://generativelanguage.googleapis.com/v1beta/openai/chat/completions",
+			EmbedURL: "https://generativelanguage.googleapis.com/v1beta/openai/embeddings",
+			Headers: map[string]string{
+				"Authorization": "Bearer " + secrets.Get("GEMINI_API_KEY"),
+			},
+		}, nil
+	}
+
+	return Provider{}, fmt.Errorf("unknown provider %q", name)
+}
+
+// NewLLMFromProvider constructs an LLM against the provider's chat
+// endpoint, so examples can switch providers by name without touching
+// the calling code.
+func NewLLMFromProvider(provider string, model string, options ...func(cln *Client)) (*LLM, error) {
+	p, err := LookupProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Headers != nil {
+		options = append(options, WithHeaders(p.Headers))
+	}
+
+	return NewLLM(p.ChatURL, model, options...), nil
+}
+
+// NewEmbedLLMFromProvider constructs an LLM against the provider's
+// embeddings endpoint.
+func NewEmbedLLMFromProvider(provider string, model string, options ...func(cln *Client)) (*LLM, error) {
+	p, err := LookupProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.EmbedURL == "" {
+		return nil, fmt.Errorf("provider %q does not support embeddings", provider)
+	}
+
+	if p.Headers != nil {
+		options = append(options, WithHeaders(p.Headers))
+	}
+
+	return NewLLM(p.EmbedURL, model, options...), nil
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Interaction represents a single recorded exchange with a model server.
+// SSE responses are recorded as the raw event stream.
+type Interaction struct {
+	Method   string `json:"method"`
+	URL      string `json:"url"`
+	Body     string `json:"body"`
+	Status   int    `json:"status"`
+	Response string `json:"response"`
+}
+
+// =============================================================================
+
+// Recorder is an http.RoundTripper that passes requests through to the
+// real server and captures each exchange into a cassette file. Use it with
+// WithClient to record chat, embedding, and SSE traffic, then replay the
+// cassette in tests with a Replayer so no model server is needed.
+type Recorder struct {
+	cassette     string
+	next         http.RoundTripper
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder constructs a recorder that writes to the specified cassette
+// file when Save is called. A nil next uses the default transport.
+func NewRecorder(cassette string, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = defaultClient.Transport
+	}
+
+	return &Recorder{
+		cassette: cassette,
+		next:     next,
+	}
+}
+
+// Client returns an http client that records through this recorder.
+func (rec *Recorder) Client() *http.Client {
+	return &http.Client{
+		Transport: rec,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (rec *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("recorder: read request: %w", err)
+		}
+
+		reqBody = data
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	resp, err := rec.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The whole response is captured before it's handed back, which is
+	// fine for recording since the replay is where latency matters.
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("recorder: read response: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.interactions = append(rec.interactions, Interaction{
+		Method:   req.Method,
+		URL:      req.URL.String(),
+		Body:     string(reqBody),
+		Status:   resp.StatusCode,
+		Response: string(respBody),
+	})
+
+	return resp, nil
+}
+
+// Save writes the captured interactions to the cassette file.
+func (rec *Recorder) Save() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	data, err := json.MarshalIndent(rec.interactions, "", "    ")
+	if err != nil {
+		return fmt.Errorf("recorder: marshal: %w", err)
+	}
+
+	if err := os.WriteFile(rec.cassette, data, 0644); err != nil {
+		return fmt.Errorf("recorder: write cassette: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// Replayer is an http.RoundTripper that serves the exchanges recorded in a
+// cassette file instead of talking to a server. Requests are matched on
+// method, url, and body, and a matching interaction is consumed so
+// repeated identical requests replay in the order they were recorded.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewReplayer constructs a replayer from the specified cassette file.
+func NewReplayer(cassette string) (*Replayer, error) {
+	data, err := os.ReadFile(cassette)
+	if err != nil {
+		return nil, fmt.Errorf("replayer: read cassette: %w", err)
+	}
+
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return nil, fmt.Errorf("replayer: unmarshal: %w", err)
+	}
+
+	return &Replayer{
+		interactions: interactions,
+	}, nil
+}
+
+// Client returns an http client that replays through this replayer.
+func (rep *Replayer) Client() *http.Client {
+	return &http.Client{
+		Transport: rep,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (rep *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("replayer: read request: %w", err)
+		}
+
+		reqBody = data
+	}
+
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+
+	for i, interaction := range rep.interactions {
+		if interaction.Method != req.Method || interaction.URL != req.URL.String() || interaction.Body != string(reqBody) {
+			continue
+		}
+
+		rep.interactions = append(rep.interactions[:i], rep.interactions[i+1:]...)
+
+		resp := http.Response{
+			StatusCode: interaction.Status,
+			Status:     http.StatusText(interaction.Status),
+			Proto:      req.Proto,
+			ProtoMajor: req.ProtoMajor,
+			ProtoMinor: req.ProtoMinor,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader([]byte(interaction.Response))),
+			Request:    req,
+		}
+
+		return &resp, nil
+	}
+
+	return nil, fmt.Errorf("replayer: no recorded interaction for %s %s", req.Method, req.URL)
+}
@@ -0,0 +1,116 @@
+// This test records chat, embedding, and SSE exchanges against a fake
+// model server into a cassette, then shuts the server down and replays
+// the cassette to prove the client sees the same responses without it.
+
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(fakeModelServer))
+
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+
+	// -------------------------------------------------------------------------
+
+	rec := NewRecorder(cassette, http.DefaultTransport)
+
+	llm := NewLLM(srv.URL, "test-model", WithClient(rec.Client()))
+
+	recorded, err := exercise(t, llm)
+	if err != nil {
+		t.Fatalf("recording: %v", err)
+	}
+
+	if err := rec.Save(); err != nil {
+		t.Fatalf("save cassette: %v", err)
+	}
+
+	srv.Close()
+
+	// -------------------------------------------------------------------------
+
+	rep, err := NewReplayer(cassette)
+	if err != nil {
+		t.Fatalf("new replayer: %v", err)
+	}
+
+	llm = NewLLM(srv.URL, "test-model", WithClient(rep.Client()))
+
+	replayed, err := exercise(t, llm)
+	if err != nil {
+		t.Fatalf("replaying: %v", err)
+	}
+
+	if replayed != recorded {
+		t.Fatalf("replayed %q, recorded %q", replayed, recorded)
+	}
+}
+
+// exercise runs a chat, an embedding, and an SSE stream through the client
+// and folds the results into one comparable string.
+func exercise(t *testing.T, llm *LLM) (string, error) {
+	chat, err := llm.ChatCompletions(t.Context(), "hello")
+	if err != nil {
+		return "", err
+	}
+
+	vector, err := llm.EmbedText(t.Context(), "hello")
+	if err != nil {
+		return "", err
+	}
+
+	ch, err := llm.ChatCompletionsSSE(t.Context(), "hello")
+	if err != nil {
+		return "", err
+	}
+
+	var stream strings.Builder
+	for resp := range ch {
+		stream.WriteString(resp.Choices[0].Delta.Content)
+	}
+
+	var b strings.Builder
+	b.WriteString(chat)
+	for _, v := range vector {
+		b.WriteString(string(rune('0' + int(v*10))))
+	}
+	b.WriteString(stream.String())
+
+	return b.String(), nil
+}
+
+// fakeModelServer stands in for an OpenAI-compatible endpoint. It keys off
+// the request body since the client sends everything to one url.
+func fakeModelServer(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body := string(data)
+
+	switch {
+	case strings.Contains(body, `"stream":true`):
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"choices":[{"delta":{"content":"str"}}]}` + "\n\n"))
+		w.Write([]byte(`data: {"choices":[{"delta":{"content":"eam"}}]}` + "\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+
+	case strings.Contains(body, `"input"`):
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"created":1,"data":[{"index":0,"embedding":[0.1,0.2,0.3]}]}`))
+
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"created":1,"choices":[{"message":{"role":"assistant","content":"canned"}}]}`))
+	}
+}
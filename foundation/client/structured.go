@@ -0,0 +1,250 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+)
+
+// ChatCompletionsJSON asks the model for a response matching the
+// specified JSON schema and unmarshals it into out. The request carries
+// response_format/json_schema for servers that support constrained
+// output, the response is validated against the schema, and an invalid
+// response is retried with a corrective prompt. This replaces the
+// hand-rolled JSON repair the vision examples grew. The same options as
+// ChatCompletions apply, so images can ride along with the prompt.
+//
+// The schema is a standard JSON schema document expressed as a D:
+//
+//	schema := client.D{
+//		"type": "object",
+//		"properties": client.D{
+//			"classification": client.D{"type": "string"},
+//		},
+//		"required": []string{"classification"},
+//	}
+func (llm *LLM) ChatCompletionsJSON(ctx context.Context, prompt string, schema D, out any, options ...withParam) error {
+	const maxAttempts = 3
+
+	var images []D
+
+	params := D{
+		"temperature": 0.0,
+	}
+
+	var repeatParams D
+
+	for _, opt := range options {
+		switch opt.typ {
+		case "image":
+			images = append(images, opt.d)
+		case "params":
+			params = opt.d
+		case "repeat":
+			repeatParams = opt.d
+		}
+	}
+
+	var content any = prompt
+	if len(images) > 0 {
+		content = append([]D{{"type": "text", "text": prompt}}, images...)
+	}
+
+	userMessage := D{
+		"role":    "user",
+		"content": content,
+	}
+
+	d := D{
+		"model":    llm.model,
+		"messages": []D{userMessage},
+		"response_format": D{
+			"type": "json_schema",
+			"json_schema": D{
+				"name":   "response",
+				"strict": true,
+				"schema": schema,
+			},
+		},
+	}
+
+	maps.Copy(d, params)
+	maps.Copy(d, repeatParams)
+
+	var lastErr error
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var chat Chat
+		if err := llm.cln.Do(ctx, http.MethodPost, llm.url, d, &chat); err != nil {
+			return fmt.Errorf("do: %w", err)
+		}
+
+		if len(chat.Choices) == 0 {
+			return fmt.Errorf("no response")
+		}
+
+		content := extractJSON(chat.Choices[0].Message.Content)
+
+		lastErr = validateJSON(content, schema, out)
+		if lastErr == nil {
+			return nil
+		}
+
+		// Feed the failure back so the model can correct itself.
+		d["messages"] = []D{
+			userMessage,
+			{
+				"role":    "assistant",
+				"content": chat.Choices[0].Message.Content,
+			},
+			{
+				"role":    "user",
+				"content": fmt.Sprintf("That response was invalid: %s. Respond again with only valid JSON matching the schema.", lastErr),
+			},
+		}
+	}
+
+	return fmt.Errorf("invalid json after %d attempts: %w", maxAttempts, lastErr)
+}
+
+// validateJSON checks the content against the schema and unmarshals it
+// into out when it conforms.
+func validateJSON(content string, schema D, out any) error {
+	var value any
+	if err := json.Unmarshal([]byte(content), &value); err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+
+	if err := validateSchema(value, map[string]any(schema), ""); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(content), out); err != nil {
+		return fmt.Errorf("unmarshal into target: %w", err)
+	}
+
+	return nil
+}
+
+// validateSchema checks a decoded value against the subset of JSON
+// schema the examples use: type, properties, required, and items.
+func validateSchema(value any, schema map[string]any, path string) error {
+	if path == "" {
+		path = "$"
+	}
+
+	typ, _ := schema["type"].(string)
+
+	switch typ {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", path, value)
+		}
+
+		for _, name := range requiredFields(schema["required"]) {
+			if _, exists := obj[name]; !exists {
+				return fmt.Errorf("%s: missing required field %q", path, name)
+			}
+		}
+
+		if properties, ok := toMap(schema["properties"]); ok {
+			for name, sub := range properties {
+				field, exists := obj[name]
+				if !exists {
+					continue
+				}
+
+				subSchema, ok := toMap(sub)
+				if !ok {
+					continue
+				}
+
+				if err := validateSchema(field, subSchema, path+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", path, value)
+		}
+
+		if items, ok := toMap(schema["items"]); ok {
+			for i, elem := range arr {
+				if err := validateSchema(elem, items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: expected string, got %T", path, value)
+		}
+
+	case "number", "integer":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: expected number, got %T", path, value)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", path, value)
+		}
+	}
+
+	return nil
+}
+
+// requiredFields normalizes the two list shapes the required clause
+// shows up as: []string when written in Go, []any when it came through
+// a JSON decode.
+func requiredFields(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+
+	case []any:
+		names := make([]string, 0, len(list))
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+
+	return nil
+}
+
+// toMap normalizes the two map shapes a schema node shows up as: D when
+// written in Go, map[string]any when it came through a JSON decode.
+func toMap(v any) (map[string]any, bool) {
+	switch m := v.(type) {
+	case D:
+		return m, true
+	case map[string]any:
+		return m, true
+	}
+
+	return nil, false
+}
+
+// extractJSON pulls the JSON document out of a response that may be
+// wrapped in markdown fences or prose.
+func extractJSON(content string) string {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+
+	if start == -1 || end == -1 || end < start {
+		return content
+	}
+
+	return content[start : end+1]
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateJSON(t *testing.T) {
+	schema := D{
+		"type": "object",
+		"properties": D{
+			"description":    D{"type": "string"},
+			"classification": D{"type": "string"},
+		},
+		"required": []string{"description", "classification"},
+	}
+
+	var out struct {
+		Description    string `json:"description"`
+		Classification string `json:"classification"`
+	}
+
+	content := `{"description": "a code sample", "classification": "source code"}`
+
+	if err := validateJSON(content, schema, &out); err != nil {
+		t.Fatalf("valid document rejected: %v", err)
+	}
+
+	if out.Classification != "source code" {
+		t.Fatalf("expected classification to unmarshal, got %q", out.Classification)
+	}
+
+	// A document missing a required field has to be rejected even though
+	// the schema spells required as []string.
+	err := validateJSON(`{"description": "a code sample"}`, schema, &out)
+	if err == nil {
+		t.Fatal("expected a missing required field to be rejected")
+	}
+
+	if !strings.Contains(err.Error(), "classification") {
+		t.Fatalf("expected the error to name the missing field, got: %v", err)
+	}
+
+	// The required clause also shows up as []any after a JSON decode.
+	schema["required"] = []any{"classification"}
+
+	if err := validateJSON(`{"description": "a code sample"}`, schema, &out); err == nil {
+		t.Fatal("expected a missing required field to be rejected with []any required")
+	}
+
+	// A type mismatch on a present field is rejected too.
+	if err := validateJSON(`{"description": 1, "classification": "diagram"}`, schema, &out); err == nil {
+		t.Fatal("expected a type mismatch to be rejected")
+	}
+}
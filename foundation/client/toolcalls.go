@@ -0,0 +1,80 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ToolCallAccumulator reassembles tool calls that arrive fragmented
+// across SSE deltas. Servers split the id, name, and argument JSON of a
+// tool call over several chunks keyed by index; reading only one delta
+// misses or corrupts the invocation. Feed every delta's ToolCalls to Add
+// and collect the completed calls when the stream finishes:
+//
+//	acc := client.NewToolCallAccumulator()
+//	for resp := range ch {
+//		acc.Add(resp.Choices[0].Delta.ToolCalls)
+//		...
+//	}
+//	toolCalls, err := acc.ToolCalls()
+type ToolCallAccumulator struct {
+	pending map[int]*ToolCall
+}
+
+// NewToolCallAccumulator constructs an accumulator for one stream.
+func NewToolCallAccumulator() *ToolCallAccumulator {
+	return &ToolCallAccumulator{
+		pending: make(map[int]*ToolCall),
+	}
+}
+
+// Add folds the tool call fragments from one delta into the accumulator.
+func (acc *ToolCallAccumulator) Add(toolCalls []ToolCall) {
+	for _, tc := range toolCalls {
+		p, exists := acc.pending[tc.Index]
+		if !exists {
+			p = &ToolCall{Index: tc.Index}
+			acc.pending[tc.Index] = p
+		}
+
+		if tc.ID != "" {
+			p.ID = tc.ID
+		}
+
+		if tc.Type != "" {
+			p.Type = tc.Type
+		}
+
+		if tc.Function.Name != "" {
+			p.Function.Name = tc.Function.Name
+		}
+
+		p.Function.RawArguments += tc.Function.RawArguments
+	}
+}
+
+// ToolCalls returns the completed tool calls in index order with their
+// argument JSON parsed.
+func (acc *ToolCallAccumulator) ToolCalls() ([]ToolCall, error) {
+	toolCalls := make([]ToolCall, 0, len(acc.pending))
+
+	for _, p := range acc.pending {
+		arguments := make(map[string]any)
+
+		if p.Function.RawArguments != "" {
+			if err := json.Unmarshal([]byte(p.Function.RawArguments), &arguments); err != nil {
+				return nil, fmt.Errorf("tool call %q: invalid arguments %q: %w", p.Function.Name, p.Function.RawArguments, err)
+			}
+		}
+
+		p.Function.Arguments = arguments
+		toolCalls = append(toolCalls, *p)
+	}
+
+	sort.Slice(toolCalls, func(i, j int) bool {
+		return toolCalls[i].Index < toolCalls[j].Index
+	})
+
+	return toolCalls, nil
+}
@@ -0,0 +1,192 @@
+// Package config centralizes the environment configuration for the
+// examples. The examples grew slightly different env var spellings over
+// time (LLM_SERVER vs LLM_CHAT_SERVER vs LLM_CHATSERVER), so this package
+// documents one canonical set with the repo's defaults and honors the old
+// spellings as fallbacks.
+//
+// Setting AI_HOST repoints every service default at another machine in
+// one shot, which is the common case when the class shares a GPU box:
+//
+//	AI_HOST=10.0.0.5 make example06
+//
+// The canonical variables, each overriding its default completely:
+//
+//	LLM_CHAT_SERVER  // Chat completions endpoint.
+//	LLM_EMBED_SERVER // Embeddings endpoint.
+//	LLM_CHAT_MODEL   // Model for chat requests.
+//	LLM_EMBED_MODEL  // Model for embedding requests.
+//	MONGO_URI        // MongoDB connection string.
+//	MONGO_USER       // MongoDB username.
+//	MONGO_PASS       // MongoDB password.
+//	DOC_SERVER       // Docling service endpoint.
+//
+// Setting LLM_BACKEND selects a profile with that backend's ports, model
+// naming, and quirks in one shot (kronk, ollama, vllm, or yzma):
+//
+//	LLM_BACKEND=ollama make example06
+//
+// The canonical variables above still override any profile value.
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ardanlabs/ai-training/foundation/secrets"
+)
+
+const (
+	defaultChatModel  = "Qwen3-8B-Q8_0"
+	defaultEmbedModel = "embeddinggemma-300m-qat-Q8_0"
+	defaultMongoUser  = "ardan"
+	defaultMongoPass  = "ardan"
+)
+
+// Backend describes one serving stack the examples can talk to. Each
+// profile carries the endpoints, the default models under that backend's
+// naming scheme, and the quirks callers need to respect.
+type Backend struct {
+	Name        string
+	ChatURL     string // Empty for in-process backends.
+	EmbedURL    string // Empty for in-process backends.
+	ChatModel   string
+	EmbedModel  string
+	Parallel    int // Concurrent requests the backend handles well.
+	ContextSize int // Tokens the default chat model is served with.
+}
+
+// backends maps the LLM_BACKEND values to their profiles. URLs hold a %s
+// for the host.
+var backends = map[string]Backend{
+	"kronk": {
+		Name:        "kronk",
+		ChatURL:     "http://%s:11435/v1/chat/completions",
+		EmbedURL:    "http://%s:11435/v1/embeddings",
+		ChatModel:   defaultChatModel,
+		EmbedModel:  defaultEmbedModel,
+		Parallel:    1,
+		ContextSize: 8192,
+	},
+	"ollama": {
+		Name:        "ollama",
+		ChatURL:     "http://%s:11434/v1/chat/completions",
+		EmbedURL:    "http://%s:11434/v1/embeddings",
+		ChatModel:   "qwen3:8b",
+		EmbedModel:  "embeddinggemma:300m",
+		Parallel:    4,
+		ContextSize: 4096,
+	},
+	"vllm": {
+		Name:        "vllm",
+		ChatURL:     "http://%s:8000/v1/chat/completions",
+		EmbedURL:    "http://%s:8000/v1/embeddings",
+		ChatModel:   "Qwen/Qwen3-8B",
+		EmbedModel:  "google/embeddinggemma-300m",
+		Parallel:    8,
+		ContextSize: 32768,
+	},
+	"yzma": {
+		Name:        "yzma",
+		ChatModel:   os.Getenv("YZMA_CHAT_MODEL"),
+		EmbedModel:  os.Getenv("YZMA_EMBED_MODEL"),
+		Parallel:    1,
+		ContextSize: 8192,
+	},
+}
+
+// ActiveBackend returns the profile selected by LLM_BACKEND with the host
+// and any canonical env var overrides applied. An unset or unknown value
+// selects kronk, matching what the examples were hardcoded to.
+func ActiveBackend() Backend {
+	b, exists := backends[os.Getenv("LLM_BACKEND")]
+	if !exists {
+		b = backends["kronk"]
+	}
+
+	if b.ChatURL != "" {
+		b.ChatURL = fmt.Sprintf(b.ChatURL, host())
+	}
+	if b.EmbedURL != "" {
+		b.EmbedURL = fmt.Sprintf(b.EmbedURL, host())
+	}
+
+	b.ChatURL = lookup(b.ChatURL, "LLM_CHAT_SERVER", "LLM_CHATSERVER", "LLM_SERVER")
+	b.EmbedURL = lookup(b.EmbedURL, "LLM_EMBED_SERVER", "EMBED_SERVER")
+	b.ChatModel = lookup(b.ChatModel, "LLM_CHAT_MODEL", "LLM_CHATMODEL", "LLM_MODEL")
+	b.EmbedModel = lookup(b.EmbedModel, "LLM_EMBED_MODEL", "EMBED_MODEL")
+
+	return b
+}
+
+// host returns the machine the services run on, localhost unless AI_HOST
+// repoints everything.
+func host() string {
+	if v := os.Getenv("AI_HOST"); v != "" {
+		return v
+	}
+
+	return "localhost"
+}
+
+// lookup returns the first env var from names that is set, or the default.
+// The extra names keep the old spellings working.
+func lookup(defaultValue string, names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+
+	return defaultValue
+}
+
+// ChatURL returns the chat completions endpoint for the active backend.
+func ChatURL() string {
+	return ActiveBackend().ChatURL
+}
+
+// EmbedURL returns the embeddings endpoint for the active backend.
+func EmbedURL() string {
+	return ActiveBackend().EmbedURL
+}
+
+// ChatModel returns the model used for chat requests by the active backend.
+func ChatModel() string {
+	return ActiveBackend().ChatModel
+}
+
+// EmbedModel returns the model used for embedding requests by the active
+// backend.
+func EmbedModel() string {
+	return ActiveBackend().EmbedModel
+}
+
+// MongoURI returns the MongoDB connection string.
+func MongoURI() string {
+	return lookup(fmt.Sprintf("mongodb://%s:27017", host()), "MONGO_URI")
+}
+
+// MongoUser returns the MongoDB username. Credentials go through the
+// secrets package so a .env file or the keychain works and the value is
+// redacted from logs.
+func MongoUser() string {
+	if v := secrets.Get("MONGO_USER"); v != "" {
+		return v
+	}
+
+	return defaultMongoUser
+}
+
+// MongoPass returns the MongoDB password.
+func MongoPass() string {
+	if v := secrets.Get("MONGO_PASS"); v != "" {
+		return v
+	}
+
+	return defaultMongoPass
+}
+
+// DoclingURL returns the Docling service endpoint.
+func DoclingURL() string {
+	return lookup(fmt.Sprintf("http://%s:5001", host()), "DOC_SERVER")
+}
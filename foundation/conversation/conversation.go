@@ -0,0 +1,81 @@
+// Package conversation manages the message history an agent sends with
+// every chat request. It owns the bookkeeping the agent examples kept
+// duplicating: appending messages, counting tokens against the context
+// window, and evicting the oldest messages when the window fills while
+// keeping the system prompt pinned in place.
+package conversation
+
+import (
+	"slices"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/tiktoken"
+)
+
+// Conversation holds the message history for one chat session and keeps
+// it inside the token budget.
+type Conversation struct {
+	tke      *tiktoken.Tiktoken
+	budget   int
+	reserved int
+	pinned   int
+	messages []client.D
+}
+
+// New constructs a conversation with the specified token budget. A zero
+// budget disables eviction.
+func New(tke *tiktoken.Tiktoken, budget int) *Conversation {
+	return &Conversation{
+		tke:    tke,
+		budget: budget,
+	}
+}
+
+// SetReserved sets a token count that is always part of the window, such
+// as the tool schemas sent with every request.
+func (c *Conversation) SetReserved(tokens int) {
+	c.reserved = tokens
+}
+
+// Pin appends a message that is never evicted. The system prompt goes
+// here. Pinned messages must be added before any regular ones.
+func (c *Conversation) Pin(msg client.D) {
+	c.messages = slices.Insert(c.messages, c.pinned, msg)
+	c.pinned++
+}
+
+// Add appends messages to the history and evicts the oldest unpinned
+// messages until the window fits the budget again.
+func (c *Conversation) Add(msgs ...client.D) {
+	c.messages = append(c.messages, msgs...)
+
+	if c.budget == 0 {
+		return
+	}
+
+	// Never evict the messages just added: a window that can't fit the
+	// current exchange is the caller's problem, not history's.
+	evictable := len(c.messages) - len(msgs)
+
+	for c.Tokens() > c.budget && evictable > c.pinned {
+		c.messages = slices.Delete(c.messages, c.pinned, c.pinned+1)
+		evictable--
+	}
+}
+
+// Messages returns the history to send with the next request. Callers
+// must not mutate the returned slice.
+func (c *Conversation) Messages() []client.D {
+	return c.messages
+}
+
+// Tokens returns the current size of the window, including the reserved
+// overhead.
+func (c *Conversation) Tokens() int {
+	return c.tke.CountMessages(c.messages) + c.reserved
+}
+
+// Len returns the number of messages in the history, pinned included.
+func (c *Conversation) Len() int {
+	return len(c.messages)
+}
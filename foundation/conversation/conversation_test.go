@@ -0,0 +1,96 @@
+package conversation_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+	"github.com/ardanlabs/ai-training/foundation/conversation"
+	"github.com/ardanlabs/ai-training/foundation/tiktoken"
+)
+
+func TestPinAndAdd(t *testing.T) {
+	tke, err := tiktoken.NewTiktoken()
+	if err != nil {
+		t.Fatalf("new tiktoken: %s", err)
+	}
+
+	c := conversation.New(tke, 0)
+
+	c.Pin(client.D{"role": "system", "content": "system prompt"})
+	c.Add(client.D{"role": "user", "content": "hello"})
+	c.Add(client.D{"role": "assistant", "content": "hi"})
+
+	msgs := c.Messages()
+
+	if len(msgs) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(msgs))
+	}
+
+	if msgs[0]["role"] != "system" {
+		t.Fatalf("expected the system prompt first, got role %v", msgs[0]["role"])
+	}
+}
+
+func TestEviction(t *testing.T) {
+	tke, err := tiktoken.NewTiktoken()
+	if err != nil {
+		t.Fatalf("new tiktoken: %s", err)
+	}
+
+	system := client.D{"role": "system", "content": "system prompt"}
+	big := strings.Repeat("word ", 200)
+
+	c := conversation.New(tke, 500)
+	c.Pin(system)
+
+	for range 10 {
+		c.Add(client.D{"role": "user", "content": big})
+	}
+
+	if c.Messages()[0]["role"] != "system" {
+		t.Fatalf("expected the system prompt to survive eviction")
+	}
+
+	if c.Tokens() > 500 {
+		t.Fatalf("expected the window inside the budget, got %d tokens", c.Tokens())
+	}
+
+	if c.Len() >= 11 {
+		t.Fatalf("expected old messages evicted, still have %d", c.Len())
+	}
+}
+
+func TestNewestMessageNeverEvicted(t *testing.T) {
+	tke, err := tiktoken.NewTiktoken()
+	if err != nil {
+		t.Fatalf("new tiktoken: %s", err)
+	}
+
+	big := strings.Repeat("word ", 500)
+
+	c := conversation.New(tke, 100)
+	c.Add(client.D{"role": "user", "content": big})
+
+	if c.Len() != 1 {
+		t.Fatalf("expected the over-budget message to remain, got %d messages", c.Len())
+	}
+}
+
+func TestReservedCountsAgainstBudget(t *testing.T) {
+	tke, err := tiktoken.NewTiktoken()
+	if err != nil {
+		t.Fatalf("new tiktoken: %s", err)
+	}
+
+	c := conversation.New(tke, 1000)
+	c.Add(client.D{"role": "user", "content": "hello"})
+
+	before := c.Tokens()
+
+	c.SetReserved(100)
+
+	if c.Tokens() != before+100 {
+		t.Fatalf("expected reserved tokens in the window: before %d, after %d", before, c.Tokens())
+	}
+}
@@ -10,8 +10,14 @@ import (
 	"mime/multipart"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
 var defaultClient = http.Client{
@@ -31,14 +37,18 @@ var defaultClient = http.Client{
 }
 
 type Docling struct {
-	client *http.Client
-	host   string
+	client      *http.Client
+	host        string
+	maxFileSize int64
+	timeout     time.Duration
 }
 
+// New constructs a Docling client using the base host of the docling
+// service. e.g. "http://localhost:5001"
 func New(host string, options ...func(doc *Docling)) *Docling {
 	doc := &Docling{
 		client: &defaultClient,
-		host:   host,
+		host:   strings.TrimSuffix(host, "/"),
 	}
 
 	for _, option := range options {
@@ -54,66 +64,579 @@ func WithClient(http *http.Client) func(doc *Docling) {
 	}
 }
 
-func (doc *Docling) ConvertFile(ctx context.Context, fileName string, fields map[string]string) (string, error) {
-	var b bytes.Buffer
-	writer := multipart.NewWriter(&b)
+// WithMaxFileSize sets an upper bound on the size of files the client will
+// upload. The default is no limit.
+func WithMaxFileSize(size int64) func(doc *Docling) {
+	return func(doc *Docling) {
+		doc.maxFileSize = size
+	}
+}
 
-	fileWriter, err := writer.CreateFormFile("files", fileName)
-	if err != nil {
-		return "", fmt.Errorf("create form file: %w", err)
+// WithTimeout bounds every request the client makes. The default is to rely
+// on the context provided by the caller.
+func WithTimeout(timeout time.Duration) func(doc *Docling) {
+	return func(doc *Docling) {
+		doc.timeout = timeout
+	}
+}
+
+// Options represents the conversion options supported by the docling
+// service. Zero valued fields are left out of the request so the service
+// defaults apply.
+type Options struct {
+	ToFormats              []string // md, json, html, text, doctags
+	IncludeImages          bool
+	TableMode              string // fast, accurate
+	ImageExportMode        string // placeholder, embedded, referenced
+	MDPageBreakPlaceholder string
+	PDFBackend             string // pypdfium2, dlparse_v1, dlparse_v2, dlparse_v4
+	OCR                    bool
+	OCREngine              string // easyocr, ocrmac, rapidocr, tesseract
+	OCRLanguages           []string
+	PageRange              [2]int // first and last page to convert, 1 based
+}
+
+// NewOptions constructs an Options with the defaults the examples use.
+func NewOptions() Options {
+	return Options{
+		ToFormats:       []string{"md"},
+		TableMode:       "accurate",
+		ImageExportMode: "placeholder",
+		PDFBackend:      "dlparse_v4",
+	}
+}
+
+func (o Options) validate() error {
+	check := func(name string, value string, allowed ...string) error {
+		if value == "" || slices.Contains(allowed, value) {
+			return nil
+		}
+		return fmt.Errorf("invalid %s %q, must be one of %v", name, value, allowed)
+	}
+
+	if err := check("table mode", o.TableMode, "fast", "accurate"); err != nil {
+		return err
+	}
+
+	if err := check("image export mode", o.ImageExportMode, "placeholder", "embedded", "referenced"); err != nil {
+		return err
+	}
+
+	if err := check("pdf backend", o.PDFBackend, "pypdfium2", "dlparse_v1", "dlparse_v2", "dlparse_v4"); err != nil {
+		return err
+	}
+
+	if err := check("ocr engine", o.OCREngine, "easyocr", "ocrmac", "rapidocr", "tesseract"); err != nil {
+		return err
+	}
+
+	if o.PageRange != [2]int{} && (o.PageRange[0] < 1 || o.PageRange[1] < o.PageRange[0]) {
+		return fmt.Errorf("invalid page range %v", o.PageRange)
+	}
+
+	return nil
+}
+
+// formFields returns the options as multipart form fields.
+func (o Options) formFields() url.Values {
+	fields := make(url.Values)
+
+	for _, format := range o.ToFormats {
+		fields.Add("to_formats", format)
+	}
+
+	fields.Set("include_images", strconv.FormatBool(o.IncludeImages))
+
+	if o.TableMode != "" {
+		fields.Set("table_mode", o.TableMode)
+	}
+
+	if o.ImageExportMode != "" {
+		fields.Set("image_export_mode", o.ImageExportMode)
+	}
+
+	if o.MDPageBreakPlaceholder != "" {
+		fields.Set("md_page_break_placeholder", o.MDPageBreakPlaceholder)
+	}
+
+	if o.PDFBackend != "" {
+		fields.Set("pdf_backend", o.PDFBackend)
+	}
+
+	if o.OCR {
+		fields.Set("do_ocr", "true")
+	}
+
+	if o.OCREngine != "" {
+		fields.Set("ocr_engine", o.OCREngine)
+	}
+
+	for _, lang := range o.OCRLanguages {
+		fields.Add("ocr_lang", lang)
+	}
+
+	if o.PageRange != [2]int{} {
+		fields.Add("page_range", strconv.Itoa(o.PageRange[0]))
+		fields.Add("page_range", strconv.Itoa(o.PageRange[1]))
+	}
+
+	return fields
+}
+
+// values returns the options as the JSON document the source based
+// endpoints expect.
+func (o Options) values() map[string]any {
+	values := map[string]any{
+		"include_images": o.IncludeImages,
+	}
+
+	if len(o.ToFormats) > 0 {
+		values["to_formats"] = o.ToFormats
+	}
+
+	if o.TableMode != "" {
+		values["table_mode"] = o.TableMode
+	}
+
+	if o.ImageExportMode != "" {
+		values["image_export_mode"] = o.ImageExportMode
+	}
+
+	if o.MDPageBreakPlaceholder != "" {
+		values["md_page_break_placeholder"] = o.MDPageBreakPlaceholder
+	}
+
+	if o.PDFBackend != "" {
+		values["pdf_backend"] = o.PDFBackend
+	}
+
+	if o.OCR {
+		values["do_ocr"] = true
+	}
+
+	if o.OCREngine != "" {
+		values["ocr_engine"] = o.OCREngine
 	}
 
-	f, err := os.Open(fileName)
+	if len(o.OCRLanguages) > 0 {
+		values["ocr_lang"] = o.OCRLanguages
+	}
+
+	if o.PageRange != [2]int{} {
+		values["page_range"] = []int{o.PageRange[0], o.PageRange[1]}
+	}
+
+	return values
+}
+
+// Ping checks that the docling service is up and healthy. It returns an
+// error that tells the user how to start the service when it isn't.
+func (doc *Docling) Ping(ctx context.Context) error {
+	if _, err := doc.get(ctx, doc.host+"/health"); err != nil {
+		return fmt.Errorf("docling is not running, run `make docling-compose-up`: %w", err)
+	}
+
+	return nil
+}
+
+// ConvertFile converts the specified document using the synchronous endpoint
+// and returns the markdown content. Large documents can time out here, use
+// the async flow for those.
+func (doc *Docling) ConvertFile(ctx context.Context, fileName string, opts Options) (string, error) {
+	if err := opts.validate(); err != nil {
+		return "", fmt.Errorf("validate options: %w", err)
+	}
+
+	body, err := doc.postFile(ctx, doc.host+"/v1/convert/file", fileName, opts.formFields())
 	if err != nil {
-		return "", fmt.Errorf("open file: %w", err)
+		return "", err
+	}
+
+	var data convertResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
 	}
-	defer f.Close()
 
-	if _, err := io.Copy(fileWriter, f); err != nil {
-		return "", fmt.Errorf("copy file: %w", err)
+	return data.Document.MDContent, nil
+}
+
+// FileResult represents the outcome of converting a single document from a
+// batch.
+type FileResult struct {
+	FileName string
+	Content  string
+	Err      error
+}
+
+// ConvertFiles converts the specified documents using a bounded number of
+// concurrent calls and returns a result for every document in the same order
+// they were provided. A document that fails doesn't stop the rest of the
+// batch, check the Err field on each result.
+func (doc *Docling) ConvertFiles(ctx context.Context, fileNames []string, opts Options) []FileResult {
+	const maxConcurrent = 4
+
+	results := make([]FileResult, len(fileNames))
+
+	var g errgroup.Group
+	g.SetLimit(maxConcurrent)
+
+	for i, fileName := range fileNames {
+		g.Go(func() error {
+			content, err := doc.ConvertFile(ctx, fileName, opts)
+
+			results[i] = FileResult{
+				FileName: fileName,
+				Content:  content,
+				Err:      err,
+			}
+
+			return nil
+		})
 	}
 
-	for k, v := range fields {
-		writer.WriteField(k, v)
+	g.Wait()
+
+	return results
+}
+
+// ConvertURL converts a document the docling service downloads itself from
+// the specified url and returns the markdown content. This avoids having to
+// download the file locally first.
+func (doc *Docling) ConvertURL(ctx context.Context, url string, opts Options) (string, error) {
+	if err := opts.validate(); err != nil {
+		return "", fmt.Errorf("validate options: %w", err)
 	}
 
-	if err := writer.Close(); err != nil {
-		return "", fmt.Errorf("close writer: %w", err)
+	payload := map[string]any{
+		"options": opts.values(),
+		"sources": []map[string]any{
+			{"kind": "http", "url": url},
+		},
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.host, &b)
+	body, err := doc.postJSON(ctx, doc.host+"/v1/convert/source", payload)
 	if err != nil {
-		return "", fmt.Errorf("create request error: %w", err)
+		return "", err
+	}
+
+	var data convertResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
 	}
 
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return data.Document.MDContent, nil
+}
+
+// Chunk represents a single chunk returned by the docling chunking
+// endpoints.
+type Chunk struct {
+	Text      string   `json:"text"`
+	Headings  []string `json:"headings"`
+	NumTokens int      `json:"num_tokens"`
+}
+
+// ChunkFileHybrid runs the specified document through docling's hybrid
+// chunker and returns the chunks ready to be embedded. This bypasses any
+// custom chunking on our side.
+func (doc *Docling) ChunkFileHybrid(ctx context.Context, fileName string, opts Options) ([]Chunk, error) {
+	return doc.chunkFile(ctx, doc.host+"/v1/chunk/hybrid/file", fileName, opts)
+}
 
-	resp, err := doc.client.Do(req)
+// ChunkFileHierarchical runs the specified document through docling's
+// hierarchical chunker and returns the chunks ready to be embedded.
+func (doc *Docling) ChunkFileHierarchical(ctx context.Context, fileName string, opts Options) ([]Chunk, error) {
+	return doc.chunkFile(ctx, doc.host+"/v1/chunk/hierarchical/file", fileName, opts)
+}
+
+func (doc *Docling) chunkFile(ctx context.Context, endpoint string, fileName string, opts Options) ([]Chunk, error) {
+	if err := opts.validate(); err != nil {
+		return nil, fmt.Errorf("validate options: %w", err)
+	}
+
+	body, err := doc.postFile(ctx, endpoint, fileName, opts.formFields())
 	if err != nil {
-		return "", fmt.Errorf("do request: %w", err)
+		return nil, err
+	}
+
+	var data struct {
+		Chunks []Chunk `json:"chunks"`
+	}
+
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return data.Chunks, nil
+}
+
+// ConvertFileAsync submits the specified document to the async endpoint and
+// returns the task id to poll with.
+func (doc *Docling) ConvertFileAsync(ctx context.Context, fileName string, opts Options) (string, error) {
+	if err := opts.validate(); err != nil {
+		return "", fmt.Errorf("validate options: %w", err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := doc.postFile(ctx, doc.host+"/v1/convert/file/async", fileName, opts.formFields())
 	if err != nil {
-		return "", fmt.Errorf("read response: %w", err)
+		return "", err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status: %d, body: %s", resp.StatusCode, body)
+	var data taskStatus
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
 	}
 
-	// -------------------------------------------------------------------------
+	if data.TaskID == "" {
+		return "", fmt.Errorf("no task id in response: %s", body)
+	}
 
-	var data struct {
-		Document struct {
-			MDContent string `json:"md_content"`
-		} `json:"document"`
+	return data.TaskID, nil
+}
+
+// Progress is called after every poll of an async task with the current
+// status of the task.
+type Progress func(taskID string, status string)
+
+// Wait polls an async task until it completes or the context is canceled,
+// then returns the markdown content. The progress function is optional.
+func (doc *Docling) Wait(ctx context.Context, taskID string, progress Progress) (string, error) {
+	const pollInterval = 2 * time.Second
+
+	for {
+		status, err := doc.taskStatus(ctx, taskID)
+		if err != nil {
+			return "", fmt.Errorf("task status: %w", err)
+		}
+
+		if progress != nil {
+			progress(taskID, status)
+		}
+
+		switch status {
+		case "success":
+			return doc.result(ctx, taskID)
+
+		case "failure":
+			return "", fmt.Errorf("task %s failed", taskID)
+		}
+
+		select {
+		case <-time.After(pollInterval):
+
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// =============================================================================
+
+type convertResponse struct {
+	Document struct {
+		MDContent string `json:"md_content"`
+	} `json:"document"`
+}
+
+type taskStatus struct {
+	TaskID     string `json:"task_id"`
+	TaskStatus string `json:"task_status"`
+}
+
+func (doc *Docling) taskStatus(ctx context.Context, taskID string) (string, error) {
+	body, err := doc.get(ctx, doc.host+"/v1/status/poll/"+taskID)
+	if err != nil {
+		return "", err
+	}
+
+	var data taskStatus
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
 	}
 
+	return data.TaskStatus, nil
+}
+
+func (doc *Docling) result(ctx context.Context, taskID string) (string, error) {
+	body, err := doc.get(ctx, doc.host+"/v1/result/"+taskID)
+	if err != nil {
+		return "", err
+	}
+
+	var data convertResponse
 	if err := json.Unmarshal(body, &data); err != nil {
 		return "", fmt.Errorf("unmarshal: %w", err)
 	}
 
 	return data.Document.MDContent, nil
 }
+
+func (doc *Docling) postFile(ctx context.Context, endpoint string, fileName string, fields url.Values) ([]byte, error) {
+	if doc.maxFileSize > 0 {
+		info, err := os.Stat(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("stat file: %w", err)
+		}
+
+		if info.Size() > doc.maxFileSize {
+			return nil, fmt.Errorf("file is %d bytes which exceeds the %d byte limit", info.Size(), doc.maxFileSize)
+		}
+	}
+
+	// Fix the boundary up front so the content type stays valid when the
+	// body is replayed on a retry.
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+
+	// stream builds the multipart body as a pipe that reads the file
+	// straight from disk, so large documents never get buffered in memory.
+	stream := func() (io.ReadCloser, error) {
+		f, err := os.Open(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("open file: %w", err)
+		}
+
+		pr, pw := io.Pipe()
+
+		writer := multipart.NewWriter(pw)
+		if err := writer.SetBoundary(boundary); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("set boundary: %w", err)
+		}
+
+		go func() {
+			defer f.Close()
+
+			fileWriter, err := writer.CreateFormFile("files", fileName)
+			if err != nil {
+				pw.CloseWithError(fmt.Errorf("create form file: %w", err))
+				return
+			}
+
+			if _, err := io.Copy(fileWriter, f); err != nil {
+				pw.CloseWithError(fmt.Errorf("copy file: %w", err))
+				return
+			}
+
+			for k, values := range fields {
+				for _, v := range values {
+					writer.WriteField(k, v)
+				}
+			}
+
+			pw.CloseWithError(writer.Close())
+		}()
+
+		return pr, nil
+	}
+
+	body, err := stream()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := doc.withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request error: %w", err)
+	}
+
+	req.GetBody = stream
+	req.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+
+	return doc.do(req)
+}
+
+func (doc *Docling) postJSON(ctx context.Context, endpoint string, payload any) ([]byte, error) {
+	var b bytes.Buffer
+	if err := json.NewEncoder(&b).Encode(payload); err != nil {
+		return nil, fmt.Errorf("encoding: %w", err)
+	}
+
+	ctx, cancel := doc.withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &b)
+	if err != nil {
+		return nil, fmt.Errorf("create request error: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return doc.do(req)
+}
+
+func (doc *Docling) get(ctx context.Context, endpoint string) ([]byte, error) {
+	ctx, cancel := doc.withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request error: %w", err)
+	}
+
+	return doc.do(req)
+}
+
+// withTimeout bounds the context with the client level timeout when one has
+// been configured.
+func (doc *Docling) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if doc.timeout > 0 {
+		return context.WithTimeout(ctx, doc.timeout)
+	}
+
+	return ctx, func() {}
+}
+
+func (doc *Docling) do(req *http.Request) ([]byte, error) {
+	const attempts = 3
+
+	var lastErr error
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+
+			// Transient failure, back off and replay the request body.
+			select {
+			case <-time.After(time.Duration(attempt) * time.Second):
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("get body: %w", err)
+				}
+				req.Body = body
+			}
+		}
+
+		resp, err := doc.client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("do request: %w", err)
+			continue
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if err != nil {
+			lastErr = fmt.Errorf("read response: %w", err)
+			continue
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			return body, nil
+		}
+
+		lastErr = fmt.Errorf("unexpected status: %d, body: %s", resp.StatusCode, body)
+
+		// Only 5xx responses are worth retrying.
+		if resp.StatusCode < http.StatusInternalServerError {
+			return nil, lastErr
+		}
+	}
+
+	return nil, fmt.Errorf("after %d attempts: %w", attempts, lastErr)
+}
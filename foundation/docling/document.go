@@ -0,0 +1,109 @@
+package docling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// BoundingBox locates an item on a page in the page's coordinate system.
+type BoundingBox struct {
+	Left   float64 `json:"l"`
+	Top    float64 `json:"t"`
+	Right  float64 `json:"r"`
+	Bottom float64 `json:"b"`
+}
+
+// Provenance ties an item back to the page it was extracted from.
+type Provenance struct {
+	PageNo int         `json:"page_no"`
+	BBox   BoundingBox `json:"bbox"`
+}
+
+// TextItem represents a single run of text in the document such as a
+// paragraph, heading, or list item.
+type TextItem struct {
+	Label string       `json:"label"`
+	Text  string       `json:"text"`
+	Prov  []Provenance `json:"prov"`
+}
+
+// TableCell represents a single cell inside a table grid.
+type TableCell struct {
+	Text    string `json:"text"`
+	RowSpan int    `json:"row_span"`
+	ColSpan int    `json:"col_span"`
+}
+
+// TableData carries the grid of cells for a table.
+type TableData struct {
+	NumRows int           `json:"num_rows"`
+	NumCols int           `json:"num_cols"`
+	Grid    [][]TableCell `json:"grid"`
+}
+
+// Table represents a table docling detected in the document.
+type Table struct {
+	Prov []Provenance `json:"prov"`
+	Data TableData    `json:"data"`
+}
+
+// Picture represents a figure or image docling detected in the document.
+type Picture struct {
+	Label string       `json:"label"`
+	Prov  []Provenance `json:"prov"`
+}
+
+// PageSize carries the dimensions of a page.
+type PageSize struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Page represents a single page of the source document.
+type Page struct {
+	PageNo int      `json:"page_no"`
+	Size   PageSize `json:"size"`
+}
+
+// Document represents docling's typed view of a converted document. With
+// this, downstream code can chunk tables and figures separately from prose
+// instead of scraping markdown.
+type Document struct {
+	Name     string          `json:"name"`
+	Texts    []TextItem      `json:"texts"`
+	Tables   []Table         `json:"tables"`
+	Pictures []Picture       `json:"pictures"`
+	Pages    map[string]Page `json:"pages"`
+}
+
+// ConvertFileJSON converts the specified document and returns docling's
+// typed JSON document rather than flattened markdown.
+func (doc *Docling) ConvertFileJSON(ctx context.Context, fileName string, opts Options) (*Document, error) {
+	opts.ToFormats = []string{"json"}
+
+	if err := opts.validate(); err != nil {
+		return nil, fmt.Errorf("validate options: %w", err)
+	}
+
+	body, err := doc.postFile(ctx, doc.host+"/v1/convert/file", fileName, opts.formFields())
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		Document struct {
+			JSONContent *Document `json:"json_content"`
+		} `json:"document"`
+	}
+
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	if data.Document.JSONContent == nil {
+		return nil, fmt.Errorf("no json content in response")
+	}
+
+	return data.Document.JSONContent, nil
+}
@@ -0,0 +1,520 @@
+// Package duckdbstore provides a DuckDB backed vector store with a
+// persistent on-disk database, HNSW index management, batched inserts,
+// and similarity search helpers built on the VSS and FTS extensions.
+package duckdbstore
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/duckdb/duckdb-go/v2"
+)
+
+// Embedder vectorizes batches of text. Any embedding model that can process
+// a group of texts per call satisfies this interface.
+type Embedder interface {
+	EmbedBatch(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Open opens the specified duckdb database, loads the extensions, and
+// makes sure the items table exists.
+func Open(dbPath string, dimentions int) (*sql.DB, error) {
+	connector, err := duckdb.NewConnector(dbPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating connector: %w", err)
+	}
+	defer connector.Close()
+
+	db := sql.OpenDB(connector)
+
+	// -------------------------------------------------------------------------
+
+	// Install and load the VSS extension for vector similarity search and
+	// the FTS extension for full-text search.
+	sql := `
+		INSTALL vss; LOAD vss;
+		INSTALL fts; LOAD fts;
+	`
+
+	_, err = db.Exec(sql)
+	if err != nil {
+		return nil, fmt.Errorf("error loading extensions: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	// The HNSW index is persisted in the database file so it's built once
+	// and reused across runs.
+	_, err = db.Exec("SET hnsw_enable_experimental_persistence = true;")
+	if err != nil {
+		return nil, fmt.Errorf("error setting HNSW persistence: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	sql = `
+		CREATE TABLE IF NOT EXISTS items (
+			id           INTEGER   PRIMARY KEY,
+			hash         VARCHAR,
+			source       VARCHAR,
+			chapter      VARCHAR,
+			section      VARCHAR,
+			page         INTEGER,
+			text         VARCHAR,
+			embedding    FLOAT[%d],
+			embedding_i8 TINYINT[%d]
+		);
+	`
+
+	sql = fmt.Sprintf(sql, dimentions, dimentions)
+
+	if _, err = db.Exec(sql); err != nil {
+		return nil, fmt.Errorf("error creating table: %w", err)
+	}
+
+	return db, nil
+}
+
+// LoadData loads the specified chunks file into a duckdb database that is
+// configured to use the VSS extension for vector similarity search.
+func LoadData(dbPath string, emb Embedder, dimentions int, chunksFile string) (*sql.DB, error) {
+	db, err := Open(dbPath, dimentions)
+	if err != nil {
+		return nil, err
+	}
+
+	// -------------------------------------------------------------------------
+
+	fmt.Print("LOADING DATA...")
+	t := time.Now()
+
+	if err := syncChunks(db, emb, chunksFile); err != nil {
+		return nil, fmt.Errorf("error loading data: %w", err)
+	}
+
+	fmt.Printf("Loaded data in %v\n", time.Since(t))
+
+	// -------------------------------------------------------------------------
+
+	sql := `
+		CREATE INDEX IF NOT EXISTS idx_embedding ON items
+		USING HNSW (embedding)
+		WITH (metric = 'cosine');
+	`
+
+	if _, err = db.Exec(sql); err != nil {
+		return nil, fmt.Errorf("error creating HNSW index: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	// The FTS index can't be updated incrementally, so it's rebuilt on
+	// every load.
+	sql = `
+		PRAGMA create_fts_index('items', 'id', 'text', overwrite=1);
+	`
+
+	if _, err = db.Exec(sql); err != nil {
+		return nil, fmt.Errorf("error creating FTS index: %w", err)
+	}
+
+	return db, nil
+}
+
+// ExportParquet writes the items table to a parquet file so an embedding
+// set can be shared without every machine re-computing it.
+func ExportParquet(db *sql.DB, path string) error {
+	sql := fmt.Sprintf("COPY items TO '%s' (FORMAT PARQUET);", escape(path))
+
+	if _, err := db.Exec(sql); err != nil {
+		return fmt.Errorf("error exporting parquet: %w", err)
+	}
+
+	return nil
+}
+
+// ImportParquet loads the rows from a parquet file into the items table,
+// replacing any rows that share an id.
+func ImportParquet(db *sql.DB, path string) error {
+	sql := fmt.Sprintf("INSERT OR REPLACE INTO items SELECT * FROM read_parquet('%s');", escape(path))
+
+	if _, err := db.Exec(sql); err != nil {
+		return fmt.Errorf("error importing parquet: %w", err)
+	}
+
+	return nil
+}
+
+// syncChunks brings the items table in line with the chunks file. Each
+// chunk is identified by a content hash, so only new or changed chunks are
+// vectorized and upserted.
+func syncChunks(db *sql.DB, emb Embedder, chunksFile string) error {
+	chunks, err := readChunks(chunksFile)
+	if err != nil {
+		return err
+	}
+
+	// -------------------------------------------------------------------------
+
+	existing := make(map[int]string)
+
+	rows, err := db.Query("SELECT id, hash FROM items;")
+	if err != nil {
+		return fmt.Errorf("query hashes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var hash string
+
+		if err := rows.Scan(&id, &hash); err != nil {
+			return fmt.Errorf("scan hash: %w", err)
+		}
+
+		existing[id] = hash
+	}
+
+	// Rows past the end of the chunks file belong to an older version of
+	// the data.
+	if _, err := db.Exec(fmt.Sprintf("DELETE FROM items WHERE id >= %d;", len(chunks))); err != nil {
+		return fmt.Errorf("delete stale chunks: %w", err)
+	}
+
+	type pending struct {
+		id   int
+		hash string
+	}
+
+	var work []pending
+
+	hashes := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		key := fmt.Sprintf("%s|%s|%d|%s", chunk.Chapter, chunk.Section, chunk.Page, chunk.Text)
+		hashes[i] = fmt.Sprintf("%x", sha256.Sum256([]byte(key)))
+
+		if existing[i] != hashes[i] {
+			work = append(work, pending{id: i, hash: hashes[i]})
+		}
+	}
+
+	if len(work) == 0 {
+		fmt.Printf("all %d chunks up to date...", len(chunks))
+		return nil
+	}
+
+	fmt.Print("\n")
+	fmt.Print("\033[s")
+
+	// Vectorize the new and changed chunks in batches so the model
+	// processes a group of chunks per call instead of one at a time.
+	const batchSize = 32
+
+	for start := 0; start < len(work); start += batchSize {
+		group := work[start:min(start+batchSize, len(work))]
+
+		fmt.Print("\033[u\033[K")
+		fmt.Printf("Vectorizing Data: %d of %d", min(start+batchSize, len(work)), len(work))
+
+		batch := make([]string, len(group))
+		for i, p := range group {
+			batch[i] = chunks[p.id].Text
+		}
+
+		vecs, err := func() ([][]float32, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			return emb.EmbedBatch(ctx, batch)
+		}()
+		if err != nil {
+			return fmt.Errorf("embed: %w", err)
+		}
+
+		source := escape(filepath.Base(chunksFile))
+
+		for i, vec := range vecs {
+			chunk := chunks[group[i].id]
+			vecStr := strings.ReplaceAll(fmt.Sprintf("%v", vec), " ", ",")
+			i8Str := strings.ReplaceAll(fmt.Sprintf("%v", quantize(vec)), " ", ",")
+
+			sql := fmt.Sprintf("INSERT OR REPLACE INTO items (id, hash, source, chapter, section, page, text, embedding, embedding_i8) VALUES(%d, '%s', '%s', '%s', '%s', %d, '%s', %v, %v);",
+				group[i].id, group[i].hash, source, escape(chunk.Chapter), escape(chunk.Section), chunk.Page, escape(chunk.Text), vecStr, i8Str)
+
+			if _, err := db.Exec(sql); err != nil {
+				return fmt.Errorf("insert chunk: %s %w", sql, err)
+			}
+		}
+	}
+
+	fmt.Print("\n")
+
+	return nil
+}
+
+// chunkDoc carries a chunk's text and the metadata the cleaner wrote with
+// it.
+type chunkDoc struct {
+	Text    string `json:"text"`
+	Chapter string `json:"chapter"`
+	Section string `json:"section"`
+	Page    int    `json:"page"`
+}
+
+func readChunks(chunksFile string) ([]chunkDoc, error) {
+	input, err := os.Open(chunksFile)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer input.Close()
+
+	var chunks []chunkDoc
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+
+	for scanner.Scan() {
+		var doc chunkDoc
+
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			return nil, fmt.Errorf("unmarshal: %w", err)
+		}
+
+		chunks = append(chunks, doc)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner: %w", err)
+	}
+
+	return chunks, nil
+}
+
+// escape doubles single quotes so the value can be embedded in a SQL
+// string literal.
+func escape(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
+
+// quantize maps a vector onto int8 values using symmetric linear
+// quantization. Cosine similarity is scale invariant, so the per vector
+// scale factor drops out of the distance calculation.
+func quantize(vec []float32) []int8 {
+	var maxAbs float32
+	for _, v := range vec {
+		if v < 0 {
+			v = -v
+		}
+		if v > maxAbs {
+			maxAbs = v
+		}
+	}
+
+	quantized := make([]int8, len(vec))
+
+	if maxAbs == 0 {
+		return quantized
+	}
+
+	for i, v := range vec {
+		quantized[i] = int8(v / maxAbs * 127)
+	}
+
+	return quantized
+}
+
+// =============================================================================
+
+type Document struct {
+	ID         int
+	Source     string
+	Chapter    string
+	Section    string
+	Page       int
+	Text       string
+	Embedding  []float64
+	Similarity float64
+}
+
+// Filter narrows a search to chunks matching the metadata fields that are
+// set. Zero valued fields are ignored.
+type Filter struct {
+	Source  string
+	Chapter string
+	Section string
+	Page    int
+}
+
+// where builds the WHERE clause for the filter, or returns an empty string
+// when no fields are set.
+func (f Filter) where() string {
+	var conds []string
+
+	if f.Source != "" {
+		conds = append(conds, fmt.Sprintf("source = '%s'", escape(f.Source)))
+	}
+
+	if f.Chapter != "" {
+		conds = append(conds, fmt.Sprintf("chapter = '%s'", escape(f.Chapter)))
+	}
+
+	if f.Section != "" {
+		conds = append(conds, fmt.Sprintf("section = '%s'", escape(f.Section)))
+	}
+
+	if f.Page != 0 {
+		conds = append(conds, fmt.Sprintf("page = %d", f.Page))
+	}
+
+	if len(conds) == 0 {
+		return ""
+	}
+
+	return "WHERE " + strings.Join(conds, " AND ")
+}
+
+// HybridSearch combines vector similarity and BM25 full-text search with
+// reciprocal rank fusion. A document that ranks well on either side rises
+// to the top, which catches exact keyword matches the embedding misses.
+func HybridSearch(db *sql.DB, queryVector []float32, queryText string, filter Filter, limit int) ([]Document, error) {
+	sql := `
+		WITH vec AS (
+			SELECT
+				id,
+				RANK() OVER (ORDER BY array_cosine_similarity(embedding, ?::FLOAT[%d]) DESC) AS r
+			FROM
+				items
+		),
+		fts AS (
+			SELECT
+				id,
+				RANK() OVER (ORDER BY score DESC) AS r
+			FROM (
+				SELECT id, fts_main_items.match_bm25(id, ?) AS score FROM items
+			)
+			WHERE
+				score IS NOT NULL
+		)
+		SELECT
+			i.id,
+			i.source,
+			i.chapter,
+			i.section,
+			i.page,
+			i.text,
+			i.embedding,
+			COALESCE(1.0/(60+vec.r), 0) + COALESCE(1.0/(60+fts.r), 0) AS similarity
+		FROM
+			items i
+			LEFT JOIN vec ON vec.id = i.id
+			LEFT JOIN fts ON fts.id = i.id
+		%s
+		ORDER BY
+			similarity DESC
+		LIMIT %d;
+	`
+
+	sql = fmt.Sprintf(sql, len(queryVector), filter.where(), limit)
+
+	rows, err := db.Query(sql, queryVector, queryText)
+	if err != nil {
+		return nil, fmt.Errorf("error querying hybrid search: %w", err)
+	}
+	defer rows.Close()
+
+	return scanDocuments(rows)
+}
+
+func Search(db *sql.DB, queryVector []float32, filter Filter, limit int) ([]Document, error) {
+	sql := `
+		SELECT
+			id,
+			source,
+			chapter,
+			section,
+			page,
+			text,
+			embedding,
+			array_cosine_similarity(embedding, ?::FLOAT[%d]) as similarity
+		FROM
+			items
+		%s
+		ORDER BY
+			similarity DESC
+		LIMIT %d;
+	`
+
+	sql = fmt.Sprintf(sql, len(queryVector), filter.where(), limit)
+
+	rows, err := db.Query(sql, queryVector)
+	if err != nil {
+		return nil, fmt.Errorf("error querying similar items: %w", err)
+	}
+	defer rows.Close()
+
+	return scanDocuments(rows)
+}
+
+// SearchQuantized runs the cosine search against the int8 quantized
+// embedding column. The quantized vectors are a quarter the size of the
+// FLOAT32 originals at the cost of some precision. The query vector isn't
+// quantized since cosine doesn't care about the difference in magnitude.
+func SearchQuantized(db *sql.DB, queryVector []float32, filter Filter, limit int) ([]Document, error) {
+	sql := `
+		SELECT
+			id,
+			source,
+			chapter,
+			section,
+			page,
+			text,
+			embedding,
+			array_cosine_similarity(embedding_i8::FLOAT[%d], ?::FLOAT[%d]) as similarity
+		FROM
+			items
+		%s
+		ORDER BY
+			similarity DESC
+		LIMIT %d;
+	`
+
+	sql = fmt.Sprintf(sql, len(queryVector), len(queryVector), filter.where(), limit)
+
+	rows, err := db.Query(sql, queryVector)
+	if err != nil {
+		return nil, fmt.Errorf("error querying similar items: %w", err)
+	}
+	defer rows.Close()
+
+	return scanDocuments(rows)
+}
+
+func scanDocuments(rows *sql.Rows) ([]Document, error) {
+	var docs []Document
+
+	for rows.Next() {
+		var doc Document
+		var embedding []any
+
+		if err := rows.Scan(&doc.ID, &doc.Source, &doc.Chapter, &doc.Section, &doc.Page, &doc.Text, &embedding, &doc.Similarity); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+
+		doc.Embedding = make([]float64, len(embedding))
+		for i, v := range embedding {
+			doc.Embedding[i] = float64(v.(float32))
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,148 @@
+// Package embedding wraps a kronk embedding model behind a small API the
+// offline examples share. Pooling is handled by the model itself, the
+// wrapper adds input truncation, batching, and optional normalization.
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/ardanlabs/kronk/sdk/kronk"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+)
+
+type config struct {
+	truncate   bool
+	direction  string
+	normalize  bool
+	dimensions int
+}
+
+func defaultConfig() config {
+	return config{
+		truncate:  true,
+		direction: "right",
+	}
+}
+
+// WithoutTruncation fails inputs that are longer than the model's context
+// window instead of cutting them. The default cuts them on the right.
+func WithoutTruncation() func(cfg *config) {
+	return func(cfg *config) {
+		cfg.truncate = false
+	}
+}
+
+// WithTruncateDirection sets which side of an oversized input is cut,
+// "right" or "left".
+func WithTruncateDirection(direction string) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.direction = direction
+	}
+}
+
+// WithNormalization applies L2 normalization to the returned vectors so
+// their dot product equals their cosine similarity.
+func WithNormalization() func(cfg *config) {
+	return func(cfg *config) {
+		cfg.normalize = true
+	}
+}
+
+// WithDimensions reduces the vectors to the first dimensions values. Only
+// use this with Matryoshka trained models.
+func WithDimensions(dimensions int) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.dimensions = dimensions
+	}
+}
+
+// =============================================================================
+
+// Model provides embedding support on top of a loaded kronk model.
+type Model struct {
+	krn *kronk.Kronk
+	cfg config
+}
+
+// New constructs a Model for the specified kronk instance, which must have
+// an embedding model loaded.
+func New(krn *kronk.Kronk, options ...func(cfg *config)) *Model {
+	cfg := defaultConfig()
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	return &Model{
+		krn: krn,
+		cfg: cfg,
+	}
+}
+
+// Embed returns the embedding vector for the specified text.
+func (m *Model) Embed(ctx context.Context, text string) ([]float32, error) {
+	vecs, err := m.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+
+	return vecs[0], nil
+}
+
+// EmbedBatch returns the embedding vectors for the specified texts in the
+// same order. The texts are processed as a single batch by the model.
+func (m *Model) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	d := model.D{
+		"input":              texts,
+		"truncate":           m.cfg.truncate,
+		"truncate_direction": m.cfg.direction,
+	}
+
+	if m.cfg.dimensions > 0 {
+		d["dimensions"] = float64(m.cfg.dimensions)
+	}
+
+	resp, err := m.krn.Embeddings(ctx, d)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings: %w", err)
+	}
+
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings: expected %d vectors, got %d", len(texts), len(resp.Data))
+	}
+
+	vecs := make([][]float32, len(texts))
+
+	for _, data := range resp.Data {
+		if len(data.Embedding) == 0 {
+			return nil, fmt.Errorf("embeddings: empty vector at index %d", data.Index)
+		}
+
+		vec := data.Embedding
+		if m.cfg.normalize {
+			vec = normalize(vec)
+		}
+
+		vecs[data.Index] = vec
+	}
+
+	return vecs, nil
+}
+
+// normalize applies L2 normalization to the vector.
+func normalize(vec []float32) []float32 {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+
+	norm := float32(1 / math.Sqrt(sum))
+
+	result := make([]float32, len(vec))
+	for i, v := range vec {
+		result[i] = v * norm
+	}
+
+	return result
+}
@@ -0,0 +1,92 @@
+// Package errs centralizes the error values and classification used
+// across the examples. The sentinels replace the per-example copies and
+// the stringly matching that grew around ffmpeg failures, model-not-loaded
+// responses, and deadline special cases.
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+)
+
+var (
+	// ErrFFMPEG indicates an ffmpeg or ffprobe invocation failed.
+	ErrFFMPEG = errors.New("ffmpeg error")
+
+	// ErrUnauthorized indicates the api understands the request but
+	// refuses to authorize it.
+	ErrUnauthorized = errors.New("api understands the request but refuses to authorize it")
+
+	// ErrModelMissing indicates the server doesn't have the requested
+	// model loaded.
+	ErrModelMissing = errors.New("model not loaded")
+)
+
+// StatusError carries the http status code of a failed api call so
+// callers can classify the failure instead of parsing the message.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("error: status: %d response: %s", e.StatusCode, e.Message)
+}
+
+// IsRetryable reports whether the error represents a transient condition
+// worth retrying: timeouts, connection failures, and server-side statuses
+// like 429 or 503. Cancellation is not retryable since the caller asked
+// to stop.
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case 429, 500, 502, 503, 504:
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsModelMissing reports whether the error means the requested model
+// isn't available on the server. The message matching covers servers
+// that report this as a plain 400/404 with prose.
+func IsModelMissing(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrModelMissing) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	if !strings.Contains(msg, "model") {
+		return false
+	}
+
+	return strings.Contains(msg, "not loaded") || strings.Contains(msg, "not found") || strings.Contains(msg, "no such model")
+}
@@ -0,0 +1,129 @@
+// Package fakechat provides an OpenAI-compatible chat completions
+// endpoint that answers every request with a canned reply. Paired with
+// fakeembed it lets the example flows run end to end in tests without a
+// model server: the reply carries no intelligence, but the request and
+// response plumbing is the real thing.
+package fakechat
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Handler returns an http handler that implements an OpenAI-compatible
+// chat completions endpoint serving the specified reply. Streaming
+// requests receive the reply as SSE deltas closed by a usage chunk and
+// [DONE]; everything else gets a single completion document.
+func Handler(reply string) http.Handler {
+	h := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stream bool `json:"stream"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("decode: %s", err))
+			return
+		}
+
+		if req.Stream {
+			stream(w, reply)
+			return
+		}
+
+		resp := completion{
+			Object: "chat.completion",
+			Model:  "fakechat",
+			Choices: []choice{
+				{Message: &message{Role: "assistant", Content: reply}, FinishReason: "stop"},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+
+	return http.HandlerFunc(h)
+}
+
+// stream writes the reply as SSE chunks, one word per delta, the way a
+// model server hands back tokens.
+func stream(w http.ResponseWriter, reply string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+
+	words := strings.SplitAfter(reply, " ")
+
+	for _, word := range words {
+		chunk := completion{
+			Object: "chat.completion.chunk",
+			Model:  "fakechat",
+			Choices: []choice{
+				{Delta: &message{Role: "assistant", Content: word}},
+			},
+		}
+
+		writeChunk(w, chunk)
+	}
+
+	// The final chunk carries only the usage block, matching what the
+	// client expects when it asks for include_usage.
+	writeChunk(w, completion{
+		Object:  "chat.completion.chunk",
+		Model:   "fakechat",
+		Choices: []choice{},
+		Usage:   &usage{CompletionTokens: len(words), TotalTokens: len(words)},
+	})
+
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+func writeChunk(w http.ResponseWriter, chunk completion) {
+	data, _ := json.Marshal(chunk)
+	fmt.Fprintf(w, "data: %s\n\n", data)
+
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	resp := struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}{}
+	resp.Error.Message = message
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+// =============================================================================
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type choice struct {
+	Index        int      `json:"index"`
+	Message      *message `json:"message,omitempty"`
+	Delta        *message `json:"delta,omitempty"`
+	FinishReason string   `json:"finish_reason,omitempty"`
+}
+
+type usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type completion struct {
+	Object  string   `json:"object"`
+	Model   string   `json:"model"`
+	Choices []choice `json:"choices"`
+	Usage   *usage   `json:"usage,omitempty"`
+}
@@ -0,0 +1,130 @@
+// Package fakeembed provides deterministic hash based embeddings and an
+// OpenAI-compatible embeddings endpoint that serves them. The vectors mean
+// nothing semantically, but the same input always produces the same
+// vector, so vector store and search code can be integration tested
+// reproducibly without a GPU model.
+package fakeembed
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+)
+
+// Vector returns the deterministic embedding for the specified text. The
+// vector is expanded from a sha256 of the text and normalized to unit
+// length so cosine similarity behaves.
+func Vector(text string, dimensions int) []float64 {
+	v := make([]float64, dimensions)
+
+	seed := sha256.Sum256([]byte(text))
+	block := seed
+
+	for i := range v {
+		// Each block of the hash chain yields four values.
+		if i%4 == 0 && i > 0 {
+			block = sha256.Sum256(block[:])
+		}
+
+		bits := binary.LittleEndian.Uint64(block[(i%4)*8:])
+		v[i] = float64(bits)/float64(math.MaxUint64)*2 - 1
+	}
+
+	var norm float64
+	for _, val := range v {
+		norm += val * val
+	}
+	norm = math.Sqrt(norm)
+
+	for i := range v {
+		v[i] /= norm
+	}
+
+	return v
+}
+
+// =============================================================================
+
+// Handler returns an http handler that implements an OpenAI-compatible
+// embeddings endpoint on top of Vector. Mount it in a real server or hand
+// it to httptest.NewServer for in-process use.
+func Handler(dimensions int) http.Handler {
+	h := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input any `json:"input"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("decode: %s", err))
+			return
+		}
+
+		var inputs []string
+
+		switch input := req.Input.(type) {
+		case string:
+			inputs = []string{input}
+
+		case []any:
+			for _, v := range input {
+				text, ok := v.(string)
+				if !ok {
+					writeError(w, http.StatusBadRequest, "input must be a string or array of strings")
+					return
+				}
+				inputs = append(inputs, text)
+			}
+
+		default:
+			writeError(w, http.StatusBadRequest, "input must be a string or array of strings")
+			return
+		}
+
+		// ---------------------------------------------------------------------
+
+		type data struct {
+			Index     int       `json:"index"`
+			Object    string    `json:"object"`
+			Embedding []float64 `json:"embedding"`
+		}
+
+		resp := struct {
+			Object string `json:"object"`
+			Model  string `json:"model"`
+			Data   []data `json:"data"`
+		}{
+			Object: "list",
+			Model:  "fakeembed",
+		}
+
+		for i, text := range inputs {
+			resp.Data = append(resp.Data, data{
+				Index:     i,
+				Object:    "embedding",
+				Embedding: Vector(text, dimensions),
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+
+	return http.HandlerFunc(h)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	resp := struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}{}
+	resp.Error.Message = message
+
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,160 @@
+// Package hardware detects the memory and compute available on the
+// machine and recommends a model configuration that fits. The point is
+// to hand an under-provisioned student a working setup instead of a
+// cryptic OOM twenty minutes into class.
+package hardware
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Info describes the hardware the examples have to work with.
+type Info struct {
+	CPUs      int
+	RAMBytes  uint64
+	GPU       string // Empty when no usable GPU was found.
+	VRAMBytes uint64 // Unified memory on Apple silicon.
+}
+
+// Detect probes the local machine. Detection is best effort: a field is
+// zero when the platform doesn't expose it.
+func Detect() Info {
+	info := Info{
+		CPUs:     runtime.NumCPU(),
+		RAMBytes: detectRAM(),
+	}
+
+	if gpu, vram := detectNvidia(); gpu != "" {
+		info.GPU = gpu
+		info.VRAMBytes = vram
+		return info
+	}
+
+	// Apple silicon shares system memory with the GPU.
+	if runtime.GOOS == "darwin" && runtime.GOARCH == "arm64" {
+		info.GPU = "apple-silicon"
+		info.VRAMBytes = info.RAMBytes
+	}
+
+	return info
+}
+
+// Recommendation is a model configuration expected to fit the hardware.
+type Recommendation struct {
+	ChatModel   string
+	EmbedModel  string
+	ContextSize int
+	Reason      string
+}
+
+// Recommend maps the detected hardware to the models used in class. The
+// budget is VRAM when a GPU is present, otherwise system RAM for CPU
+// inference.
+func Recommend(info Info) Recommendation {
+	budget := info.RAMBytes
+	if info.GPU != "" && info.VRAMBytes > 0 {
+		budget = info.VRAMBytes
+	}
+
+	const gb = 1 << 30
+
+	switch {
+	case budget >= 24*gb:
+		return Recommendation{
+			ChatModel:   "gpt-oss-20b-Q8_0",
+			EmbedModel:  "embeddinggemma-300m-qat-Q8_0",
+			ContextSize: 32768,
+			Reason:      "24GB+ available, the 20B model fits with a large context",
+		}
+
+	case budget >= 12*gb:
+		return Recommendation{
+			ChatModel:   "Qwen3-8B-Q8_0",
+			EmbedModel:  "embeddinggemma-300m-qat-Q8_0",
+			ContextSize: 8192,
+			Reason:      "12GB+ available, the class default 8B model fits",
+		}
+
+	case budget >= 6*gb:
+		return Recommendation{
+			ChatModel:   "Qwen3-4B-Q8_0",
+			EmbedModel:  "embeddinggemma-300m-qat-Q8_0",
+			ContextSize: 8192,
+			Reason:      "6GB+ available, drop to the 4B model",
+		}
+
+	default:
+		return Recommendation{
+			ChatModel:   "Qwen3-1.7B-Q8_0",
+			EmbedModel:  "embeddinggemma-300m-qat-Q8_0",
+			ContextSize: 4096,
+			Reason:      "under 6GB available, use the smallest model that can follow the examples",
+		}
+	}
+}
+
+// =============================================================================
+
+// detectRAM returns the total system memory.
+func detectRAM() uint64 {
+	switch runtime.GOOS {
+	case "linux":
+		f, err := os.Open("/proc/meminfo")
+		if err != nil {
+			return 0
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && fields[0] == "MemTotal:" {
+				kb, err := strconv.ParseUint(fields[1], 10, 64)
+				if err != nil {
+					return 0
+				}
+				return kb * 1024
+			}
+		}
+
+	case "darwin":
+		out, err := exec.Command("sysctl", "-n", "hw.memsize").Output()
+		if err != nil {
+			return 0
+		}
+
+		bytes, err := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
+		if err != nil {
+			return 0
+		}
+		return bytes
+	}
+
+	return 0
+}
+
+// detectNvidia returns the name and memory of the first NVIDIA GPU.
+func detectNvidia() (string, uint64) {
+	out, err := exec.Command("nvidia-smi", "--query-gpu=name,memory.total", "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return "", 0
+	}
+
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	name, mem, found := strings.Cut(line, ",")
+	if !found {
+		return "", 0
+	}
+
+	mb, err := strconv.ParseUint(strings.TrimSpace(mem), 10, 64)
+	if err != nil {
+		return strings.TrimSpace(name), 0
+	}
+
+	return strings.TrimSpace(name), mb * 1024 * 1024
+}
@@ -0,0 +1,97 @@
+// Package logger provides slog based logging for the examples and
+// services. It supports a text handler for humans and a JSON handler for
+// post-processing pipeline runs, and it stamps every record with the
+// trace id carried in the context so concurrent requests can be told
+// apart.
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/ardanlabs/ai-training/foundation/secrets"
+)
+
+// Format selects how log records are rendered.
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// New constructs a slog logger writing to w at the specified minimum
+// level. The service name is attached to every record.
+func New(w io.Writer, format Format, level slog.Level, service string) *slog.Logger {
+	opts := slog.HandlerOptions{
+		Level: level,
+
+		// Credentials handed out by the secrets package never reach the
+		// log output.
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Value.Kind() == slog.KindString {
+				a.Value = slog.StringValue(secrets.Redact(a.Value.String()))
+			}
+
+			return a
+		},
+	}
+
+	var handler slog.Handler
+
+	switch format {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, &opts)
+	default:
+		handler = slog.NewTextHandler(w, &opts)
+	}
+
+	handler = traceHandler{Handler: handler}
+
+	return slog.New(handler).With("service", service)
+}
+
+// =============================================================================
+
+type ctxKey int
+
+const traceIDKey ctxKey = 1
+
+// SetTraceID stores a trace id in the context so every log record written
+// for this request carries it.
+func SetTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey, traceID)
+}
+
+// GetTraceID returns the trace id stored in the context, or an empty
+// string when there isn't one.
+func GetTraceID(ctx context.Context) string {
+	traceID, ok := ctx.Value(traceIDKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return traceID
+}
+
+// traceHandler decorates records with the trace id from the context.
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if traceID := GetTraceID(ctx); traceID != "" {
+		r.AddAttrs(slog.String("trace_id", traceID))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceHandler) WithGroup(name string) slog.Handler {
+	return traceHandler{Handler: h.Handler.WithGroup(name)}
+}
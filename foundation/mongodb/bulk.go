@@ -0,0 +1,85 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// BulkUpsertSettings represents settings to perform a batched upsert.
+type BulkUpsertSettings struct {
+	Keys      []string // Fields that identify a document, like a unique index.
+	BatchSize int      // Documents per BulkWrite call. Defaults to 500.
+}
+
+// BulkUpsert writes the documents in batches with one replace-with-upsert
+// per document, keyed on the configured fields. Ingestions get one round
+// trip per batch instead of a find and insert per document, and re-running
+// one replaces what's there instead of failing on a unique index.
+func BulkUpsert(ctx context.Context, col *mongo.Collection, docs []any, settings BulkUpsertSettings) error {
+	if len(settings.Keys) == 0 {
+		return fmt.Errorf("no key fields configured")
+	}
+
+	batchSize := settings.BatchSize
+	if batchSize == 0 {
+		batchSize = 500
+	}
+
+	for start := 0; start < len(docs); start += batchSize {
+		end := min(start+batchSize, len(docs))
+
+		models := make([]mongo.WriteModel, 0, end-start)
+
+		for _, doc := range docs[start:end] {
+			filter, err := keyFilter(doc, settings.Keys)
+			if err != nil {
+				return fmt.Errorf("keyFilter: %w", err)
+			}
+
+			model := mongo.NewReplaceOneModel().
+				SetFilter(filter).
+				SetReplacement(doc).
+				SetUpsert(true)
+
+			models = append(models, model)
+		}
+
+		opts := options.BulkWrite().SetOrdered(false)
+
+		if _, err := col.BulkWrite(ctx, models, opts); err != nil {
+			return fmt.Errorf("bulk write: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// keyFilter builds the filter that identifies the document by pulling the
+// key fields out of its bson encoding.
+func keyFilter(doc any, keys []string) (bson.D, error) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	raw := bson.Raw(data)
+
+	filter := make(bson.D, len(keys))
+
+	for i, key := range keys {
+		value, err := raw.LookupErr(key)
+		if err != nil {
+			return nil, fmt.Errorf("lookup %q: %w", key, err)
+		}
+
+		filter[i] = bson.E{Key: key, Value: value}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,157 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// HybridSearchSettings represents settings to perform a hybrid search.
+type HybridSearchSettings struct {
+	IndexName   string // Name of the vector search index.
+	VectorPath  string // Field holding the embedding.
+	TextPath    string // Field holding the raw text. Needs a text index.
+	Limit       int    // Number of results to return after fusion.
+	RRFConstant int    // Rank fusion constant. Defaults to 60.
+}
+
+// CreateTextIndex creates a standard text index over the specified path so
+// the collection supports BM25 style keyword search. Creating an index that
+// already exists is a no-op.
+func CreateTextIndex(ctx context.Context, col *mongo.Collection, path string) error {
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: path, Value: "text"}},
+	}
+
+	if _, err := col.Indexes().CreateOne(ctx, indexModel); err != nil {
+		return fmt.Errorf("create text index: %w", err)
+	}
+
+	return nil
+}
+
+// HybridSearch runs a vector search and a keyword text search over the same
+// collection and merges the two result sets with reciprocal rank fusion.
+// Each document scores 1/(k+rank) per list it appears in, so documents both
+// searches agree on float to the top. The returned documents carry the fused
+// value in their score field.
+func HybridSearch(ctx context.Context, col *mongo.Collection, vector []float64, query string, settings HybridSearchSettings) ([]bson.M, error) {
+	k := settings.RRFConstant
+	if k == 0 {
+		k = 60
+	}
+
+	vecDocs, err := hybridVectorSearch(ctx, col, vector, settings)
+	if err != nil {
+		return nil, fmt.Errorf("hybridVectorSearch: %w", err)
+	}
+
+	txtDocs, err := hybridTextSearch(ctx, col, query, settings)
+	if err != nil {
+		return nil, fmt.Errorf("hybridTextSearch: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	// Fuse the two ranked lists by document identity. The raw vector and
+	// BM25 scores live on different scales, so only the ranks matter.
+
+	fused := make(map[string]bson.M)
+	scores := make(map[string]float64)
+
+	for _, docs := range [][]bson.M{vecDocs, txtDocs} {
+		for rank, doc := range docs {
+			id := fmt.Sprintf("%v", doc["_id"])
+
+			if _, exists := fused[id]; !exists {
+				fused[id] = doc
+			}
+
+			scores[id] += 1 / float64(k+rank+1)
+		}
+	}
+
+	results := make([]bson.M, 0, len(fused))
+
+	for id, doc := range fused {
+		doc["score"] = scores[id]
+		results = append(results, doc)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i]["score"].(float64) > results[j]["score"].(float64)
+	})
+
+	if len(results) > settings.Limit {
+		results = results[:settings.Limit]
+	}
+
+	return results, nil
+}
+
+// =============================================================================
+
+func hybridVectorSearch(ctx context.Context, col *mongo.Collection, vector []float64, settings HybridSearchSettings) ([]bson.M, error) {
+	pipeline := mongo.Pipeline{
+		{{
+			Key: "$vectorSearch",
+			Value: bson.M{
+				"index":       settings.IndexName,
+				"exact":       true,
+				"path":        settings.VectorPath,
+				"queryVector": vector,
+				"limit":       settings.Limit,
+			}},
+		},
+		{{
+			Key: "$addFields",
+			Value: bson.M{
+				"score": bson.M{
+					"$meta": "vectorSearchScore",
+				},
+			}},
+		},
+	}
+
+	cur, err := col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var docs []bson.M
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("all: %w", err)
+	}
+
+	return docs, nil
+}
+
+func hybridTextSearch(ctx context.Context, col *mongo.Collection, query string, settings HybridSearchSettings) ([]bson.M, error) {
+	filter := bson.M{
+		"$text": bson.M{
+			"$search": query,
+		},
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}}).
+		SetLimit(int64(settings.Limit))
+
+	cur, err := col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("find: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var docs []bson.M
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("all: %w", err)
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,69 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// The collection where each collection's metadata document is stored,
+// keyed by collection name.
+const metaColName = "collection_meta"
+
+// CollectionMeta describes how a collection's embeddings were produced.
+// Searching vectors from one model with vectors from another silently
+// produces garbage scores, so the combination is stamped per collection.
+type CollectionMeta struct {
+	Model      string `bson:"model"`
+	Dimensions int    `bson:"dimensions"`
+	Similarity string `bson:"similarity"`
+}
+
+// CheckCollectionMeta compares the metadata stamped for the collection
+// against what the caller is about to use. The first run stamps it; a
+// mismatch errors with instructions to drop the collection and re-ingest.
+func CheckCollectionMeta(ctx context.Context, col *mongo.Collection, meta CollectionMeta) error {
+	metaCol := col.Database().Collection(metaColName)
+
+	filter := bson.D{{Key: "_id", Value: col.Name()}}
+
+	var stamped CollectionMeta
+
+	res := metaCol.FindOne(ctx, filter)
+
+	switch {
+	case res.Err() == nil:
+		if err := res.Decode(&stamped); err != nil {
+			return fmt.Errorf("decode: %w", err)
+		}
+
+	case errors.Is(res.Err(), mongo.ErrNoDocuments):
+		doc := bson.D{
+			{Key: "_id", Value: col.Name()},
+			{Key: "model", Value: meta.Model},
+			{Key: "dimensions", Value: meta.Dimensions},
+			{Key: "similarity", Value: meta.Similarity},
+		}
+
+		if _, err := metaCol.InsertOne(ctx, doc); err != nil {
+			return fmt.Errorf("insert: %w", err)
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("find: %w", res.Err())
+	}
+
+	if stamped != meta {
+		return fmt.Errorf("collection %q holds embeddings from model %q (%d dims, %s) but model %q (%d dims, %s) is configured: drop the collection and re-ingest",
+			col.Name(),
+			stamped.Model, stamped.Dimensions, stamped.Similarity,
+			meta.Model, meta.Dimensions, meta.Similarity)
+	}
+
+	return nil
+}
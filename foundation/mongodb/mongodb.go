@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -75,8 +76,69 @@ func CreateVectorIndex(ctx context.Context, col *mongo.Collection, vectorIndexNa
 	return nil
 }
 
+// WaitForIndexReady polls the specified search index until Mongo reports
+// it ready and queryable, or the timeout expires. Search indexes build
+// asynchronously, so a write followed by an immediate search can miss
+// documents. Use this instead of sleeping and hoping.
+func WaitForIndexReady(ctx context.Context, col *mongo.Collection, indexName string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		ready, err := indexReady(ctx, col, indexName)
+		if err != nil {
+			return fmt.Errorf("indexReady: %w", err)
+		}
+
+		if ready {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("index %q not ready: %w", indexName, ctx.Err())
+
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
 // =============================================================================
 
+func indexReady(ctx context.Context, col *mongo.Collection, indexName string) (bool, error) {
+	pipeline := mongo.Pipeline{
+		{{
+			Key: "$listSearchIndexes",
+			Value: bson.M{
+				"name": indexName,
+			}},
+		},
+	}
+
+	cur, err := col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return false, fmt.Errorf("aggregate: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var indexes []struct {
+		Status    string `bson:"status"`
+		Queryable bool   `bson:"queryable"`
+	}
+
+	if err := cur.All(ctx, &indexes); err != nil {
+		return false, fmt.Errorf("all: %w", err)
+	}
+
+	for _, idx := range indexes {
+		if idx.Queryable && idx.Status == "READY" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func lookupVectorIndex(ctx context.Context, col *mongo.Collection, vectorIndexName string) ([]Index, error) {
 	siv := col.SearchIndexes()
 	cur, err := siv.List(ctx, options.SearchIndexes().SetName(vectorIndexName))
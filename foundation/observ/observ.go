@@ -0,0 +1,64 @@
+// Package observ provides OpenTelemetry tracing support for the pipeline
+// examples. A span per stage exported to the collector in compose makes
+// it visible where the hours of a long ingestion run actually go.
+package observ
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName identifies the spans produced by this repo.
+const tracerName = "ai-training"
+
+// InitTracing configures the global tracer to export spans to the OTLP
+// collector at the specified grpc endpoint, such as localhost:4317 for
+// the jaeger service in compose. The returned function flushes and shuts
+// the exporter down, call it before the program exits.
+//
+// An empty endpoint installs a noop tracer so instrumented code runs
+// unchanged when tracing isn't wanted.
+func InitTracing(serviceName string, endpoint string) (func(context.Context) error, error) {
+	if endpoint == "" {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracegrpc.New(context.Background(),
+		otlptracegrpc.WithEndpoint(endpoint),
+		otlptracegrpc.WithInsecure(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(serviceName),
+		)),
+	)
+
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// AddSpan starts a span as a child of whatever span is carried in the
+// context. End the span when the stage completes:
+//
+//	ctx, span := observ.AddSpan(ctx, "transcribe", attribute.String("chunk", name))
+//	defer span.End()
+func AddSpan(ctx context.Context, name string, keyValues ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(keyValues...))
+}
@@ -0,0 +1,139 @@
+// Package preflight checks that the services an example depends on are
+// actually reachable before any real work starts. Failing up front with
+// an actionable message beats failing minutes into an ingestion run with
+// a connection error buried in a wrapped chain.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Check probes one dependency. The hint tells the student what to run
+// when the probe fails.
+type Check struct {
+	Name  string
+	Hint  string
+	Probe func(ctx context.Context) error
+}
+
+// Run probes every check once and reports all the failures together.
+func Run(ctx context.Context, checks ...Check) error {
+	var failures []string
+
+	for _, check := range checks {
+		if err := check.Probe(ctx); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s (%s)", check.Name, err, check.Hint))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("preflight:\n\t%s", strings.Join(failures, "\n\t"))
+	}
+
+	return nil
+}
+
+// Wait retries the checks until they all pass or the timeout expires,
+// for the case where compose services are still starting up.
+func Wait(ctx context.Context, timeout time.Duration, checks ...Check) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var err error
+
+	for {
+		if err = Run(ctx, checks...); err == nil {
+			return nil
+		}
+
+		select {
+		case <-time.After(time.Second):
+
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// =============================================================================
+
+// Mongo checks that the MongoDB service accepts connections.
+func Mongo(uri string) Check {
+	host := strings.TrimPrefix(uri, "mongodb://")
+	if i := strings.IndexAny(host, "/?"); i != -1 {
+		host = host[:i]
+	}
+	if i := strings.LastIndex(host, "@"); i != -1 {
+		host = host[i+1:]
+	}
+
+	return Check{
+		Name: "mongodb",
+		Hint: "run: make compose-up",
+		Probe: func(ctx context.Context) error {
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "tcp", host)
+			if err != nil {
+				return fmt.Errorf("not reachable at %s: %w", host, err)
+			}
+			conn.Close()
+
+			return nil
+		},
+	}
+}
+
+// ModelServer checks that the model server answers http requests at the
+// specified endpoint.
+func ModelServer(url string) Check {
+	return Check{
+		Name: "model server",
+		Hint: "run: make kronk-up",
+		Probe: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return fmt.Errorf("bad url %s: %w", url, err)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return fmt.Errorf("not reachable at %s: %w", url, err)
+			}
+			resp.Body.Close()
+
+			return nil
+		},
+	}
+}
+
+// Docling checks that the docling service answers http requests.
+func Docling(url string) Check {
+	check := ModelServer(url)
+	check.Name = "docling"
+	check.Hint = "run: make compose-up"
+
+	return check
+}
+
+// FFMPEG checks that the ffmpeg and ffprobe binaries are installed.
+func FFMPEG() Check {
+	return Check{
+		Name: "ffmpeg",
+		Hint: "install ffmpeg 8.0 or later with whisper support",
+		Probe: func(ctx context.Context) error {
+			for _, bin := range []string{"ffmpeg", "ffprobe"} {
+				if _, err := exec.LookPath(bin); err != nil {
+					return fmt.Errorf("%s not found in PATH", bin)
+				}
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,175 @@
+// Package prompttest provides a table driven runner for prompt regression
+// tests. A spec file pairs prompts with assertions about the response,
+// and the runner executes them against whatever chat function it's given,
+// so the same cases run against a fake server in CI and a real model
+// before a prompt edit ships.
+package prompttest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ChatFunc sends a single prompt to a model and returns the response.
+type ChatFunc func(ctx context.Context, prompt string) (string, error)
+
+// Assertion checks one property of a model response.
+type Assertion struct {
+	Type  string `json:"type"` // contains, not_contains, matches, json, json_field
+	Value string `json:"value,omitempty"`
+}
+
+// Case represents one prompt and the assertions its response must pass.
+// The prompt is either inline or in a file relative to the spec file.
+type Case struct {
+	Name       string      `json:"name"`
+	Prompt     string      `json:"prompt,omitempty"`
+	PromptFile string      `json:"prompt_file,omitempty"`
+	Assertions []Assertion `json:"assertions"`
+}
+
+// Result carries the response and any assertion failures for one case.
+type Result struct {
+	Case     Case
+	Response string
+	Failures []string
+}
+
+// Load reads a spec file and resolves any prompt files relative to it.
+func Load(specFile string) ([]Case, error) {
+	data, err := os.ReadFile(specFile)
+	if err != nil {
+		return nil, fmt.Errorf("read spec: %w", err)
+	}
+
+	var cases []Case
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return nil, fmt.Errorf("unmarshal spec: %w", err)
+	}
+
+	for i, c := range cases {
+		if c.Name == "" {
+			return nil, fmt.Errorf("case %d has no name", i)
+		}
+
+		if (c.Prompt == "") == (c.PromptFile == "") {
+			return nil, fmt.Errorf("case %q needs a prompt or a prompt_file", c.Name)
+		}
+
+		if c.PromptFile != "" {
+			prompt, err := os.ReadFile(filepath.Join(filepath.Dir(specFile), c.PromptFile))
+			if err != nil {
+				return nil, fmt.Errorf("case %q: read prompt: %w", c.Name, err)
+			}
+
+			cases[i].Prompt = string(prompt)
+		}
+
+		if len(c.Assertions) == 0 {
+			return nil, fmt.Errorf("case %q has no assertions", c.Name)
+		}
+	}
+
+	return cases, nil
+}
+
+// Run executes every case against the chat function and returns a result
+// per case. A failing assertion is recorded in the result rather than
+// stopping the run, so one report covers the whole table.
+func Run(ctx context.Context, chat ChatFunc, cases []Case) ([]Result, error) {
+	results := make([]Result, len(cases))
+
+	for i, c := range cases {
+		response, err := chat(ctx, c.Prompt)
+		if err != nil {
+			return nil, fmt.Errorf("case %q: chat: %w", c.Name, err)
+		}
+
+		result := Result{
+			Case:     c,
+			Response: response,
+		}
+
+		for _, a := range c.Assertions {
+			if err := check(response, a); err != nil {
+				result.Failures = append(result.Failures, err.Error())
+			}
+		}
+
+		results[i] = result
+	}
+
+	return results, nil
+}
+
+// Failed reports whether any case in the results had a failing assertion.
+func Failed(results []Result) bool {
+	for _, result := range results {
+		if len(result.Failures) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check applies a single assertion to the response.
+func check(response string, a Assertion) error {
+	switch a.Type {
+	case "contains":
+		if !strings.Contains(response, a.Value) {
+			return fmt.Errorf("response does not contain %q", a.Value)
+		}
+
+	case "not_contains":
+		if strings.Contains(response, a.Value) {
+			return fmt.Errorf("response contains %q", a.Value)
+		}
+
+	case "matches":
+		re, err := regexp.Compile(a.Value)
+		if err != nil {
+			return fmt.Errorf("bad pattern %q: %w", a.Value, err)
+		}
+		if !re.MatchString(response) {
+			return fmt.Errorf("response does not match %q", a.Value)
+		}
+
+	case "json":
+		if !json.Valid([]byte(extractJSON(response))) {
+			return fmt.Errorf("response does not carry valid JSON")
+		}
+
+	case "json_field":
+		var doc map[string]any
+		if err := json.Unmarshal([]byte(extractJSON(response)), &doc); err != nil {
+			return fmt.Errorf("response does not carry a JSON object: %w", err)
+		}
+		if _, exists := doc[a.Value]; !exists {
+			return fmt.Errorf("response JSON has no %q field", a.Value)
+		}
+
+	default:
+		return fmt.Errorf("unknown assertion type %q", a.Type)
+	}
+
+	return nil
+}
+
+// extractJSON pulls the first JSON object out of the response since models
+// routinely wrap their JSON in prose or a code fence.
+func extractJSON(response string) string {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+
+	if start == -1 || end == -1 || end < start {
+		return response
+	}
+
+	return response[start : end+1]
+}
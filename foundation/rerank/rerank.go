@@ -0,0 +1,192 @@
+// Package rerank reorders retrieval results with a second, more precise
+// relevance pass before they reach the prompt. A vector search is a fast
+// recall step; a reranker reads the query together with each candidate and
+// scores the actual pairing, which a single embedding can't do.
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+)
+
+// Result represents the relevance judgement for one candidate document.
+type Result struct {
+	Index int     // Position of the document in the input slice.
+	Score float64 // Relevance in the range [0, 1], higher is better.
+}
+
+// Reranker scores the candidate documents against the query and returns
+// the results ordered from most to least relevant.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, documents []string) ([]Result, error)
+}
+
+// =============================================================================
+
+// Endpoint is a Reranker backed by a cross-encoder model served behind a
+// /v1/rerank endpoint, like llama.cpp serving a bge or jina reranker.
+type Endpoint struct {
+	url   string
+	model string
+	http  http.Client
+}
+
+// NewEndpoint constructs an Endpoint for the specified url and model.
+func NewEndpoint(url string, model string) *Endpoint {
+	ep := Endpoint{
+		url:   url,
+		model: model,
+	}
+
+	return &ep
+}
+
+// Rerank implements the Reranker interface.
+func (ep *Endpoint) Rerank(ctx context.Context, query string, documents []string) ([]Result, error) {
+	body := struct {
+		Model     string   `json:"model"`
+		Query     string   `json:"query"`
+		Documents []string `json:"documents"`
+	}{
+		Model:     ep.model,
+		Query:     query,
+		Documents: documents,
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ep.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Results []struct {
+			Index int     `json:"index"`
+			Score float64 `json:"relevance_score"`
+		} `json:"results"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	results := make([]Result, len(response.Results))
+	for i, res := range response.Results {
+		results[i] = Result{
+			Index: res.Index,
+			Score: res.Score,
+		}
+	}
+
+	sortResults(results)
+
+	return results, nil
+}
+
+// =============================================================================
+
+// LLMJudge is a Reranker that asks a chat model to score each document
+// against the query. Slower and noisier than a real cross-encoder, but it
+// works with the models the class already runs.
+type LLMJudge struct {
+	llm *client.LLM
+}
+
+// NewLLMJudge constructs an LLMJudge using the specified chat client.
+func NewLLMJudge(llm *client.LLM) *LLMJudge {
+	lj := LLMJudge{
+		llm: llm,
+	}
+
+	return &lj
+}
+
+// The scoring prompt for the model so the response stays parseable.
+const judgePrompt = `On a scale from 0 to 10, score how useful the DOCUMENT
+is for answering the QUERY. 0 means unrelated, 10 means it directly answers
+the query.
+
+QUERY:
+%s
+
+DOCUMENT:
+%s
+
+Respond with only the number.
+`
+
+// Rerank implements the Reranker interface.
+func (lj *LLMJudge) Rerank(ctx context.Context, query string, documents []string) ([]Result, error) {
+	results := make([]Result, len(documents))
+
+	for i, document := range documents {
+		prompt := fmt.Sprintf(judgePrompt, query, document)
+
+		response, err := lj.llm.ChatCompletions(ctx, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("chat completions: %w", err)
+		}
+
+		score, err := parseScore(response)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+
+		results[i] = Result{
+			Index: i,
+			Score: score / 10,
+		}
+	}
+
+	sortResults(results)
+
+	return results, nil
+}
+
+// =============================================================================
+
+// parseScore pulls the score out of the model response. Reasoning models
+// can produce chatter before the number, so take the last one found.
+func parseScore(response string) (float64, error) {
+	fields := strings.Fields(response)
+
+	for i := len(fields) - 1; i >= 0; i-- {
+		score, err := strconv.ParseFloat(strings.Trim(fields[i], ".,"), 64)
+		if err != nil {
+			continue
+		}
+
+		return min(max(score, 0), 10), nil
+	}
+
+	return 0, fmt.Errorf("no score in response %q", response)
+}
+
+func sortResults(results []Result) {
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+}
@@ -0,0 +1,120 @@
+// Package secrets loads credentials for the examples without hardcoding
+// them in source. Lookup order is the process environment, then a .env
+// file in the working directory, then the macOS keychain. Every value
+// the package hands out is registered for redaction so it never shows up
+// in log output.
+//
+// The .env file holds KEY=VALUE lines, with # comments and blank lines
+// ignored. Keychain entries are generic passwords under the service name
+// ai-training with the variable name as the account:
+//
+//	$ security add-generic-password -s ai-training -a MONGO_PASS -w
+package secrets
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	mu      sync.Mutex
+	dotEnv  map[string]string
+	secrets []string
+)
+
+// Get returns the named secret, or an empty string when it isn't set
+// anywhere. The result is registered for redaction.
+func Get(name string) string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if dotEnv == nil {
+		dotEnv = loadDotEnv()
+	}
+
+	value := os.Getenv(name)
+
+	if value == "" {
+		value = dotEnv[name]
+	}
+
+	if value == "" && runtime.GOOS == "darwin" {
+		value = keychain(name)
+	}
+
+	if value != "" {
+		register(value)
+	}
+
+	return value
+}
+
+// Redact replaces every secret this package has handed out with a mask,
+// so values can't leak through log messages.
+func Redact(s string) string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, "*****")
+	}
+
+	return s
+}
+
+// =============================================================================
+
+// register remembers a value for redaction. Callers must hold mu.
+func register(value string) {
+	for _, secret := range secrets {
+		if secret == value {
+			return
+		}
+	}
+
+	secrets = append(secrets, value)
+}
+
+// loadDotEnv parses the .env file in the working directory. A missing
+// file just means there is nothing to load.
+func loadDotEnv() map[string]string {
+	values := make(map[string]string)
+
+	f, err := os.Open(".env")
+	if err != nil {
+		return values
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+
+	return values
+}
+
+// keychain looks the name up as a generic password in the macOS keychain.
+func keychain(name string) string {
+	out, err := exec.Command("security", "find-generic-password", "-s", "ai-training", "-a", name, "-w").Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
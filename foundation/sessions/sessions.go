@@ -0,0 +1,56 @@
+// Package sessions persists the conversation history of a chat agent to
+// disk keyed by a session id, so a user can quit an agent example and
+// resume the same conversation later. Tool call results live in the
+// history like any other message, so they come back with it.
+package sessions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+)
+
+// Save writes the conversation history for the session id to the
+// specified directory, creating it as needed.
+func Save(dir string, sessionID string, messages []client.D) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create sessions dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(messages, "", "    ")
+	if err != nil {
+		return fmt.Errorf("marshal session: %w", err)
+	}
+
+	file := filepath.Join(dir, sessionID+".json")
+
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("write session: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads the conversation history for the session id. A session
+// that was never saved returns an empty history and no error.
+func Load(dir string, sessionID string) ([]client.D, error) {
+	file := filepath.Join(dir, sessionID+".json")
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read session: %w", err)
+	}
+
+	var messages []client.D
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("unmarshal session: %w", err)
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,140 @@
+// Package shell executes shell commands on behalf of a tool call with
+// the guard rails the raw handler in example10 deliberately leaves out:
+// an allowlist of binaries, pattern rules for their arguments, a
+// timeout, an output cap, and a dry-run mode.
+package shell
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Runner executes allowlisted commands. The zero value allows nothing,
+// so a command only runs if a rule was configured for its binary.
+type Runner struct {
+	rules     map[string][]*regexp.Regexp
+	timeout   time.Duration
+	maxOutput int
+	dryRun    bool
+}
+
+// NewRunner constructs a runner with the specified rules and limits.
+func NewRunner(options ...func(r *Runner)) *Runner {
+	r := Runner{
+		rules:     map[string][]*regexp.Regexp{},
+		timeout:   30 * time.Second,
+		maxOutput: 64 * 1024,
+	}
+
+	for _, option := range options {
+		option(&r)
+	}
+
+	return &r
+}
+
+// WithRule allows the specified binary to run. Every argument must match
+// at least one of the patterns; with no patterns the binary runs with
+// any arguments. Invalid patterns panic, the same as regexp.MustCompile,
+// since rules are written by the programmer, not the model.
+func WithRule(binary string, argPatterns ...string) func(r *Runner) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range argPatterns {
+		compiled = append(compiled, regexp.MustCompile(pattern))
+	}
+
+	return func(r *Runner) {
+		r.rules[binary] = compiled
+	}
+}
+
+// WithTimeout sets how long a command may run. It defaults to 30 seconds.
+func WithTimeout(timeout time.Duration) func(r *Runner) {
+	return func(r *Runner) {
+		r.timeout = timeout
+	}
+}
+
+// WithMaxOutput caps how many bytes of output are returned. It defaults
+// to 64K.
+func WithMaxOutput(maxBytes int) func(r *Runner) {
+	return func(r *Runner) {
+		r.maxOutput = maxBytes
+	}
+}
+
+// WithDryRun reports what would run without executing anything.
+func WithDryRun() func(r *Runner) {
+	return func(r *Runner) {
+		r.dryRun = true
+	}
+}
+
+// =============================================================================
+
+// Run validates the command against the rules and executes it, returning
+// the combined output. A command that is not allowlisted, times out, or
+// fails returns an error the tool can hand back to the model.
+func (r *Runner) Run(ctx context.Context, command []string) (string, error) {
+	if len(command) == 0 {
+		return "", fmt.Errorf("no command provided")
+	}
+
+	patterns, exists := r.rules[command[0]]
+	if !exists {
+		return "", fmt.Errorf("command %q is not allowlisted", command[0])
+	}
+
+	for _, arg := range command[1:] {
+		if !matchesAny(patterns, arg) {
+			return "", fmt.Errorf("argument %q is not allowed for command %q", arg, command[0])
+		}
+	}
+
+	if r.dryRun {
+		return fmt.Sprintf("dry-run: would execute %s", strings.Join(command, " ")), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("command %q exceeded the %s timeout", command[0], r.timeout)
+		}
+		return "", fmt.Errorf("command failed: %w: %s", err, out.String())
+	}
+
+	output := out.String()
+	if len(output) > r.maxOutput {
+		output = output[:r.maxOutput] + "\n... output truncated ..."
+	}
+
+	return output, nil
+}
+
+// matchesAny reports whether the argument matches one of the patterns.
+// A rule with no patterns allows any arguments.
+func matchesAny(patterns []*regexp.Regexp, arg string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		if pattern.MatchString(arg) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,34 +2,49 @@ package tiktoken
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/dlclark/regexp2"
 )
 
 type coreBPE struct {
 	encoder map[string]int
+	decoder map[int]string
 	tlRegex *regexp2.Regexp
 }
 
-func newCoreBPE() (*coreBPE, error) {
-	enc, err := cl100kBaseEncoding()
-	if err != nil {
-		return nil, fmt.Errorf("error loading base encoding model: %w", err)
-	}
-
+func newCoreBPE(enc *encoding) (*coreBPE, error) {
 	regex, err := regexp2.Compile(enc.PatStr, regexp2.None)
 	if err != nil {
 		return nil, fmt.Errorf("error compiling regex: %w", err)
 	}
 
+	decoder := make(map[int]string, len(enc.MergeableRanks))
+	for token, rank := range enc.MergeableRanks {
+		decoder[rank] = token
+	}
+
 	bp := coreBPE{
 		encoder: enc.MergeableRanks,
+		decoder: decoder,
 		tlRegex: regex,
 	}
 
 	return &bp, nil
 }
 
+// decodeNative concatenates the raw bytes each token id represents. Ids
+// that aren't in the vocabulary are skipped.
+func (bp *coreBPE) decodeNative(tokens []int) string {
+	var b strings.Builder
+
+	for _, token := range tokens {
+		b.WriteString(bp.decoder[token])
+	}
+
+	return b.String()
+}
+
 func (bp *coreBPE) encodeNative(text string) ([]int, int) {
 	regex := bp.tlRegex
 	ret := []int{}
@@ -55,6 +70,106 @@ func (bp *coreBPE) encodeNative(text string) ([]int, int) {
 	return ret, lastPieceTokenLen
 }
 
+// tokenPiece represents a single regex match of the text with its rune
+// offsets and the number of tokens it encodes to.
+type tokenPiece struct {
+	start  int
+	end    int
+	tokens int
+}
+
+// pieces walks the text one regex match at a time and returns every piece
+// with its token count. Pieces are whole regex matches so any cut made
+// between pieces lands on a rune boundary.
+func (bp *coreBPE) pieces(text string) []tokenPiece {
+	textRunes := []rune(text)
+
+	var pieces []tokenPiece
+
+	for _, mat := range findRegex2AllStringMatchIndex(text, bp.tlRegex) {
+		piece := cutRunes(textRunes, mat[0], mat[1])
+
+		count := 1
+		if _, ok := bp.encoder[piece]; !ok {
+			count = len(bytePairEncode([]byte(piece), bp.encoder))
+		}
+
+		pieces = append(pieces, tokenPiece{
+			start:  mat[0],
+			end:    mat[1],
+			tokens: count,
+		})
+	}
+
+	return pieces
+}
+
+// truncateNative returns the prefix of the text that fits inside the token
+// budget.
+func (bp *coreBPE) truncateNative(text string, maxTokens int) string {
+	textRunes := []rune(text)
+
+	var used int
+	var keep int
+
+	for _, piece := range bp.pieces(text) {
+		if used+piece.tokens > maxTokens {
+			break
+		}
+
+		used += piece.tokens
+		keep = piece.end
+	}
+
+	return cutRunes(textRunes, 0, keep)
+}
+
+// splitNative breaks the text into chunks that each fit inside the chunk
+// token budget, repeating the last overlap tokens of a chunk at the start
+// of the next so context isn't lost at the boundary.
+func (bp *coreBPE) splitNative(text string, chunkTokens int, overlapTokens int) []string {
+	textRunes := []rune(text)
+	pieces := bp.pieces(text)
+
+	var chunks []string
+
+	start := 0
+	for start < len(pieces) {
+		var used int
+		end := start
+
+		for end < len(pieces) {
+			if end > start && used+pieces[end].tokens > chunkTokens {
+				break
+			}
+
+			used += pieces[end].tokens
+			end++
+		}
+
+		chunks = append(chunks, cutRunes(textRunes, pieces[start].start, pieces[end-1].end))
+
+		if end == len(pieces) {
+			break
+		}
+
+		// Back up enough pieces to repeat the overlap at the start of the
+		// next chunk. Never back up past the piece after the start of the
+		// current chunk so the split always makes progress.
+		next := end
+
+		var overlap int
+		for next > start+1 && overlap < overlapTokens {
+			overlap += pieces[next-1].tokens
+			next--
+		}
+
+		start = next
+	}
+
+	return chunks
+}
+
 func findRegex2AllStringMatchIndex(text string, reg *regexp2.Regexp) [][]int {
 	var matches [][]int
 
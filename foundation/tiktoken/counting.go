@@ -0,0 +1,103 @@
+package tiktoken
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ardanlabs/ai-training/foundation/client"
+)
+
+// Token accounting constants. These mirror the overhead the chat template
+// adds around the content of every message.
+const (
+	tokensPerMessage = 4  // Framing tokens added around every message.
+	tokensPerName    = 1  // A name field rides along with the role.
+	tokensPerReply   = 3  // Every reply is primed with assistant framing.
+	tokensPerImage   = 85 // Flat estimate for a low detail image.
+)
+
+// CountMessages counts the tokens a conversation will occupy, including the
+// per message framing the chat template adds. Content may be a plain string
+// or the structured content parts the vision and tooling examples use.
+func (t *Tiktoken) CountMessages(messages []client.D) int {
+	var total int
+
+	for _, msg := range messages {
+		total += tokensPerMessage
+
+		for key, value := range msg {
+			total += t.countValue(value)
+
+			// A name field costs an extra token of framing on top of
+			// its content.
+			if key == "name" {
+				total += tokensPerName
+			}
+		}
+	}
+
+	return total + tokensPerReply
+}
+
+// CountTools estimates the token footprint of the tool definitions an
+// agent sends with every request. Tool schemas go over the wire as JSON
+// inside the chat template, so counting the JSON is a close estimate.
+func (t *Tiktoken) CountTools(tools []client.D) int {
+	var total int
+
+	for _, tool := range tools {
+		total += tokensPerMessage + t.countValue(tool)
+	}
+
+	return total
+}
+
+// countValue counts a single field of a message. Images are counted at a
+// flat estimate since their token cost depends on the model's vision
+// preprocessor, not on the bytes in the url.
+func (t *Tiktoken) countValue(value any) int {
+	switch v := value.(type) {
+	case string:
+		return t.TokenCount(v)
+
+	case client.D:
+		if typ, ok := v["type"].(string); ok && typ == "image_url" {
+			return tokensPerImage
+		}
+
+		var total int
+		for _, item := range v {
+			total += t.countValue(item)
+		}
+		return total
+
+	case map[string]any:
+		return t.countValue(client.D(v))
+
+	case []client.D:
+		var total int
+		for _, item := range v {
+			total += t.countValue(item)
+		}
+		return total
+
+	case []any:
+		var total int
+		for _, item := range v {
+			total += t.countValue(item)
+		}
+		return total
+
+	case nil:
+		return 0
+
+	default:
+		// Anything else (tool calls, numbers, bools) goes over the wire
+		// as JSON, so count the JSON.
+		data, err := json.Marshal(v)
+		if err != nil {
+			return t.TokenCount(fmt.Sprintf("%v", v))
+		}
+		return t.TokenCount(string(data))
+	}
+}
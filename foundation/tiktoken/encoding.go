@@ -5,11 +5,15 @@ import (
 	_ "embed"
 	"encoding/gob"
 	"fmt"
+	"strings"
 )
 
 //go:embed cl100k.gob
 var cl100k []byte
 
+//go:embed o200k.gob
+var o200k []byte
+
 // -----------------------------------------------------------------------------
 
 type encoding struct {
@@ -52,3 +56,45 @@ func cl100kBaseEncoding() (*encoding, error) {
 
 	return &enc, nil
 }
+
+func o200kBaseEncoding() (*encoding, error) {
+	const (
+		endOfText   string = "<|endoftext|>"
+		endOfPrompt string = "<|endofprompt|>"
+	)
+
+	const modelO200KBase string = "o200k_base"
+
+	specialTokens := map[string]int{
+		endOfText:   199999,
+		endOfPrompt: 200018,
+	}
+
+	var vocabO200K map[string]int
+	if err := gob.NewDecoder(bytes.NewReader(o200k)).Decode(&vocabO200K); err != nil {
+		return nil, fmt.Errorf("decoding: %w", err)
+	}
+
+	enc := encoding{
+		Name:           modelO200KBase,
+		PatStr:         `[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?|[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+(?!\S)|\s+`,
+		MergeableRanks: vocabO200K,
+		SpecialTokens:  specialTokens,
+	}
+
+	return &enc, nil
+}
+
+// encodingForModel maps the model names the examples use to the encoding
+// the model family is built on. Models we don't recognize fall back to the
+// cl100k base encoding, which is close enough for context accounting.
+func encodingForModel(model string) (*encoding, error) {
+	switch model = strings.ToLower(model); {
+	case strings.Contains(model, "gpt-oss"),
+		strings.Contains(model, "gpt-4o"),
+		strings.Contains(model, "o200k"):
+		return o200kBaseEncoding()
+	}
+
+	return cl100kBaseEncoding()
+}
@@ -4,7 +4,9 @@
 	syntax where possible.
 */
 
-// Package tiktoken provides support for GPT-3+ token counting.
+// Package tiktoken provides support for GPT-3+ token counting. The
+// vocabularies are embedded in the binary so token counting works in the
+// offline classroom environments, nothing is fetched at runtime.
 package tiktoken
 
 import (
@@ -16,8 +18,42 @@ type Tiktoken struct {
 	bpe *coreBPE
 }
 
+// NewTiktoken constructs a Tiktoken using the cl100k base encoding.
 func NewTiktoken() (*Tiktoken, error) {
-	bpe, err := newCoreBPE()
+	enc, err := cl100kBaseEncoding()
+	if err != nil {
+		return nil, fmt.Errorf("error loading base encoding model: %w", err)
+	}
+
+	return newTiktoken(enc)
+}
+
+// NewTiktokenForModel constructs a Tiktoken using the encoding that matches
+// the specified model so token counts line up with what the model server
+// actually sees.
+func NewTiktokenForModel(model string) (*Tiktoken, error) {
+	enc, err := encodingForModel(model)
+	if err != nil {
+		return nil, fmt.Errorf("error loading encoding for model %s: %w", model, err)
+	}
+
+	return newTiktoken(enc)
+}
+
+// NewTiktokenFromTokenizerFile constructs a Tiktoken from a huggingface
+// tokenizer.json file for models that don't use one of the OpenAI
+// encodings.
+func NewTiktokenFromTokenizerFile(fileName string) (*Tiktoken, error) {
+	enc, err := tokenizerFileEncoding(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("error loading tokenizer file: %w", err)
+	}
+
+	return newTiktoken(enc)
+}
+
+func newTiktoken(enc *encoding) (*Tiktoken, error) {
+	bpe, err := newCoreBPE(enc)
 	if err != nil {
 		return nil, fmt.Errorf("new core bpe: %w", err)
 	}
@@ -33,3 +69,37 @@ func (t *Tiktoken) TokenCount(text string) int {
 	tokens, _ := t.bpe.encodeNative(text)
 	return len(tokens)
 }
+
+// Encode returns the token ids for the text.
+func (t *Tiktoken) Encode(text string) []int {
+	tokens, _ := t.bpe.encodeNative(text)
+	return tokens
+}
+
+// Decode returns the text the token ids represent. This is handy for
+// showing exactly where a chunk gets cut when it goes over budget.
+func (t *Tiktoken) Decode(tokens []int) string {
+	return t.bpe.decodeNative(tokens)
+}
+
+// Truncate cuts the text down to the specified token budget. The cut is
+// made on a whole token piece so the result is always valid utf-8.
+func (t *Tiktoken) Truncate(text string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+
+	return t.bpe.truncateNative(text, maxTokens)
+}
+
+// Split breaks the text into chunks that each fit inside the chunk token
+// budget. The last overlap tokens of a chunk are repeated at the start of
+// the next chunk so context isn't lost at the boundary. Cuts are made on
+// whole token pieces so every chunk is valid utf-8.
+func (t *Tiktoken) Split(text string, chunkTokens int, overlapTokens int) []string {
+	if chunkTokens <= 0 {
+		return nil
+	}
+
+	return t.bpe.splitNative(text, chunkTokens, overlapTokens)
+}
@@ -0,0 +1,98 @@
+package tiktoken
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// tokenizerFileEncoding builds an encoding from a huggingface tokenizer.json
+// file. The vocab in these files is stored in the byte level representation
+// GPT-2 style tokenizers use, so each token is mapped back to its raw bytes
+// before it can be used as a mergeable rank.
+func tokenizerFileEncoding(fileName string) (*encoding, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	var doc struct {
+		Model struct {
+			Vocab map[string]int `json:"vocab"`
+		} `json:"model"`
+		AddedTokens []struct {
+			ID      int    `json:"id"`
+			Content string `json:"content"`
+		} `json:"added_tokens"`
+	}
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	if len(doc.Model.Vocab) == 0 {
+		return nil, fmt.Errorf("no vocab found in %s", fileName)
+	}
+
+	charToByte := byteLevelChars()
+
+	ranks := make(map[string]int, len(doc.Model.Vocab))
+
+	for token, id := range doc.Model.Vocab {
+		raw := make([]byte, 0, len(token))
+
+		ok := true
+		for _, r := range token {
+			b, found := charToByte[r]
+			if !found {
+				ok = false
+				break
+			}
+			raw = append(raw, b)
+		}
+
+		// Tokens with characters outside the byte level alphabet are
+		// special tokens that leaked into the vocab. Skip them.
+		if !ok {
+			continue
+		}
+
+		ranks[string(raw)] = id
+	}
+
+	specialTokens := make(map[string]int, len(doc.AddedTokens))
+	for _, token := range doc.AddedTokens {
+		specialTokens[token.Content] = token.ID
+	}
+
+	enc := encoding{
+		Name:           fileName,
+		PatStr:         `(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+`,
+		MergeableRanks: ranks,
+		SpecialTokens:  specialTokens,
+	}
+
+	return &enc, nil
+}
+
+// byteLevelChars returns the unicode code point GPT-2 style tokenizers use
+// to represent each byte value. Printable bytes represent themselves and the
+// rest are shifted up past the printable range.
+func byteLevelChars() map[rune]byte {
+	chars := make(map[rune]byte, 256)
+
+	var shifted int
+	for b := 0; b < 256; b++ {
+		printable := (b >= '!' && b <= '~') || (b >= 0xA1 && b <= 0xAC) || (b >= 0xAE && b <= 0xFF)
+
+		r := rune(b)
+		if !printable {
+			r = rune(256 + shifted)
+			shifted++
+		}
+
+		chars[r] = byte(b)
+	}
+
+	return chars
+}
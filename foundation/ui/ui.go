@@ -0,0 +1,143 @@
+// Package ui provides the small terminal rendering pieces the examples
+// share: an in-place progress counter, a spinner, and an elapsed-time
+// ticker. Each example grew its own copy of the cursor save/restore
+// escape codes and the latency goroutine; this package is that code in
+// one place.
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Progress renders an in-place counter on a single terminal line using
+// cursor save/restore, the way the ingestion loops report their position:
+//
+//	p := ui.NewProgress(os.Stdout, "Vectorizing Data", len(chunks))
+//	for i, chunk := range chunks {
+//		p.Update(i)
+//		...
+//	}
+//	p.Done()
+type Progress struct {
+	w     io.Writer
+	label string
+	total int
+}
+
+// NewProgress constructs a progress counter and saves the cursor
+// position the updates will render at. A zero total renders just the
+// count for loops that don't know their length up front.
+func NewProgress(w io.Writer, label string, total int) *Progress {
+	fmt.Fprint(w, "\n\033[s")
+
+	return &Progress{
+		w:     w,
+		label: label,
+		total: total,
+	}
+}
+
+// Update rewrites the progress line with the specified count.
+func (p *Progress) Update(n int) {
+	fmt.Fprint(p.w, "\033[u\033[K")
+
+	if p.total > 0 {
+		fmt.Fprintf(p.w, "%s: %d of %d", p.label, n, p.total)
+		return
+	}
+
+	fmt.Fprintf(p.w, "%s: %d", p.label, n)
+}
+
+// Done moves off the progress line.
+func (p *Progress) Done() {
+	fmt.Fprint(p.w, "\n")
+}
+
+// =============================================================================
+
+// Spinner starts a goroutine that animates a spinner next to the label
+// while some unbounded work runs. The returned function stops the
+// animation and is safe to call multiple times.
+func Spinner(w io.Writer, label string) (stop func()) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+		})
+	}
+
+	go func() {
+		defer ticker.Stop()
+		defer close(exited)
+
+		frames := `|/-\`
+		frame := 0
+
+		for {
+			select {
+			case <-ticker.C:
+				fmt.Fprintf(w, "\r%s %c", label, frames[frame%len(frames)])
+				frame++
+
+			case <-done:
+				fmt.Fprint(w, "\n")
+				return
+			}
+		}
+	}()
+
+	return stop
+}
+
+// Elapsed starts a goroutine that displays elapsed time next to the
+// label while waiting on a model, matching the latency printer the
+// agents use. The returned function stops the printer; it is safe to
+// call multiple times.
+func Elapsed(ctx context.Context, w io.Writer, label string) (stop func()) {
+	start := time.Now()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+		})
+	}
+
+	go func() {
+		defer ticker.Stop()
+		defer close(exited)
+
+		for {
+			select {
+			case <-ticker.C:
+				m := time.Since(start).Milliseconds()
+				fmt.Fprintf(w, "\r\u001b[93m%s %d.%03d\u001b[0m:  ", label, m/1000, m%1000)
+
+			case <-done:
+				fmt.Fprint(w, "\n")
+				return
+
+			case <-ctx.Done():
+				fmt.Fprint(w, "\n")
+				return
+			}
+		}
+	}()
+
+	return stop
+}
@@ -0,0 +1,88 @@
+// Benchmarks for the vector math across the dimensions used by common
+// embedding models. Run them with:
+//
+//	$ make vector-bench
+
+package vector
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+var dimensions = []int{256, 512, 768, 1024, 1536}
+
+// sink keeps the compiler from optimizing the benchmarked calls away.
+var sink float64
+
+func BenchmarkCosineSimilarity(b *testing.B) {
+	for _, dim := range dimensions {
+		x := randVector(dim, 1)
+		y := randVector(dim, 2)
+
+		b.Run(fmt.Sprintf("float64-%d", dim), func(b *testing.B) {
+			for b.Loop() {
+				sink = CosineSimilarity(x, y)
+			}
+		})
+	}
+}
+
+func BenchmarkCosineSimilarity32(b *testing.B) {
+	for _, dim := range dimensions {
+		x := randVector32(dim, 1)
+		y := randVector32(dim, 2)
+
+		b.Run(fmt.Sprintf("float32-%d", dim), func(b *testing.B) {
+			for b.Loop() {
+				sink = float64(CosineSimilarity32(x, y))
+			}
+		})
+	}
+}
+
+// BenchmarkScan measures a brute force similarity scan of a query against
+// a corpus, which is what a vector search without an index does.
+func BenchmarkScan(b *testing.B) {
+	const corpusSize = 10_000
+
+	for _, dim := range dimensions {
+		query := randVector(dim, 1)
+
+		corpus := make([][]float64, corpusSize)
+		for i := range corpus {
+			corpus[i] = randVector(dim, int64(i+2))
+		}
+
+		b.Run(fmt.Sprintf("scan-%d", dim), func(b *testing.B) {
+			for b.Loop() {
+				for _, doc := range corpus {
+					sink = CosineSimilarity(query, doc)
+				}
+			}
+		})
+	}
+}
+
+func randVector(dim int, seed int64) []float64 {
+	rng := rand.New(rand.NewSource(seed))
+
+	v := make([]float64, dim)
+	for i := range v {
+		v[i] = rng.Float64()*2 - 1
+	}
+
+	return v
+}
+
+func randVector32(dim int, seed int64) []float32 {
+	rng := rand.New(rand.NewSource(seed))
+
+	v := make([]float32, dim)
+	for i := range v {
+		v[i] = rng.Float32()*2 - 1
+	}
+
+	return v
+}
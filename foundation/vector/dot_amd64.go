@@ -0,0 +1,21 @@
+//go:build amd64
+
+package vector
+
+import "golang.org/x/sys/cpu"
+
+// dotAccelerated reports whether the assembly dot product kernel can run
+// on this CPU. The kernel needs AVX2 for the 4 wide loads and FMA for the
+// fused multiply-add.
+var dotAccelerated = cpu.X86.HasAVX2 && cpu.X86.HasFMA
+
+// dotProduct computes the dot product of two equal length vectors with
+// the AVX2 kernel. Only call this when dotAccelerated reports true.
+func dotProduct(x, y []float64) float64 {
+	return dotAVX2(x, y)
+}
+
+// dotAVX2 is implemented in dot_amd64.s.
+//
+//go:noescape
+func dotAVX2(x, y []float64) float64
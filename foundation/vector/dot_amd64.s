@@ -0,0 +1,51 @@
+//go:build amd64
+
+#include "textflag.h"
+
+// func dotAVX2(x []float64, y []float64) float64
+//
+// Processes 4 doubles per iteration with fused multiply-add, then sums the
+// accumulator lanes and finishes any remainder one element at a time. Both
+// slices must have the same length.
+TEXT ·dotAVX2(SB), NOSPLIT, $0-56
+	MOVQ x_base+0(FP), SI
+	MOVQ y_base+24(FP), DI
+	MOVQ x_len+8(FP), CX
+
+	VXORPD Y0, Y0, Y0
+
+	MOVQ CX, BX
+	SHRQ $2, BX
+	JZ   tail
+
+loop4:
+	VMOVUPD     (SI), Y1
+	VMOVUPD     (DI), Y2
+	VFMADD231PD Y2, Y1, Y0
+	ADDQ        $32, SI
+	ADDQ        $32, DI
+	DECQ        BX
+	JNZ         loop4
+
+tail:
+	// Sum the four accumulator lanes into the low lane of X0.
+	VEXTRACTF128 $1, Y0, X1
+	VADDPD       X1, X0, X0
+	VHADDPD      X0, X0, X0
+
+	ANDQ $3, CX
+	JZ   done
+
+rem:
+	VMOVSD      (SI), X2
+	VMOVSD      (DI), X3
+	VFMADD231SD X3, X2, X0
+	ADDQ        $8, SI
+	ADDQ        $8, DI
+	DECQ        CX
+	JNZ         rem
+
+done:
+	VMOVSD X0, ret+48(FP)
+	VZEROUPPER
+	RET
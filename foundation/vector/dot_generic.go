@@ -0,0 +1,13 @@
+//go:build !amd64
+
+package vector
+
+// dotAccelerated reports whether an assembly dot product kernel exists
+// for this architecture.
+const dotAccelerated = false
+
+// dotProduct falls back to the scalar loop on architectures without an
+// assembly kernel.
+func dotProduct(x, y []float64) float64 {
+	return dotScalar(x, y)
+}
@@ -0,0 +1,49 @@
+package vector
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// TestDotProduct checks the assembly kernel against the scalar loop for a
+// spread of lengths, including ones that exercise the remainder path.
+func TestDotProduct(t *testing.T) {
+	if !dotAccelerated {
+		t.Skip("no assembly kernel on this CPU")
+	}
+
+	for _, dim := range []int{1, 2, 3, 4, 5, 7, 8, 255, 256, 768, 1536} {
+		x := randVector(dim, 1)
+		y := randVector(dim, 2)
+
+		got := dotProduct(x, y)
+		want := dotScalar(x, y)
+
+		// The kernel sums in a different order, so allow for rounding.
+		if diff := math.Abs(got - want); diff > 1e-9 {
+			t.Errorf("dim %d: got %v, want %v (diff %v)", dim, got, want, diff)
+		}
+	}
+}
+
+func BenchmarkDotProduct(b *testing.B) {
+	for _, dim := range dimensions {
+		x := randVector(dim, 1)
+		y := randVector(dim, 2)
+
+		b.Run(fmt.Sprintf("scalar-%d", dim), func(b *testing.B) {
+			for b.Loop() {
+				sink = dotScalar(x, y)
+			}
+		})
+
+		if dotAccelerated {
+			b.Run(fmt.Sprintf("kernel-%d", dim), func(b *testing.B) {
+				for b.Loop() {
+					sink = dotProduct(x, y)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package vector
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// HNSW is a small in-memory hierarchical navigable small world index for
+// approximate nearest neighbor search by cosine similarity. Every vector
+// lands in layer 0 and a shrinking fraction in each layer above, so a
+// search drops through the sparse layers to a good starting spot and only
+// walks the dense bottom layer locally. It trades a little recall for
+// sub-linear search, which is what makes tens of thousands of chunks
+// workable without a database.
+type HNSW struct {
+	m              int // Max connections per node per layer.
+	efConstruction int // Candidate list size while inserting.
+	efSearch       int // Candidate list size while searching.
+	levelMult      float64
+	mu             sync.RWMutex
+	nodes          []hnswNode
+	entry          int
+	maxLayer       int
+	rng            *rand.Rand
+}
+
+type hnswNode struct {
+	Vector    []float64 `json:"vector"`
+	Neighbors [][]int   `json:"neighbors"`
+}
+
+// NewHNSW constructs an index with sensible classroom defaults.
+func NewHNSW(options ...func(h *HNSW)) *HNSW {
+	h := HNSW{
+		m:              16,
+		efConstruction: 200,
+		efSearch:       50,
+
+		// Seeded so index builds are reproducible between runs.
+		rng: rand.New(rand.NewSource(1)),
+	}
+
+	for _, option := range options {
+		option(&h)
+	}
+
+	h.levelMult = 1 / math.Log(float64(h.m))
+
+	return &h
+}
+
+// WithM sets the max connections per node per layer. Higher improves
+// recall and costs memory.
+func WithM(m int) func(h *HNSW) {
+	return func(h *HNSW) {
+		h.m = m
+	}
+}
+
+// WithEFConstruction sets the candidate list size used while inserting.
+func WithEFConstruction(ef int) func(h *HNSW) {
+	return func(h *HNSW) {
+		h.efConstruction = ef
+	}
+}
+
+// WithEFSearch sets the candidate list size used while searching. Higher
+// improves recall and costs time.
+func WithEFSearch(ef int) func(h *HNSW) {
+	return func(h *HNSW) {
+		h.efSearch = ef
+	}
+}
+
+// =============================================================================
+
+// Insert adds the vector to the index and returns its id, which Search
+// reports back as the Neighbor index.
+func (h *HNSW) Insert(vec []float64) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id := len(h.nodes)
+
+	// Draw the node's top layer: each layer up keeps roughly 1/m of the
+	// nodes below it.
+	level := int(math.Floor(-math.Log(h.rng.Float64()) * h.levelMult))
+
+	h.nodes = append(h.nodes, hnswNode{
+		Vector:    vec,
+		Neighbors: make([][]int, level+1),
+	})
+
+	if id == 0 {
+		h.entry = 0
+		h.maxLayer = level
+		return id
+	}
+
+	// Drop through the layers above the node's level, greedily moving to
+	// the closest node in each.
+	ep := h.entry
+	for layer := h.maxLayer; layer > level; layer-- {
+		ep = h.greedyClosest(vec, ep, layer)
+	}
+
+	// From the node's level down, pick its neighbors from a wider
+	// candidate search and link both directions.
+	eps := []Neighbor{{Index: ep, Score: CosineSimilarity(vec, h.nodes[ep].Vector)}}
+
+	for layer := min(level, h.maxLayer); layer >= 0; layer-- {
+		results := h.searchLayer(vec, eps, h.efConstruction, layer)
+
+		neighbors := make([]int, 0, min(len(results), h.m))
+		for _, n := range results[:min(len(results), h.m)] {
+			neighbors = append(neighbors, n.Index)
+		}
+
+		h.nodes[id].Neighbors[layer] = neighbors
+
+		for _, n := range neighbors {
+			h.link(n, id, layer)
+		}
+
+		eps = results
+	}
+
+	if level > h.maxLayer {
+		h.maxLayer = level
+		h.entry = id
+	}
+
+	return id
+}
+
+// Search returns the k approximate nearest vectors to the query, ordered
+// best first.
+func (h *HNSW) Search(query []float64, k int) []Neighbor {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.nodes) == 0 || k <= 0 {
+		return nil
+	}
+
+	ep := h.entry
+	for layer := h.maxLayer; layer >= 1; layer-- {
+		ep = h.greedyClosest(query, ep, layer)
+	}
+
+	eps := []Neighbor{{Index: ep, Score: CosineSimilarity(query, h.nodes[ep].Vector)}}
+
+	results := h.searchLayer(query, eps, max(h.efSearch, k), 0)
+
+	if len(results) > k {
+		results = results[:k]
+	}
+
+	return results
+}
+
+// Len returns the number of vectors in the index.
+func (h *HNSW) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.nodes)
+}
+
+// =============================================================================
+
+// Save writes the index to disk as JSON.
+func (h *HNSW) Save(path string) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	state := hnswState{
+		M:              h.m,
+		EFConstruction: h.efConstruction,
+		EFSearch:       h.efSearch,
+		Entry:          h.entry,
+		MaxLayer:       h.maxLayer,
+		Nodes:          h.nodes,
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshal index: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create index dir: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write index: %w", err)
+	}
+
+	return nil
+}
+
+// LoadHNSW reads an index previously written by Save.
+func LoadHNSW(path string) (*HNSW, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read index: %w", err)
+	}
+
+	var state hnswState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("unmarshal index: %w", err)
+	}
+
+	h := NewHNSW(WithM(state.M), WithEFConstruction(state.EFConstruction), WithEFSearch(state.EFSearch))
+	h.entry = state.Entry
+	h.maxLayer = state.MaxLayer
+	h.nodes = state.Nodes
+
+	return h, nil
+}
+
+type hnswState struct {
+	M              int        `json:"m"`
+	EFConstruction int        `json:"ef_construction"`
+	EFSearch       int        `json:"ef_search"`
+	Entry          int        `json:"entry"`
+	MaxLayer       int        `json:"max_layer"`
+	Nodes          []hnswNode `json:"nodes"`
+}
+
+// =============================================================================
+
+// greedyClosest walks the layer from ep, always moving to the neighbor
+// closest to the query, until no neighbor improves.
+func (h *HNSW) greedyClosest(query []float64, ep int, layer int) int {
+	best := ep
+	bestScore := CosineSimilarity(query, h.nodes[ep].Vector)
+
+	for improved := true; improved; {
+		improved = false
+
+		for _, n := range h.nodes[best].Neighbors[layer] {
+			if score := CosineSimilarity(query, h.nodes[n].Vector); score > bestScore {
+				best = n
+				bestScore = score
+				improved = true
+			}
+		}
+	}
+
+	return best
+}
+
+// searchLayer runs the beam search over one layer: expand the best
+// unexplored candidate until none can beat the worst of the ef results
+// collected so far. Results come back ordered best first.
+func (h *HNSW) searchLayer(query []float64, eps []Neighbor, ef int, layer int) []Neighbor {
+	visited := make(map[int]bool)
+
+	var candidates maxHeap
+	results := make(minHeap, 0, ef)
+
+	for _, ep := range eps {
+		if visited[ep.Index] {
+			continue
+		}
+		visited[ep.Index] = true
+
+		candidates.push(ep)
+		results.push(ep)
+	}
+
+	for len(candidates) > 0 {
+		c := candidates.pop()
+
+		if len(results) == ef && c.Score < results[0].Score {
+			break
+		}
+
+		for _, n := range h.nodes[c.Index].Neighbors[layer] {
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+
+			score := CosineSimilarity(query, h.nodes[n].Vector)
+
+			if len(results) < ef {
+				candidates.push(Neighbor{Index: n, Score: score})
+				results.push(Neighbor{Index: n, Score: score})
+				continue
+			}
+
+			if score > results[0].Score {
+				candidates.push(Neighbor{Index: n, Score: score})
+				results[0] = Neighbor{Index: n, Score: score}
+				results.down(0)
+			}
+		}
+	}
+
+	ordered := make([]Neighbor, len(results))
+	copy(ordered, results)
+	sortNeighbors(ordered)
+
+	return ordered
+}
+
+// link adds to as a neighbor of from at the layer, shrinking the list back
+// to the best connections when it grows past the cap. Layer 0 allows twice
+// the connections of the layers above, per the paper.
+func (h *HNSW) link(from int, to int, layer int) {
+	maxConns := h.m
+	if layer == 0 {
+		maxConns = 2 * h.m
+	}
+
+	neighbors := append(h.nodes[from].Neighbors[layer], to)
+
+	if len(neighbors) > maxConns {
+		scored := make([]Neighbor, len(neighbors))
+		for i, n := range neighbors {
+			scored[i] = Neighbor{Index: n, Score: CosineSimilarity(h.nodes[from].Vector, h.nodes[n].Vector)}
+		}
+
+		sortNeighbors(scored)
+
+		neighbors = neighbors[:0]
+		for _, n := range scored[:maxConns] {
+			neighbors = append(neighbors, n.Index)
+		}
+	}
+
+	h.nodes[from].Neighbors[layer] = neighbors
+}
+
+// maxHeap keeps the best unexplored candidate at the root.
+type maxHeap []Neighbor
+
+func (h *maxHeap) push(n Neighbor) {
+	*h = append(*h, n)
+
+	i := len(*h) - 1
+	for i > 0 {
+		parent := (i - 1) / 2
+		if (*h)[parent].Score >= (*h)[i].Score {
+			break
+		}
+		(*h)[parent], (*h)[i] = (*h)[i], (*h)[parent]
+		i = parent
+	}
+}
+
+func (h *maxHeap) pop() Neighbor {
+	old := *h
+	top := old[0]
+
+	old[0] = old[len(old)-1]
+	old = old[:len(old)-1]
+	*h = old
+
+	for i := 0; ; {
+		left := 2*i + 1
+		if left >= len(old) {
+			break
+		}
+
+		largest := left
+		if right := left + 1; right < len(old) && old[right].Score > old[left].Score {
+			largest = right
+		}
+
+		if old[i].Score >= old[largest].Score {
+			break
+		}
+
+		old[i], old[largest] = old[largest], old[i]
+		i = largest
+	}
+
+	return top
+}
@@ -0,0 +1,84 @@
+package vector
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestHNSWRecall builds an index over a random corpus and checks the
+// approximate results overlap heavily with an exact TopK scan.
+func TestHNSWRecall(t *testing.T) {
+	const corpusSize = 2_000
+	const dim = 64
+	const k = 10
+
+	corpus := make([][]float64, corpusSize)
+	for i := range corpus {
+		corpus[i] = randVector(dim, int64(i+2))
+	}
+
+	h := NewHNSW()
+	for _, vec := range corpus {
+		h.Insert(vec)
+	}
+
+	var hits int
+	const queries = 20
+
+	for q := range queries {
+		query := randVector(dim, int64(corpusSize+q+2))
+
+		exact := make(map[int]bool, k)
+		for _, n := range TopK(query, corpus, k) {
+			exact[n.Index] = true
+		}
+
+		for _, n := range h.Search(query, k) {
+			if exact[n.Index] {
+				hits++
+			}
+		}
+	}
+
+	recall := float64(hits) / float64(queries*k)
+	if recall < 0.9 {
+		t.Errorf("recall %.2f, want at least 0.90", recall)
+	}
+}
+
+func TestHNSWSaveLoad(t *testing.T) {
+	const corpusSize = 200
+	const dim = 32
+	const k = 5
+
+	h := NewHNSW()
+	for i := range corpusSize {
+		h.Insert(randVector(dim, int64(i+2)))
+	}
+
+	path := filepath.Join(t.TempDir(), "index.json")
+
+	if err := h.Save(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded, err := LoadHNSW(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	if loaded.Len() != h.Len() {
+		t.Fatalf("got %d vectors, want %d", loaded.Len(), h.Len())
+	}
+
+	query := randVector(dim, 1)
+
+	want := h.Search(query, k)
+	got := loaded.Search(query, k)
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("result %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
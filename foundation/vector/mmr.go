@@ -0,0 +1,52 @@
+package vector
+
+import "math"
+
+// MMR selects k of the candidates by maximal marginal relevance: each pick
+// maximizes lambda times the relevance to the query minus (1-lambda) times
+// the similarity to anything already picked. Lambda 1 is pure relevance,
+// lambda 0 pure diversity. Retrieval often returns near duplicates of the
+// best chunk; this keeps the relevant ones that say something new.
+//
+// Results come back in selection order and Score carries the relevance to
+// the query, not the marginal score, so callers can still gate on it.
+func MMR(query []float64, candidates [][]float64, k int, lambda float64) []Neighbor {
+	k = min(k, len(candidates))
+	if k <= 0 {
+		return nil
+	}
+
+	relevance := make([]float64, len(candidates))
+	for i, c := range candidates {
+		relevance[i] = CosineSimilarity(query, c)
+	}
+
+	selected := make([]Neighbor, 0, k)
+	used := make([]bool, len(candidates))
+
+	for len(selected) < k {
+		best := -1
+		bestScore := math.Inf(-1)
+
+		for i := range candidates {
+			if used[i] {
+				continue
+			}
+
+			var redundancy float64
+			for _, s := range selected {
+				redundancy = max(redundancy, CosineSimilarity(candidates[i], candidates[s.Index]))
+			}
+
+			if score := lambda*relevance[i] - (1-lambda)*redundancy; score > bestScore {
+				best = i
+				bestScore = score
+			}
+		}
+
+		used[best] = true
+		selected = append(selected, Neighbor{Index: best, Score: relevance[best]})
+	}
+
+	return selected
+}
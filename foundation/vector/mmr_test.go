@@ -0,0 +1,36 @@
+package vector
+
+import "testing"
+
+// TestMMR checks that a near duplicate of the best candidate loses its
+// spot to a less relevant but novel one.
+func TestMMR(t *testing.T) {
+	query := []float64{1, 0, 0}
+
+	candidates := [][]float64{
+		{1, 1, 0},    // Most relevant.
+		{2, 2.01, 0}, // Near duplicate of the first.
+		{1, -1.1, 0}, // Less relevant, but novel.
+	}
+
+	picked := MMR(query, candidates, 2, 0.5)
+
+	if len(picked) != 2 {
+		t.Fatalf("got %d results, want 2", len(picked))
+	}
+
+	if picked[0].Index != 0 {
+		t.Errorf("first pick: got %d, want 0", picked[0].Index)
+	}
+
+	if picked[1].Index != 2 {
+		t.Errorf("second pick: got %d, want 2 (the novel candidate)", picked[1].Index)
+	}
+
+	// Pure relevance must keep the duplicate instead.
+	picked = MMR(query, candidates, 2, 1)
+
+	if picked[1].Index != 1 {
+		t.Errorf("lambda 1 second pick: got %d, want 1", picked[1].Index)
+	}
+}
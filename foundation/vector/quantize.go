@@ -0,0 +1,95 @@
+package vector
+
+import (
+	"math"
+	"math/bits"
+)
+
+// QuantizeInt8 compresses the vector to one signed byte per dimension
+// using symmetric scalar quantization, an 8x storage saving over float64.
+// The returned scale converts codes back to approximate values. Cosine
+// similarity between two quantized vectors doesn't need the scales at all:
+// per-vector scaling cancels out of the cosine.
+func QuantizeInt8(v []float64) ([]int8, float64) {
+	var maxAbs float64
+	for _, val := range v {
+		maxAbs = max(maxAbs, max(val, -val))
+	}
+
+	if maxAbs == 0 {
+		return make([]int8, len(v)), 0
+	}
+
+	scale := maxAbs / 127
+
+	codes := make([]int8, len(v))
+	for i, val := range v {
+		q := val / scale
+		if q >= 0 {
+			codes[i] = int8(q + 0.5)
+			continue
+		}
+		codes[i] = int8(q - 0.5)
+	}
+
+	return codes, scale
+}
+
+// DequantizeInt8 converts the codes back to approximate float values.
+func DequantizeInt8(codes []int8, scale float64) []float64 {
+	v := make([]float64, len(codes))
+	for i, code := range codes {
+		v[i] = float64(code) * scale
+	}
+
+	return v
+}
+
+// CosineSimilarityInt8 computes the cosine similarity between two int8
+// quantized vectors in integer arithmetic.
+func CosineSimilarityInt8(x, y []int8) float64 {
+	var sum, s1, s2 int64
+
+	for i := range x {
+		sum += int64(x[i]) * int64(y[i])
+		s1 += int64(x[i]) * int64(x[i])
+		s2 += int64(y[i]) * int64(y[i])
+	}
+
+	if s1 == 0 || s2 == 0 {
+		return 0.0
+	}
+
+	// The squared norms fit well inside a float64 mantissa, so the
+	// conversion is exact enough.
+	return float64(sum) / (math.Sqrt(float64(s1)) * math.Sqrt(float64(s2)))
+}
+
+// QuantizeBinary compresses the vector to one bit per dimension, keeping
+// only the sign: a 64x storage saving over float64. What survives is the
+// orientation of the vector by quadrant, which is enough to rank rough
+// neighbors and cheap enough to scan millions of them.
+func QuantizeBinary(v []float64) []uint64 {
+	words := make([]uint64, (len(v)+63)/64)
+
+	for i, val := range v {
+		if val > 0 {
+			words[i/64] |= 1 << (i % 64)
+		}
+	}
+
+	return words
+}
+
+// CosineSimilarityBinary estimates the cosine similarity between two
+// binary quantized vectors of dims dimensions from their hamming
+// distance. The value is a coarse estimate: use it to shortlist
+// candidates and rescore the survivors at higher precision.
+func CosineSimilarityBinary(x, y []uint64, dims int) float64 {
+	var hamming int
+	for i := range x {
+		hamming += bits.OnesCount64(x[i] ^ y[i])
+	}
+
+	return 1 - 2*float64(hamming)/float64(dims)
+}
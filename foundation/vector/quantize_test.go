@@ -0,0 +1,61 @@
+package vector
+
+import (
+	"math"
+	"testing"
+)
+
+// TestQuantizeInt8 checks the round trip error stays inside half a
+// quantization step and that cosine over codes tracks cosine over floats.
+func TestQuantizeInt8(t *testing.T) {
+	const dim = 768
+
+	x := randVector(dim, 1)
+	y := randVector(dim, 2)
+
+	codes, scale := QuantizeInt8(x)
+
+	for i, val := range DequantizeInt8(codes, scale) {
+		if diff := math.Abs(val - x[i]); diff > scale/2+1e-12 {
+			t.Fatalf("element %d: round trip error %v exceeds half a step %v", i, diff, scale/2)
+		}
+	}
+
+	yCodes, _ := QuantizeInt8(y)
+
+	got := CosineSimilarityInt8(codes, yCodes)
+	want := CosineSimilarity(x, y)
+
+	if diff := math.Abs(got - want); diff > 0.01 {
+		t.Errorf("cosine drift %v: got %v, want %v", diff, got, want)
+	}
+}
+
+// TestQuantizeBinary checks the self and opposite similarities and that
+// the estimate lands in the right neighborhood for random vectors.
+func TestQuantizeBinary(t *testing.T) {
+	const dim = 768
+
+	x := randVector(dim, 1)
+
+	opposite := make([]float64, dim)
+	for i, val := range x {
+		opposite[i] = -val
+	}
+
+	bx := QuantizeBinary(x)
+
+	if got := CosineSimilarityBinary(bx, bx, dim); got != 1 {
+		t.Errorf("self similarity: got %v, want 1", got)
+	}
+
+	if got := CosineSimilarityBinary(bx, QuantizeBinary(opposite), dim); got != -1 {
+		t.Errorf("opposite similarity: got %v, want -1", got)
+	}
+
+	// Random vectors are near orthogonal at this dimension.
+	y := randVector(dim, 2)
+	if got := CosineSimilarityBinary(bx, QuantizeBinary(y), dim); math.Abs(got) > 0.2 {
+		t.Errorf("random similarity: got %v, want near 0", got)
+	}
+}
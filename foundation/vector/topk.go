@@ -0,0 +1,140 @@
+package vector
+
+import (
+	"sort"
+	"sync"
+)
+
+// Neighbor represents one result from a TopK search.
+type Neighbor struct {
+	Index int     // Position of the vector in the dataset.
+	Score float64 // Cosine similarity to the query.
+}
+
+// TopK returns the k nearest dataset vectors to the query by cosine
+// similarity, ordered best first. A bounded min-heap holds the current
+// candidates, so the full similarity list is never built or sorted.
+func TopK(query []float64, dataset [][]float64, k int) []Neighbor {
+	if k <= 0 {
+		return nil
+	}
+
+	h := make(minHeap, 0, k)
+
+	for i, vec := range dataset {
+		score := CosineSimilarity(query, vec)
+
+		if len(h) < k {
+			h.push(Neighbor{Index: i, Score: score})
+			continue
+		}
+
+		// The root is the worst candidate so far. Replace it when this
+		// vector beats it.
+		if score > h[0].Score {
+			h[0] = Neighbor{Index: i, Score: score}
+			h.down(0)
+		}
+	}
+
+	sortNeighbors(h)
+
+	return h
+}
+
+// TopKParallel partitions the dataset across workers and merges each
+// worker's candidates. Use it when the corpus is large enough to pay for
+// the goroutines; for small datasets TopK alone is faster.
+func TopKParallel(query []float64, dataset [][]float64, k int, workers int) []Neighbor {
+	workers = min(max(workers, 1), len(dataset))
+
+	if workers <= 1 {
+		return TopK(query, dataset, k)
+	}
+
+	part := (len(dataset) + workers - 1) / workers
+	candidates := make([][]Neighbor, workers)
+
+	var wg sync.WaitGroup
+
+	for w := range workers {
+		start := w * part
+		end := min(start+part, len(dataset))
+
+		if start >= end {
+			continue
+		}
+
+		wg.Go(func() {
+			local := TopK(query, dataset[start:end], k)
+
+			for i := range local {
+				local[i].Index += start
+			}
+
+			candidates[w] = local
+		})
+	}
+
+	wg.Wait()
+
+	var merged []Neighbor
+	for _, c := range candidates {
+		merged = append(merged, c...)
+	}
+
+	sortNeighbors(merged)
+
+	if len(merged) > k {
+		merged = merged[:k]
+	}
+
+	return merged
+}
+
+// =============================================================================
+
+func sortNeighbors(neighbors []Neighbor) {
+	sort.Slice(neighbors, func(i, j int) bool {
+		return neighbors[i].Score > neighbors[j].Score
+	})
+}
+
+// minHeap keeps the worst of the current candidates at the root so it can
+// be replaced in O(log k).
+type minHeap []Neighbor
+
+func (h *minHeap) push(n Neighbor) {
+	*h = append(*h, n)
+
+	i := len(*h) - 1
+	for i > 0 {
+		parent := (i - 1) / 2
+		if (*h)[parent].Score <= (*h)[i].Score {
+			break
+		}
+		(*h)[parent], (*h)[i] = (*h)[i], (*h)[parent]
+		i = parent
+	}
+}
+
+func (h minHeap) down(i int) {
+	for {
+		left := 2*i + 1
+		if left >= len(h) {
+			return
+		}
+
+		smallest := left
+		if right := left + 1; right < len(h) && h[right].Score < h[left].Score {
+			smallest = right
+		}
+
+		if h[i].Score <= h[smallest].Score {
+			return
+		}
+
+		h[i], h[smallest] = h[smallest], h[i]
+		i = smallest
+	}
+}
@@ -0,0 +1,73 @@
+package vector
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// TestTopK checks the bounded heap and the parallel partitioning against
+// a full sort of the similarity list.
+func TestTopK(t *testing.T) {
+	const corpusSize = 1_000
+	const dim = 64
+	const k = 10
+
+	query := randVector(dim, 1)
+
+	corpus := make([][]float64, corpusSize)
+	for i := range corpus {
+		corpus[i] = randVector(dim, int64(i+2))
+	}
+
+	want := make([]Neighbor, corpusSize)
+	for i, vec := range corpus {
+		want[i] = Neighbor{Index: i, Score: CosineSimilarity(query, vec)}
+	}
+
+	sort.Slice(want, func(i, j int) bool {
+		return want[i].Score > want[j].Score
+	})
+	want = want[:k]
+
+	for name, got := range map[string][]Neighbor{
+		"TopK":         TopK(query, corpus, k),
+		"TopKParallel": TopKParallel(query, corpus, k, 4),
+	} {
+		if len(got) != k {
+			t.Fatalf("%s: got %d results, want %d", name, len(got), k)
+		}
+
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: result %d: got %+v, want %+v", name, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func BenchmarkTopK(b *testing.B) {
+	const corpusSize = 10_000
+	const k = 10
+
+	for _, dim := range dimensions {
+		query := randVector(dim, 1)
+
+		corpus := make([][]float64, corpusSize)
+		for i := range corpus {
+			corpus[i] = randVector(dim, int64(i+2))
+		}
+
+		b.Run(fmt.Sprintf("topk-%d", dim), func(b *testing.B) {
+			for b.Loop() {
+				sink = TopK(query, corpus, k)[0].Score
+			}
+		})
+
+		b.Run(fmt.Sprintf("parallel-%d", dim), func(b *testing.B) {
+			for b.Loop() {
+				sink = TopKParallel(query, corpus, k, 4)[0].Score
+			}
+		})
+	}
+}
@@ -17,6 +17,12 @@ type Data interface {
 	Vector() []float64
 }
 
+// Data32 represents data that can be vectorized with float32 precision,
+// which is what the models actually produce and half the memory.
+type Data32 interface {
+	Vector32() []float32
+}
+
 // =============================================================================
 
 // SimilarityResult represents the result of performaing a similarity check
@@ -48,9 +54,53 @@ func Similarity(target Data, dataPoints ...Data) []SimilarityResult {
 	return results
 }
 
+// SimilarityResult32 represents the result of performaing a similarity check
+// between two float32 embeddings.
+type SimilarityResult32 struct {
+	Target     Data32
+	DataPoint  Data32
+	Similarity float32
+	Percentage float32
+}
+
+// Similarity32 calculates the similarity between two float32 vectors.
+func Similarity32(target Data32, dataPoints ...Data32) []SimilarityResult32 {
+	results := make([]SimilarityResult32, len(dataPoints))
+
+	te := target.Vector32()
+
+	for i, dp := range dataPoints {
+		similarity := CosineSimilarity32(te, dp.Vector32())
+
+		results[i] = SimilarityResult32{
+			Target:     target,
+			DataPoint:  dp,
+			Similarity: similarity,
+			Percentage: similarity * 100,
+		}
+	}
+
+	return results
+}
+
 // CosineSimilarity takes two vectors and computes the similarity between
 // them using a cosine algorithm.
 func CosineSimilarity(x, y []float64) float64 {
+	// Use the assembly dot product kernel when the CPU has one. Three
+	// vectorized passes beat one scalar pass several times over at
+	// embedding dimensions.
+	if dotAccelerated && len(x) == len(y) {
+		sum := dotProduct(x, y)
+		s1 := dotProduct(x, x)
+		s2 := dotProduct(y, y)
+
+		if s1 == 0 || s2 == 0 {
+			return 0.0
+		}
+
+		return sum / (math.Sqrt(s1) * math.Sqrt(s2))
+	}
+
 	var sum, s1, s2 float64
 
 	for i := range x {
@@ -66,6 +116,18 @@ func CosineSimilarity(x, y []float64) float64 {
 	return sum / (math.Sqrt(s1) * math.Sqrt(s2))
 }
 
+// dotScalar is the pure Go dot product. It's the fallback on architectures
+// without an assembly kernel and the baseline in the benchmarks.
+func dotScalar(x, y []float64) float64 {
+	var sum float64
+
+	for i := range x {
+		sum += x[i] * y[i]
+	}
+
+	return sum
+}
+
 // CosineSimilarity32 takes two vectors and computes the similarity between
 // them using a cosine algorithm.
 func CosineSimilarity32(x, y []float32) float32 {
@@ -163,6 +225,77 @@ func Sub(a, b []float64) []float64 {
 	return a
 }
 
+// Add32 calculates the addition of two float32 vectors.
+func Add32(a, b []float32) []float32 {
+	dimA, dimB := len(a), len(b)
+
+	if (dimA == 1 || dimA == 2 || dimA == 3) && dimB == 1 {
+		a[x] += b[x]
+		return a
+	}
+
+	if dimA == 2 && dimB == 2 {
+		a[x], a[y] = a[x]+b[x], a[y]+b[y]
+		return a
+	}
+
+	if dimA == 3 && dimB == 2 {
+		a[x], a[y] = a[x]+b[x], a[y]+b[y]
+		return a
+	}
+
+	if dimA == 3 && dimB == 3 {
+		a[x], a[y], a[z] = a[x]+b[x], a[y]+b[y], a[z]+b[z]
+		return a
+	}
+
+	if dimB > dimA {
+		axpyUnitaryTo32(a, 1, a, b[:dimA])
+	} else {
+		axpyUnitaryTo32(a, 1, a, b)
+	}
+
+	return a
+}
+
+// Sub32 calculates the subtraction of two float32 vectors.
+func Sub32(a, b []float32) []float32 {
+	dimA, dimB := len(a), len(b)
+
+	if (dimA == 1 || dimA == 2 || dimA == 3) && dimB == 1 {
+		a[x] -= b[x]
+		return a
+	}
+
+	if dimA == 2 && dimB == 2 {
+		a[x], a[y] = a[x]-b[x], a[y]-b[y]
+		return a
+	}
+
+	if dimA == 3 && dimB == 1 {
+		a[x] -= b[x]
+		return a
+	}
+
+	if dimA == 3 && dimB == 2 {
+		a[x], a[y] = a[x]-b[x], a[y]-b[y]
+		return a
+	}
+
+	if dimA == 3 && dimB == 3 {
+		a[x], a[y], a[z] = a[x]-b[x], a[y]-b[y], a[z]-b[z]
+		return a
+	}
+
+	if dimB > dimA {
+		axpyUnitaryTo32(a, -1, b[:dimA], a)
+	} else {
+		axpyUnitaryTo32(a, -1, b, a)
+	}
+
+	return a
+}
+
 func axpyUnitaryTo(dst []float64, alpha float64, x, y []float64) {
 	dim := len(y)
 	for i, v := range x {
@@ -173,6 +306,36 @@ func axpyUnitaryTo(dst []float64, alpha float64, x, y []float64) {
 	}
 }
 
+func axpyUnitaryTo32(dst []float32, alpha float32, x, y []float32) {
+	dim := len(y)
+	for i, v := range x {
+		if i == dim {
+			return
+		}
+		dst[i] = alpha*v + y[i]
+	}
+}
+
+// ToFloat32 converts a float64 vector into float32 precision.
+func ToFloat32(v []float64) []float32 {
+	v32 := make([]float32, len(v))
+	for i, val := range v {
+		v32[i] = float32(val)
+	}
+
+	return v32
+}
+
+// ToFloat64 converts a float32 vector into float64 precision.
+func ToFloat64(v []float32) []float64 {
+	v64 := make([]float64, len(v))
+	for i, val := range v {
+		v64[i] = float64(val)
+	}
+
+	return v64
+}
+
 // FormatPGVector converts a float64 slice into the pgvector text format
 // "[v0,v1,...,vN]" used for INSERT and query parameters.
 func FormatPGVector(v []float64) string {
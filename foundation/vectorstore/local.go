@@ -0,0 +1,151 @@
+package vectorstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ardanlabs/ai-training/foundation/vector"
+)
+
+// Local is a Store backed by a JSON file on disk with a brute-force
+// cosine scan. It exists so students without Docker can run the vector
+// examples: no containers, no services, just a file. A flat scan is fine
+// at classroom scale.
+type Local struct {
+	path string
+	mu   sync.Mutex
+	docs []Document
+}
+
+// NewLocal constructs a store over the specified file, loading any
+// documents a previous run saved there.
+func NewLocal(path string) (*Local, error) {
+	l := Local{
+		path: path,
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &l, nil
+		}
+		return nil, fmt.Errorf("read store: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &l.docs); err != nil {
+		return nil, fmt.Errorf("unmarshal store: %w", err)
+	}
+
+	return &l, nil
+}
+
+// EnsureIndex implements the Store interface. A flat scan has no index,
+// so there is nothing to create.
+func (l *Local) EnsureIndex(ctx context.Context, dimensions int) error {
+	return nil
+}
+
+// Insert implements the Store interface.
+func (l *Local) Insert(ctx context.Context, docs ...Document) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, doc := range docs {
+		if l.find(doc.ID) != -1 {
+			return fmt.Errorf("document %q already exists", doc.ID)
+		}
+	}
+
+	l.docs = append(l.docs, docs...)
+
+	return l.save()
+}
+
+// Upsert implements the Store interface.
+func (l *Local) Upsert(ctx context.Context, docs ...Document) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, doc := range docs {
+		if i := l.find(doc.ID); i != -1 {
+			l.docs[i] = doc
+			continue
+		}
+		l.docs = append(l.docs, doc)
+	}
+
+	return l.save()
+}
+
+// Search implements the Store interface.
+func (l *Local) Search(ctx context.Context, vec []float64, limit int) ([]Match, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	vecs := make([][]float64, len(l.docs))
+	for i, doc := range l.docs {
+		vecs[i] = doc.Embedding
+	}
+
+	neighbors := vector.TopK(vec, vecs, limit)
+
+	matches := make([]Match, len(neighbors))
+	for i, n := range neighbors {
+		matches[i] = Match{
+			Document: l.docs[n.Index],
+			Score:    n.Score,
+		}
+	}
+
+	return matches, nil
+}
+
+// Delete implements the Store interface.
+func (l *Local) Delete(ctx context.Context, ids ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, id := range ids {
+		if i := l.find(id); i != -1 {
+			l.docs = append(l.docs[:i], l.docs[i+1:]...)
+		}
+	}
+
+	return l.save()
+}
+
+// =============================================================================
+
+// find returns the index of the document with the specified id, or -1.
+// The caller must hold the mutex.
+func (l *Local) find(id string) int {
+	for i, doc := range l.docs {
+		if doc.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// save writes the documents to disk. The caller must hold the mutex.
+func (l *Local) save() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("create store dir: %w", err)
+	}
+
+	data, err := json.Marshal(l.docs)
+	if err != nil {
+		return fmt.Errorf("marshal store: %w", err)
+	}
+
+	if err := os.WriteFile(l.path, data, 0644); err != nil {
+		return fmt.Errorf("write store: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"github.com/ardanlabs/ai-training/foundation/mongodb"
+)
+
+// indexName matches the name the examples have always used so a store
+// constructed over an existing collection finds the existing index.
+const indexName = "vector_index"
+
+// Mongo is a Store backed by a MongoDB collection, extracted from the
+// aggregation pipelines the examples used to carry around individually.
+type Mongo struct {
+	col *mongo.Collection
+}
+
+// NewMongo constructs a store over the specified database and collection,
+// creating the collection if it doesn't exist.
+func NewMongo(ctx context.Context, client *mongo.Client, dbName string, colName string) (*Mongo, error) {
+	col, err := mongodb.CreateCollection(ctx, client.Database(dbName), colName)
+	if err != nil {
+		return nil, fmt.Errorf("create collection: %w", err)
+	}
+
+	return &Mongo{
+		col: col,
+	}, nil
+}
+
+// EnsureIndex implements the Store interface.
+func (m *Mongo) EnsureIndex(ctx context.Context, dimensions int) error {
+	settings := mongodb.VectorIndexSettings{
+		NumDimensions: dimensions,
+		Path:          "embedding",
+		Similarity:    "cosine",
+	}
+
+	if err := mongodb.CreateVectorIndex(ctx, m.col, indexName, settings); err != nil {
+		return fmt.Errorf("create vector index: %w", err)
+	}
+
+	return nil
+}
+
+// Insert implements the Store interface.
+func (m *Mongo) Insert(ctx context.Context, docs ...Document) error {
+	records := make([]any, len(docs))
+	for i, doc := range docs {
+		records[i] = toRecord(doc)
+	}
+
+	if _, err := m.col.InsertMany(ctx, records); err != nil {
+		return fmt.Errorf("insert many: %w", err)
+	}
+
+	return nil
+}
+
+// Upsert implements the Store interface.
+func (m *Mongo) Upsert(ctx context.Context, docs ...Document) error {
+	opts := options.Replace().SetUpsert(true)
+
+	for _, doc := range docs {
+		filter := bson.M{"_id": doc.ID}
+
+		if _, err := m.col.ReplaceOne(ctx, filter, toRecord(doc), opts); err != nil {
+			return fmt.Errorf("replace one %q: %w", doc.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// Search implements the Store interface.
+func (m *Mongo) Search(ctx context.Context, vector []float64, limit int) ([]Match, error) {
+	pipeline := mongo.Pipeline{
+		{{
+			Key: "$vectorSearch",
+			Value: bson.M{
+				"index":       indexName,
+				"exact":       true,
+				"path":        "embedding",
+				"queryVector": vector,
+				"limit":       limit,
+			}},
+		},
+		{{
+			Key: "$addFields",
+			Value: bson.M{
+				"score": bson.M{
+					"$meta": "vectorSearchScore",
+				},
+			}},
+		},
+	}
+
+	cur, err := m.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var records []record
+	if err := cur.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("all: %w", err)
+	}
+
+	matches := make([]Match, len(records))
+	for i, rec := range records {
+		matches[i] = Match{
+			Document: Document{
+				ID:        rec.ID,
+				Text:      rec.Text,
+				Embedding: rec.Embedding,
+				Metadata:  rec.Metadata,
+			},
+			Score: rec.Score,
+		}
+	}
+
+	return matches, nil
+}
+
+// Delete implements the Store interface.
+func (m *Mongo) Delete(ctx context.Context, ids ...string) error {
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+
+	if _, err := m.col.DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("delete many: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// record is the shape a document takes inside the collection.
+type record struct {
+	ID        string         `bson:"_id"`
+	Text      string         `bson:"text"`
+	Embedding []float64      `bson:"embedding"`
+	Metadata  map[string]any `bson:"metadata,omitempty"`
+	Score     float64        `bson:"score,omitempty"`
+}
+
+// toRecord converts a document into its storage shape.
+func toRecord(doc Document) record {
+	return record{
+		ID:        doc.ID,
+		Text:      doc.Text,
+		Embedding: doc.Embedding,
+		Metadata:  doc.Metadata,
+	}
+}
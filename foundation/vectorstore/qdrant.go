@@ -0,0 +1,241 @@
+package vectorstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Qdrant is a Store backed by a Qdrant collection, talking to its REST
+// API directly the same way the client package talks to model servers.
+type Qdrant struct {
+	host       string
+	collection string
+	http       http.Client
+}
+
+// NewQdrant constructs a store over the specified collection on the
+// Qdrant service, such as http://localhost:6333.
+func NewQdrant(host string, collection string) *Qdrant {
+	return &Qdrant{
+		host:       host,
+		collection: collection,
+	}
+}
+
+// EnsureIndex implements the Store interface. Qdrant indexes vectors on
+// insert, so this creates the collection if it doesn't exist.
+func (q *Qdrant) EnsureIndex(ctx context.Context, dimensions int) error {
+	status, _, err := q.do(ctx, http.MethodGet, "/collections/"+q.collection, nil)
+	if err != nil {
+		return fmt.Errorf("lookup collection: %w", err)
+	}
+
+	if status == http.StatusOK {
+		return nil
+	}
+
+	body := map[string]any{
+		"vectors": map[string]any{
+			"size":     dimensions,
+			"distance": "Cosine",
+		},
+	}
+
+	status, resp, err := q.do(ctx, http.MethodPut, "/collections/"+q.collection, body)
+	if err != nil {
+		return fmt.Errorf("create collection: %w", err)
+	}
+
+	if status != http.StatusOK {
+		return fmt.Errorf("create collection: status: %d response: %s", status, resp)
+	}
+
+	return nil
+}
+
+// Insert implements the Store interface. Qdrant's point API always
+// upserts, so inserting a duplicate id replaces the existing document.
+func (q *Qdrant) Insert(ctx context.Context, docs ...Document) error {
+	return q.Upsert(ctx, docs...)
+}
+
+// Upsert implements the Store interface.
+func (q *Qdrant) Upsert(ctx context.Context, docs ...Document) error {
+	points := make([]map[string]any, len(docs))
+
+	for i, doc := range docs {
+		payload := map[string]any{
+			"id":   doc.ID,
+			"text": doc.Text,
+		}
+		for k, v := range doc.Metadata {
+			payload[k] = v
+		}
+
+		points[i] = map[string]any{
+			"id":      pointID(doc.ID),
+			"vector":  doc.Embedding,
+			"payload": payload,
+		}
+	}
+
+	body := map[string]any{
+		"points": points,
+	}
+
+	status, resp, err := q.do(ctx, http.MethodPut, "/collections/"+q.collection+"/points?wait=true", body)
+	if err != nil {
+		return fmt.Errorf("upsert points: %w", err)
+	}
+
+	if status != http.StatusOK {
+		return fmt.Errorf("upsert points: status: %d response: %s", status, resp)
+	}
+
+	return nil
+}
+
+// Search implements the Store interface.
+func (q *Qdrant) Search(ctx context.Context, vector []float64, limit int) ([]Match, error) {
+	return q.SearchFiltered(ctx, vector, limit, nil)
+}
+
+// SearchFiltered performs a scored search constrained to documents whose
+// payload matches the specified field values. This is Qdrant-specific,
+// which is why it's not part of the Store interface.
+func (q *Qdrant) SearchFiltered(ctx context.Context, vector []float64, limit int, match map[string]any) ([]Match, error) {
+	body := map[string]any{
+		"vector":       vector,
+		"limit":        limit,
+		"with_payload": true,
+		"with_vector":  true,
+	}
+
+	if len(match) > 0 {
+		var must []map[string]any
+		for field, value := range match {
+			must = append(must, map[string]any{
+				"key":   field,
+				"match": map[string]any{"value": value},
+			})
+		}
+
+		body["filter"] = map[string]any{"must": must}
+	}
+
+	status, resp, err := q.do(ctx, http.MethodPost, "/collections/"+q.collection+"/points/search", body)
+	if err != nil {
+		return nil, fmt.Errorf("search points: %w", err)
+	}
+
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("search points: status: %d response: %s", status, resp)
+	}
+
+	var result struct {
+		Result []struct {
+			Score   float64        `json:"score"`
+			Vector  []float64      `json:"vector"`
+			Payload map[string]any `json:"payload"`
+		} `json:"result"`
+	}
+
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal search response: %w", err)
+	}
+
+	matches := make([]Match, len(result.Result))
+	for i, hit := range result.Result {
+		id, _ := hit.Payload["id"].(string)
+		text, _ := hit.Payload["text"].(string)
+
+		metadata := make(map[string]any)
+		for k, v := range hit.Payload {
+			if k != "id" && k != "text" {
+				metadata[k] = v
+			}
+		}
+
+		matches[i] = Match{
+			Document: Document{
+				ID:        id,
+				Text:      text,
+				Embedding: hit.Vector,
+				Metadata:  metadata,
+			},
+			Score: hit.Score,
+		}
+	}
+
+	return matches, nil
+}
+
+// Delete implements the Store interface.
+func (q *Qdrant) Delete(ctx context.Context, ids ...string) error {
+	points := make([]string, len(ids))
+	for i, id := range ids {
+		points[i] = pointID(id)
+	}
+
+	body := map[string]any{
+		"points": points,
+	}
+
+	status, resp, err := q.do(ctx, http.MethodPost, "/collections/"+q.collection+"/points/delete?wait=true", body)
+	if err != nil {
+		return fmt.Errorf("delete points: %w", err)
+	}
+
+	if status != http.StatusOK {
+		return fmt.Errorf("delete points: status: %d response: %s", status, resp)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// pointID converts a document id into the UUID form Qdrant requires for
+// point ids. The original id lives in the payload.
+func pointID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// do executes a request against the Qdrant REST API.
+func (q *Qdrant) do(ctx context.Context, method string, path string, body any) (int, []byte, error) {
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, fmt.Errorf("marshal body: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, q.host+path, reader)
+	if err != nil {
+		return 0, nil, fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := q.http.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("read response: %w", err)
+	}
+
+	return resp.StatusCode, data, nil
+}
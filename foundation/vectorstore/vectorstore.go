@@ -0,0 +1,43 @@
+// Package vectorstore defines a storage interface for embedded documents
+// so the examples aren't welded to MongoDB's aggregation pipeline syntax.
+// A backend implements Store and the rest of the code doesn't care which
+// database holds the vectors.
+package vectorstore
+
+import (
+	"context"
+)
+
+// Document represents a record with its embedding. Metadata carries any
+// extra fields a corpus needs, such as chapter and page for the book.
+type Document struct {
+	ID        string
+	Text      string
+	Embedding []float64
+	Metadata  map[string]any
+}
+
+// Match represents a search hit with its similarity score.
+type Match struct {
+	Document
+	Score float64
+}
+
+// Store describes the behavior a vector database backend must implement.
+type Store interface {
+	// EnsureIndex creates the vector index for the specified number of
+	// dimensions if it doesn't already exist.
+	EnsureIndex(ctx context.Context, dimensions int) error
+
+	// Insert adds documents, failing on duplicate ids.
+	Insert(ctx context.Context, docs ...Document) error
+
+	// Upsert adds documents, replacing any that share an id.
+	Upsert(ctx context.Context, docs ...Document) error
+
+	// Search returns the documents closest to the vector, best first.
+	Search(ctx context.Context, vector []float64, limit int) ([]Match, error)
+
+	// Delete removes the documents with the specified ids.
+	Delete(ctx context.Context, ids ...string) error
+}
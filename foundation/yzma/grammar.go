@@ -0,0 +1,78 @@
+package yzma
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Property describes a single field of a JSON object schema used with
+// WithJSONSchema.
+type Property struct {
+	Name string
+	Type string   // string, number, integer, boolean
+	Enum []string // allowed values, string properties only
+}
+
+// WithJSONSchema constrains the generation to a JSON object with exactly
+// the specified properties in order. This is a convenience over WithGrammar
+// for the common "respond using the following JSON document" prompt, and
+// makes any JSON repair code unnecessary.
+func WithJSONSchema(properties ...Property) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.grammar = buildGrammar(properties)
+	}
+}
+
+// buildGrammar produces a GBNF grammar that only accepts a JSON object
+// with the specified properties.
+func buildGrammar(properties []Property) string {
+	var b strings.Builder
+
+	b.WriteString(`root ::= "{" ws`)
+
+	for i, prop := range properties {
+		if i > 0 {
+			b.WriteString(` "," ws`)
+		}
+
+		fmt.Fprintf(&b, ` "\"%s\"" ws ":" ws %s`, prop.Name, ruleName(prop))
+	}
+
+	b.WriteString(` ws "}"` + "\n")
+
+	for _, prop := range properties {
+		if len(prop.Enum) == 0 {
+			continue
+		}
+
+		quoted := make([]string, len(prop.Enum))
+		for i, value := range prop.Enum {
+			quoted[i] = fmt.Sprintf(`"\"%s\""`, value)
+		}
+
+		fmt.Fprintf(&b, "%s ::= %s\n", ruleName(prop), strings.Join(quoted, " | "))
+	}
+
+	b.WriteString(`string ::= "\"" char* "\""` + "\n")
+	b.WriteString(`char ::= [^"\\\x7F\x00-\x1F] | "\\" (["\\bfnrt] | "u" [0-9a-fA-F] [0-9a-fA-F] [0-9a-fA-F] [0-9a-fA-F])` + "\n")
+	b.WriteString(`integer ::= "-"? [0-9]+` + "\n")
+	b.WriteString(`number ::= "-"? [0-9]+ ("." [0-9]+)?` + "\n")
+	b.WriteString(`boolean ::= "true" | "false"` + "\n")
+	b.WriteString("ws ::= [ \\t\\n]*\n")
+
+	return b.String()
+}
+
+// ruleName returns the grammar rule that matches the property's value.
+func ruleName(prop Property) string {
+	if len(prop.Enum) > 0 {
+		return prop.Name + "-enum"
+	}
+
+	switch prop.Type {
+	case "number", "integer", "boolean":
+		return prop.Type
+	}
+
+	return "string"
+}
@@ -0,0 +1,99 @@
+package yzma
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+// Perplexity computes the model's perplexity over the specified text. Lower
+// is better. The text is processed in windows, and within each window the
+// first token has no context so it isn't scored.
+func (m *Model) Perplexity(ctx context.Context, text string) (float64, error) {
+	if m.cfg.embeddings {
+		return 0, fmt.Errorf("model is configured for embeddings")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tokens := llama.Tokenize(m.vocab, text, true, false)
+	if len(tokens) < 2 {
+		return 0, fmt.Errorf("text is too short to score")
+	}
+
+	nVocab := int(llama.VocabNTokens(m.vocab))
+
+	// Every token in a window is decoded in a single batch, so the window
+	// can't be larger than the batch size.
+	window := int(min(m.cfg.contextSize, m.cfg.batchSize))
+
+	var nll float64
+	var count int
+
+	for start := 0; start < len(tokens)-1; start += window {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		chunk := tokens[start:min(start+window, len(tokens))]
+		if len(chunk) < 2 {
+			break
+		}
+
+		if err := m.clearMemory(); err != nil {
+			return 0, err
+		}
+
+		batch := llama.BatchInit(int32(len(chunk)), 0, 1)
+
+		for pos, token := range chunk {
+			batch.Add(token, llama.Pos(pos), []llama.SeqId{0}, true)
+		}
+
+		_, err := llama.Decode(m.lctx, batch)
+		llama.BatchFree(batch)
+
+		if err != nil {
+			return 0, fmt.Errorf("decode: %w", err)
+		}
+
+		logits, err := llama.GetLogits(m.lctx, len(chunk), nVocab)
+		if err != nil {
+			return 0, fmt.Errorf("get logits: %w", err)
+		}
+
+		// The logits at each position predict the token that follows it.
+		for i := range len(chunk) - 1 {
+			row := logits[i*nVocab : (i+1)*nVocab]
+			nll -= logProb(row, chunk[i+1])
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, fmt.Errorf("no tokens were scored")
+	}
+
+	return math.Exp(nll / float64(count)), nil
+}
+
+// logProb returns the log of the softmax probability the logits assign to
+// the token.
+func logProb(logits []float32, token llama.Token) float64 {
+	maxVal := float64(logits[0])
+	for _, v := range logits[1:] {
+		if float64(v) > maxVal {
+			maxVal = float64(v)
+		}
+	}
+
+	var sumExp float64
+	for _, v := range logits {
+		sumExp += math.Exp(float64(v) - maxVal)
+	}
+
+	return float64(logits[token]) - maxVal - math.Log(sumExp)
+}
@@ -0,0 +1,147 @@
+package yzma
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hybridgroup/yzma/pkg/llama"
+	"github.com/hybridgroup/yzma/pkg/mtmd"
+)
+
+// The mtmd library is loaded once the first time a model is loaded with a
+// projection file.
+var (
+	mtmdOnce sync.Once
+	mtmdErr  error
+)
+
+// WithProjection sets the multi-modal projection file that pairs with the
+// model, enabling image input through ChatVision.
+func WithProjection(path string) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.projFile = path
+	}
+}
+
+// initProjection loads the mtmd library and initializes a multi-modal
+// context for the projection file.
+func initProjection(model llama.Model, cfg config) (mtmd.Context, error) {
+	mtmdOnce.Do(func() {
+		if err := mtmd.Load(cfg.libPath); err != nil {
+			mtmdErr = fmt.Errorf("load mtmd library: %w", err)
+			return
+		}
+
+		mtmd.LogSet(llama.LogSilent())
+	})
+
+	if mtmdErr != nil {
+		return 0, mtmdErr
+	}
+
+	mctx, err := mtmd.InitFromFile(cfg.projFile, model, mtmd.ContextParamsDefault())
+	if err != nil {
+		return 0, fmt.Errorf("init projection %s: %w", cfg.projFile, err)
+	}
+
+	return mctx, nil
+}
+
+// ChatVision answers a prompt about one or more images. Each image is
+// tokenized as a bitmap through the projection model, so prompts like
+// "compare these two slides" work with any number of images. The model must
+// be loaded with the WithProjection option.
+func (m *Model) ChatVision(ctx context.Context, prompt string, images ...[]byte) (string, error) {
+	var b strings.Builder
+
+	emit := func(piece string) error {
+		b.WriteString(piece)
+		return nil
+	}
+
+	if err := m.generateVision(ctx, prompt, images, emit); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// ChatVisionFunc answers a prompt about one or more images, calling fn with
+// each piece of the response as it's produced.
+func (m *Model) ChatVisionFunc(ctx context.Context, prompt string, images [][]byte, fn StreamFunc) error {
+	return m.generateVision(ctx, prompt, images, fn)
+}
+
+// generateVision tokenizes the prompt and bitmaps through the projection
+// model, evaluates them, and runs the token generation loop.
+func (m *Model) generateVision(ctx context.Context, prompt string, images [][]byte, emit func(piece string) error) error {
+	if m.mctx == 0 {
+		return fmt.Errorf("model is not configured for vision, use WithProjection")
+	}
+
+	if len(images) == 0 {
+		return fmt.Errorf("no images provided")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The evaluation starts at position zero and image positions don't map
+	// back to text tokens, so the prompt cache can't be reused before or
+	// after a vision call.
+	if err := m.clearMemory(); err != nil {
+		return err
+	}
+	defer func() {
+		m.cache = nil
+	}()
+
+	// One marker per image tells the tokenizer where each bitmap goes in
+	// the prompt.
+	markers := strings.Repeat(mtmd.DefaultMarker(), len(images))
+
+	text, err := m.applyTemplate([]Message{{Role: "user", Content: markers + prompt}}, true)
+	if err != nil {
+		return err
+	}
+
+	bitmaps := make([]mtmd.Bitmap, 0, len(images))
+	defer func() {
+		for _, bitmap := range bitmaps {
+			mtmd.BitmapFree(bitmap)
+		}
+	}()
+
+	for i, image := range images {
+		bitmap := mtmd.BitmapInitFromBuf(m.mctx, &image[0], uint64(len(image)))
+		if bitmap == 0 {
+			return fmt.Errorf("decode image %d failed", i)
+		}
+
+		bitmaps = append(bitmaps, bitmap)
+	}
+
+	chunks := mtmd.InputChunksInit()
+	defer mtmd.InputChunksFree(chunks)
+
+	input := mtmd.NewInputText(text, true, true)
+
+	if n := mtmd.Tokenize(m.mctx, chunks, input, bitmaps); n != 0 {
+		return fmt.Errorf("tokenize vision prompt failed: %d", n)
+	}
+
+	llama.SamplerReset(m.sampler)
+
+	var pos llama.Pos
+	if n := mtmd.HelperEvalChunks(m.mctx, m.lctx, chunks, 0, 0, int32(m.cfg.batchSize), true, &pos); n != 0 {
+		return fmt.Errorf("eval vision prompt failed: %d", n)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return m.sampleLoop(ctx, newStopFilter(m.cfg.stops, emit))
+}
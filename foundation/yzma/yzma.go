@@ -0,0 +1,891 @@
+// Package yzma provides a high level wrapper over the yzma llama.cpp
+// bindings. The examples talk to a local model through LoadModel, Chat, and
+// Embed without managing tokens, batches, and samplers by hand.
+package yzma
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hybridgroup/yzma/pkg/llama"
+	"github.com/hybridgroup/yzma/pkg/message"
+	"github.com/hybridgroup/yzma/pkg/mtmd"
+	"github.com/hybridgroup/yzma/pkg/template"
+)
+
+// The llama.cpp library can only be loaded and initialized once for the
+// life of the process.
+var (
+	loadOnce sync.Once
+	loadErr  error
+)
+
+type config struct {
+	libPath     string
+	contextSize uint32
+	batchSize   uint32
+	maxTokens   int
+	temperature float32
+	topK        int32
+	topP        float32
+	minP        float32
+	template    string
+	embeddings  bool
+	stops       []string
+	grammar     string
+	gpuLayers   int32
+	mainGPU     int32
+	flashAttn   llama.FlashAttentionType
+	maxSeqs     uint32
+	loras       []lora
+	projFile    string
+	tmplFile    string
+	dumpPrompt  bool
+}
+
+// lora identifies an adapter file and the scale to apply it with.
+type lora struct {
+	path  string
+	scale float32
+}
+
+func defaultConfig() config {
+	return config{
+		libPath:     os.Getenv("YZMA_LIB"),
+		contextSize: 8192,
+		batchSize:   2048,
+		maxTokens:   2048,
+		temperature: 0.8,
+		topK:        40,
+		topP:        0.95,
+		minP:        0.05,
+		gpuLayers:   -1,
+		flashAttn:   llama.FlashAttentionTypeAuto,
+		maxSeqs:     1,
+		dumpPrompt:  os.Getenv("YZMA_DUMP_PROMPT") != "",
+	}
+}
+
+// WithLibPath sets the directory holding the llama.cpp shared libraries.
+// The default comes from the YZMA_LIB environment variable.
+func WithLibPath(path string) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.libPath = path
+	}
+}
+
+// WithContextSize sets the size of the context window in tokens.
+func WithContextSize(size uint32) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.contextSize = size
+	}
+}
+
+// WithMaxTokens sets the maximum number of tokens a single generation can
+// produce.
+func WithMaxTokens(maxTokens int) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.maxTokens = maxTokens
+	}
+}
+
+// WithParams sets the sampling parameters used during generation.
+func WithParams(temperature float32, topP float32, topK int32) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.temperature = temperature
+		cfg.topP = topP
+		cfg.topK = topK
+	}
+}
+
+// WithTemplate overrides the chat template baked into the model.
+func WithTemplate(template string) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.template = template
+	}
+}
+
+// WithTemplateFile overrides the chat template baked into the model with a
+// jinja template read from the specified file. Use this when a model's
+// embedded template renders incorrectly.
+func WithTemplateFile(path string) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.tmplFile = path
+	}
+}
+
+// WithPromptDump writes every rendered prompt to stderr for template
+// debugging. The YZMA_DUMP_PROMPT environment variable enables the same
+// behavior.
+func WithPromptDump() func(cfg *config) {
+	return func(cfg *config) {
+		cfg.dumpPrompt = true
+	}
+}
+
+// WithGrammar constrains the generation with a GBNF grammar. The grammar's
+// entry rule must be named root.
+func WithGrammar(grammar string) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.grammar = grammar
+	}
+}
+
+// WithStopSequences cuts the generation when any of the sequences appears
+// in the output. The stop sequence itself is not delivered.
+func WithStopSequences(stops ...string) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.stops = stops
+	}
+}
+
+// WithEmbeddings configures the model for embedding generation with mean
+// pooling instead of text generation.
+func WithEmbeddings() func(cfg *config) {
+	return func(cfg *config) {
+		cfg.embeddings = true
+	}
+}
+
+// WithGPULayers sets the number of model layers to offload to the GPU. Use a
+// small number to partially offload a model that doesn't fit in VRAM, or 0 to
+// run CPU only. The default lets llama.cpp offload everything it can.
+func WithGPULayers(layers int32) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.gpuLayers = layers
+	}
+}
+
+// WithMainGPU selects which GPU holds the model when more than one is
+// available.
+func WithMainGPU(gpu int32) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.mainGPU = gpu
+	}
+}
+
+// WithLoRA applies a LoRA adapter to the model at the specified scale. A
+// scale of 1 applies the adapter at full strength. The option can be
+// repeated to stack adapters.
+func WithLoRA(path string, scale float32) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.loras = append(cfg.loras, lora{path: path, scale: scale})
+	}
+}
+
+// WithMaxSequences sets how many sequences the context can process in
+// parallel. EmbedBatch decodes that many texts per call to the model.
+func WithMaxSequences(sequences uint32) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.maxSeqs = sequences
+	}
+}
+
+// WithFlashAttention forces flash attention on or off. The default lets
+// llama.cpp decide based on the hardware.
+func WithFlashAttention(enabled bool) func(cfg *config) {
+	return func(cfg *config) {
+		cfg.flashAttn = llama.FlashAttentionTypeDisabled
+		if enabled {
+			cfg.flashAttn = llama.FlashAttentionTypeEnabled
+		}
+	}
+}
+
+// =============================================================================
+
+// Message represents a single message in a conversation.
+type Message struct {
+	Role    string
+	Content string
+}
+
+// Model represents a loaded gguf model with its context and sampler. The
+// methods serialize access so a Model can be shared across goroutines.
+type Model struct {
+	cfg      config
+	model    llama.Model
+	lctx     llama.Context
+	vocab    llama.Vocab
+	sampler  llama.Sampler
+	template string
+	cache    []llama.Token
+	adapters []llama.AdapterLora
+	mctx     mtmd.Context
+	mu       sync.Mutex
+}
+
+// LoadModel loads the specified gguf model file and prepares a context and
+// sampler for it. The llama.cpp libraries are loaded on the first call.
+func LoadModel(modelFile string, options ...func(cfg *config)) (*Model, error) {
+	cfg := defaultConfig()
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	if cfg.tmplFile != "" {
+		data, err := os.ReadFile(cfg.tmplFile)
+		if err != nil {
+			return nil, fmt.Errorf("read template %s: %w", cfg.tmplFile, err)
+		}
+		cfg.template = string(data)
+	}
+
+	loadOnce.Do(func() {
+		if err := llama.Load(cfg.libPath); err != nil {
+			loadErr = fmt.Errorf("load library: %w", err)
+			return
+		}
+
+		llama.LogSet(llama.LogSilent())
+		llama.Init()
+	})
+
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	mdlParams := llama.ModelDefaultParams()
+	mdlParams.MainGpu = cfg.mainGPU
+
+	if cfg.gpuLayers >= 0 {
+		mdlParams.NGpuLayers = cfg.gpuLayers
+	}
+
+	model, err := llama.ModelLoadFromFile(modelFile, mdlParams)
+	if err != nil {
+		return nil, fmt.Errorf("load model %s: %w", modelFile, err)
+	}
+
+	if model == 0 {
+		return nil, fmt.Errorf("load model %s: no model", modelFile)
+	}
+
+	ctxParams := llama.ContextDefaultParams()
+	ctxParams.NCtx = cfg.contextSize
+	ctxParams.NBatch = cfg.batchSize
+	ctxParams.FlashAttentionType = cfg.flashAttn
+	ctxParams.NSeqMax = cfg.maxSeqs
+
+	if cfg.embeddings {
+		ctxParams.PoolingType = llama.PoolingTypeMean
+		ctxParams.Embeddings = 1
+	}
+
+	lctx, err := llama.InitFromModel(model, ctxParams)
+	if err != nil {
+		llama.ModelFree(model)
+		return nil, fmt.Errorf("init context: %w", err)
+	}
+
+	adapters, err := applyLoras(model, lctx, cfg.loras)
+	if err != nil {
+		llama.Free(lctx)
+		llama.ModelFree(model)
+		return nil, err
+	}
+
+	m := Model{
+		cfg:      cfg,
+		model:    model,
+		lctx:     lctx,
+		vocab:    llama.ModelGetVocab(model),
+		adapters: adapters,
+	}
+
+	if cfg.projFile != "" {
+		mctx, err := initProjection(model, cfg)
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+
+		m.mctx = mctx
+	}
+
+	if !cfg.embeddings {
+		switch {
+		case cfg.grammar != "":
+
+			// A grammar constrained chain is built by hand so the grammar
+			// sampler filters the candidates before any sampling happens.
+			chain := llama.SamplerChainInit(llama.SamplerChainDefaultParams())
+			llama.SamplerChainAdd(chain, llama.SamplerInitGrammar(m.vocab, cfg.grammar, "root"))
+			llama.SamplerChainAdd(chain, llama.SamplerInitTopK(cfg.topK))
+			llama.SamplerChainAdd(chain, llama.SamplerInitTopP(cfg.topP, 1))
+			llama.SamplerChainAdd(chain, llama.SamplerInitMinP(cfg.minP, 1))
+			llama.SamplerChainAdd(chain, llama.SamplerInitTempExt(cfg.temperature, 0, 1))
+			llama.SamplerChainAdd(chain, llama.SamplerInitDist(llama.DefaultSeed))
+
+			m.sampler = chain
+
+		default:
+			sp := llama.DefaultSamplerParams()
+			sp.Temp = cfg.temperature
+			sp.TopK = cfg.topK
+			sp.TopP = cfg.topP
+			sp.MinP = cfg.minP
+
+			samplers := []llama.SamplerType{
+				llama.SamplerTypeTopK,
+				llama.SamplerTypeTopP,
+				llama.SamplerTypeMinP,
+				llama.SamplerTypeTemperature,
+			}
+
+			m.sampler = llama.NewSampler(model, samplers, sp)
+		}
+
+		m.template = cfg.template
+		if m.template == "" {
+			m.template = llama.ModelChatTemplate(model, "")
+		}
+		if m.template == "" {
+			m.template = "chatml"
+		}
+	}
+
+	return &m, nil
+}
+
+// Close frees the sampler, context, and model.
+func (m *Model) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.sampler != 0 {
+		llama.SamplerFree(m.sampler)
+	}
+
+	if m.mctx != 0 {
+		mtmd.Free(m.mctx)
+	}
+
+	llama.Free(m.lctx)
+
+	for _, adapter := range m.adapters {
+		llama.AdapterLoraFree(adapter)
+	}
+
+	llama.ModelFree(m.model)
+}
+
+// applyLoras loads the LoRA adapter files and applies them to the context
+// with their scales.
+func applyLoras(model llama.Model, lctx llama.Context, loras []lora) ([]llama.AdapterLora, error) {
+	if len(loras) == 0 {
+		return nil, nil
+	}
+
+	adapters := make([]llama.AdapterLora, len(loras))
+	scales := make([]float32, len(loras))
+
+	for i, l := range loras {
+		adapter, err := llama.AdapterLoraInit(model, l.path)
+		if err != nil {
+			for _, a := range adapters[:i] {
+				llama.AdapterLoraFree(a)
+			}
+			return nil, fmt.Errorf("load lora %s: %w", l.path, err)
+		}
+
+		adapters[i] = adapter
+		scales[i] = l.scale
+	}
+
+	if llama.SetAdaptersLora(lctx, adapters, scales) != 0 {
+		for _, a := range adapters {
+			llama.AdapterLoraFree(a)
+		}
+		return nil, fmt.Errorf("apply lora adapters failed")
+	}
+
+	return adapters, nil
+}
+
+// Prefill renders the messages through the chat template and decodes them
+// into the KV cache without generating a response. Combined with SaveSession
+// this pays the prompt processing cost for a long system prompt or RAG
+// context once instead of on every turn.
+func (m *Model) Prefill(ctx context.Context, messages []Message) error {
+	if m.cfg.embeddings {
+		return fmt.Errorf("model is configured for embeddings")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// The assistant turn is not primed because later calls append more
+	// messages and the rendered conversations must share this prefix.
+	text, err := m.applyTemplate(messages, false)
+	if err != nil {
+		return err
+	}
+
+	return m.decodePrompt(llama.Tokenize(m.vocab, text, true, true))
+}
+
+// SaveSession writes the KV cache and the tokens it holds to a file. Load it
+// later with LoadSession to skip decoding that prompt again.
+func (m *Model) SaveSession(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !llama.StateSaveFile(m.lctx, path, m.cache) {
+		return fmt.Errorf("save session %s failed", path)
+	}
+
+	return nil
+}
+
+// LoadSession restores a KV cache written by SaveSession. The next chat call
+// only decodes the part of the prompt that isn't already in the cache.
+func (m *Model) LoadSession(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tokens := make([]llama.Token, m.cfg.contextSize)
+
+	var count uint64
+	if !llama.StateLoadFile(m.lctx, path, tokens, uint64(len(tokens)), &count) {
+		return fmt.Errorf("load session %s failed", path)
+	}
+
+	m.cache = tokens[:count]
+
+	return nil
+}
+
+// StreamFunc is called with each piece of generated text as it's produced.
+// Returning an error stops the generation.
+type StreamFunc func(piece string) error
+
+// Chat renders the conversation through the model's chat template, runs the
+// generation loop, and returns the complete response.
+func (m *Model) Chat(ctx context.Context, messages []Message) (string, error) {
+	var b strings.Builder
+
+	emit := func(piece string) error {
+		b.WriteString(piece)
+		return nil
+	}
+
+	if err := m.generate(ctx, messages, emit); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// ChatFunc runs the generation loop and calls fn with each piece of the
+// response as it's produced. Cancel the context to stop the generation
+// early.
+func (m *Model) ChatFunc(ctx context.Context, messages []Message, fn StreamFunc) error {
+	return m.generate(ctx, messages, fn)
+}
+
+// ChatStream runs the generation loop in the background and delivers each
+// piece of the response on the returned channel. The channel is closed when
+// the generation completes or the context is canceled.
+func (m *Model) ChatStream(ctx context.Context, messages []Message) (<-chan string, error) {
+	if m.cfg.embeddings {
+		return nil, fmt.Errorf("model is configured for embeddings")
+	}
+
+	ch := make(chan string)
+
+	emit := func(piece string) error {
+		select {
+		case ch <- piece:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	go func() {
+		defer close(ch)
+		m.generate(ctx, messages, emit)
+	}()
+
+	return ch, nil
+}
+
+// Embed returns the normalized embedding vector for the specified text. The
+// model must be loaded with the WithEmbeddings option.
+func (m *Model) Embed(ctx context.Context, text string) ([]float64, error) {
+	if !m.cfg.embeddings {
+		return nil, fmt.Errorf("model is not configured for embeddings, use WithEmbeddings")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.clearMemory(); err != nil {
+		return nil, err
+	}
+
+	tokens := llama.Tokenize(m.vocab, text, true, true)
+
+	if _, err := llama.Decode(m.lctx, llama.BatchGetOne(tokens)); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	vec, err := llama.GetEmbeddingsSeq(m.lctx, 0, llama.ModelNEmbd(m.model))
+	if err != nil {
+		return nil, fmt.Errorf("get embeddings: %w", err)
+	}
+
+	return normalize(vec), nil
+}
+
+// EmbedBatch returns the normalized embedding vectors for the specified
+// texts. Texts are decoded together in groups of WithMaxSequences parallel
+// sequences, which is much faster than one Embed call per text.
+func (m *Model) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	if !m.cfg.embeddings {
+		return nil, fmt.Errorf("model is not configured for embeddings, use WithEmbeddings")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	vectors := make([][]float64, 0, len(texts))
+
+	for start := 0; start < len(texts); start += int(m.cfg.maxSeqs) {
+		group := texts[start:min(start+int(m.cfg.maxSeqs), len(texts))]
+
+		vecs, err := m.embedGroup(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+
+		vectors = append(vectors, vecs...)
+	}
+
+	return vectors, nil
+}
+
+// embedGroup embeds up to WithMaxSequences texts with a single decode call,
+// one sequence per text.
+func (m *Model) embedGroup(ctx context.Context, texts []string) ([][]float64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := m.clearMemory(); err != nil {
+		return nil, err
+	}
+
+	tokens := make([][]llama.Token, len(texts))
+
+	var total int32
+	for i, text := range texts {
+		tokens[i] = llama.Tokenize(m.vocab, text, true, true)
+		total += int32(len(tokens[i]))
+	}
+
+	batch := llama.BatchInit(total, 0, int32(len(texts)))
+	defer llama.BatchFree(batch)
+
+	for seq, seqTokens := range tokens {
+		for pos, token := range seqTokens {
+			batch.Add(token, llama.Pos(pos), []llama.SeqId{llama.SeqId(seq)}, true)
+		}
+	}
+
+	if _, err := llama.Decode(m.lctx, batch); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	vecs := make([][]float64, len(texts))
+
+	for seq := range texts {
+		vec, err := llama.GetEmbeddingsSeq(m.lctx, llama.SeqId(seq), llama.ModelNEmbd(m.model))
+		if err != nil {
+			return nil, fmt.Errorf("get embeddings: %w", err)
+		}
+
+		vecs[seq] = normalize(vec)
+	}
+
+	return vecs, nil
+}
+
+// generate runs the token generation loop, calling emit with each piece of
+// text as it's produced.
+func (m *Model) generate(ctx context.Context, messages []Message, emit func(piece string) error) error {
+	if m.cfg.embeddings {
+		return fmt.Errorf("model is configured for embeddings")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	text, err := m.applyTemplate(messages, true)
+	if err != nil {
+		return err
+	}
+
+	llama.SamplerReset(m.sampler)
+
+	// Each call renders the full conversation, but any prefix already in
+	// the KV cache from a previous turn or a loaded session is reused so
+	// only the new part of the prompt is decoded.
+	if err := m.decodePrompt(llama.Tokenize(m.vocab, text, true, true)); err != nil {
+		return err
+	}
+
+	return m.sampleLoop(ctx, newStopFilter(m.cfg.stops, emit))
+}
+
+// sampleLoop samples tokens until EOG, a stop sequence, or the token budget
+// is reached, delivering the text through the filter.
+func (m *Model) sampleLoop(ctx context.Context, filter *stopFilter) error {
+	buf := make([]byte, 256)
+
+	for range m.cfg.maxTokens {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		token := llama.SamplerSample(m.sampler, m.lctx, -1)
+
+		if llama.VocabIsEOG(m.vocab, token) {
+			return filter.flush()
+		}
+
+		n := llama.TokenToPiece(m.vocab, token, buf, 0, false)
+
+		stop, err := filter.write(string(buf[:n]))
+		if err != nil {
+			return err
+		}
+
+		if stop {
+			return nil
+		}
+
+		if _, err := llama.Decode(m.lctx, llama.BatchGetOne([]llama.Token{token})); err != nil {
+			return fmt.Errorf("decode: %w", err)
+		}
+
+		m.cache = append(m.cache, token)
+	}
+
+	return filter.flush()
+}
+
+// decodePrompt decodes the prompt tokens into the KV cache, reusing any
+// prefix that is already there. At least the last token is always decoded so
+// the logits for sampling are fresh.
+func (m *Model) decodePrompt(tokens []llama.Token) error {
+	keep := 0
+	for keep < len(m.cache) && keep < len(tokens)-1 && m.cache[keep] == tokens[keep] {
+		keep++
+	}
+
+	switch {
+	case keep == 0:
+		if err := m.clearMemory(); err != nil {
+			return err
+		}
+
+	case keep < len(m.cache):
+		mem, err := llama.GetMemory(m.lctx)
+		if err != nil {
+			return fmt.Errorf("get memory: %w", err)
+		}
+
+		if _, err := llama.MemorySeqRm(mem, 0, llama.Pos(keep), -1); err != nil {
+			return fmt.Errorf("trim memory: %w", err)
+		}
+
+		m.cache = m.cache[:keep]
+	}
+
+	if _, err := llama.Decode(m.lctx, llama.BatchGetOne(tokens[keep:])); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	m.cache = append(m.cache, tokens[keep:]...)
+
+	return nil
+}
+
+// stopFilter holds back generated text that could be the start of a stop
+// sequence and cuts the stream when one appears.
+type stopFilter struct {
+	stops   []string
+	emit    StreamFunc
+	pending string
+}
+
+func newStopFilter(stops []string, emit StreamFunc) *stopFilter {
+	return &stopFilter{
+		stops: stops,
+		emit:  emit,
+	}
+}
+
+// write delivers the piece downstream. It reports true when a stop sequence
+// has been seen and the generation should end.
+func (sf *stopFilter) write(piece string) (bool, error) {
+	if len(sf.stops) == 0 {
+		return false, sf.emit(piece)
+	}
+
+	sf.pending += piece
+
+	for _, stop := range sf.stops {
+		idx := strings.Index(sf.pending, stop)
+		if idx == -1 {
+			continue
+		}
+
+		if idx > 0 {
+			if err := sf.emit(sf.pending[:idx]); err != nil {
+				return true, err
+			}
+		}
+
+		sf.pending = ""
+
+		return true, nil
+	}
+
+	// Hold back the longest suffix that could still become a stop
+	// sequence once more text arrives.
+	var hold int
+	for _, stop := range sf.stops {
+		for k := min(len(stop)-1, len(sf.pending)); k > hold; k-- {
+			if strings.HasPrefix(stop, sf.pending[len(sf.pending)-k:]) {
+				hold = k
+				break
+			}
+		}
+	}
+
+	if cut := len(sf.pending) - hold; cut > 0 {
+		if err := sf.emit(sf.pending[:cut]); err != nil {
+			return false, err
+		}
+		sf.pending = sf.pending[cut:]
+	}
+
+	return false, nil
+}
+
+// flush delivers any held back text when the generation ends without
+// hitting a stop sequence.
+func (sf *stopFilter) flush() error {
+	if sf.pending == "" {
+		return nil
+	}
+
+	piece := sf.pending
+	sf.pending = ""
+
+	return sf.emit(piece)
+}
+
+// applyTemplate renders the conversation through the model's chat template,
+// priming the assistant turn when a response should follow.
+func (m *Model) applyTemplate(messages []Message, addAssistant bool) (string, error) {
+	// Templates straight from a gguf file are often jinja programs that
+	// llama.cpp's built-in matcher doesn't recognize. Render those with a
+	// real jinja engine instead of degrading the output.
+	if strings.Contains(m.template, "{%") {
+		msgs := make([]message.Message, len(messages))
+		for i, msg := range messages {
+			msgs[i] = message.Chat{Role: msg.Role, Content: msg.Content}
+		}
+
+		text, err := template.Apply(m.template, msgs, addAssistant)
+		if err != nil {
+			return "", fmt.Errorf("apply jinja template: %w", err)
+		}
+
+		m.dump(text)
+
+		return text, nil
+	}
+
+	chat := make([]llama.ChatMessage, len(messages))
+	for i, msg := range messages {
+		chat[i] = llama.NewChatMessage(msg.Role, msg.Content)
+	}
+
+	buf := make([]byte, 4096)
+
+	n := llama.ChatApplyTemplate(m.template, chat, addAssistant, buf)
+	if int(n) > len(buf) {
+		buf = make([]byte, n)
+		n = llama.ChatApplyTemplate(m.template, chat, addAssistant, buf)
+	}
+
+	if n < 0 {
+		return "", fmt.Errorf("apply template %s failed", m.template)
+	}
+
+	text := string(buf[:n])
+
+	m.dump(text)
+
+	return text, nil
+}
+
+// dump writes the rendered prompt to stderr when prompt dumping is enabled.
+func (m *Model) dump(text string) {
+	if !m.cfg.dumpPrompt {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "---- rendered prompt ----\n%s\n-------------------------\n", text)
+}
+
+// clearMemory drops everything in the model's KV cache.
+func (m *Model) clearMemory() error {
+	mem, err := llama.GetMemory(m.lctx)
+	if err != nil {
+		return fmt.Errorf("get memory: %w", err)
+	}
+
+	if err := llama.MemoryClear(mem, true); err != nil {
+		return fmt.Errorf("clear memory: %w", err)
+	}
+
+	m.cache = nil
+
+	return nil
+}
+
+// normalize applies L2 normalization and converts the vector to the float64
+// form the vector packages work with.
+func normalize(vec []float32) []float64 {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+
+	norm := 1 / math.Sqrt(sum)
+
+	result := make([]float64, len(vec))
+	for i, v := range vec {
+		result[i] = float64(v) * norm
+	}
+
+	return result
+}